@@ -0,0 +1,349 @@
+package main
+
+import (
+	"context"
+	"crowfather/internal/config"
+	"crowfather/internal/database"
+	"crowfather/internal/reconciler"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// reconcileCmd runs one reconciliation in the foreground, or with --dry-run
+// prints the documents it would upload and how they differ from the last run.
+func reconcileCmd(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("reconcile", flag.ContinueOnError)
+	dryRun := fs.Bool("dry-run", false, "render documents without uploading them")
+	asJSON := fs.Bool("json", false, "with --dry-run, print the documents and diffs as JSON")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if cfg.Reconciler == nil {
+		return errors.New("no Sleeper leagues configured (set SLEEPER_LEAGUE_IDS)")
+	}
+
+	repos := openRepositories(ctx, cfg.Database)
+	defer repos.Close()
+	svcs, err := newServices(cfg, repos)
+	if err != nil {
+		return err
+	}
+	rec := svcs.rec
+
+	if *dryRun {
+		result, err := rec.DryRun(ctx, "", "")
+		if err != nil {
+			return err
+		}
+		if *asJSON {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			return enc.Encode(result)
+		}
+		fmt.Println(result.Summary())
+		for _, change := range result.Changes {
+			if change.Status == reconciler.DocChanged {
+				fmt.Printf("\n--- %s\n%s", change.Name, change.Diff)
+			}
+		}
+		return nil
+	}
+
+	done := make(chan string, 1)
+	triggered, reason := rec.TriggerFrom(reconciler.SourceCLI, func(summary string) { done <- summary })
+	if !triggered {
+		return errors.New(reason)
+	}
+
+	select {
+	case summary := <-done:
+		fmt.Println(summary)
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := rec.Shutdown(shutdownCtx); err != nil {
+			return fmt.Errorf("interrupted: %w", err)
+		}
+		return ctx.Err()
+	}
+
+	if last := rec.Status().LastRun; last != nil && last.Status == reconciler.StatusFailed {
+		return errors.New(last.Error)
+	}
+	return nil
+}
+
+// askCmd sends one question to an assistant on a throwaway thread.
+func askCmd(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("ask", flag.ContinueOnError)
+	assistant := fs.String("assistant", "test", "test, meltdown, groupme or an assistant ID")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	question := strings.TrimSpace(strings.Join(fs.Args(), " "))
+	if question == "" {
+		return errors.New(`usage: crowfather ask --assistant NAME "question"`)
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	assistantID, err := resolveAssistant(cfg, *assistant)
+	if err != nil {
+		return err
+	}
+
+	// No thread repository: the question must not land in a chat's thread.
+	svcs, err := newServices(cfg, &repositories{})
+	if err != nil {
+		return err
+	}
+	oai := svcs.oai
+	threadID, err := oai.CreateThread()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := oai.DeleteThread(context.Background(), threadID); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to clean up thread: %v\n", err)
+		}
+	}()
+
+	if err := oai.QueueMessage(threadID, question); err != nil {
+		return err
+	}
+	response, err := oai.RunThread(ctx, threadID, assistantID)
+	if err != nil {
+		return err
+	}
+	fmt.Println(response)
+	return nil
+}
+
+// resolveAssistant maps the configured assistant names to their IDs; anything
+// else is taken to be an assistant ID.
+func resolveAssistant(cfg *config.Config, name string) (string, error) {
+	var id string
+	switch name {
+	case "test":
+		id = cfg.Assistants.TestAssistantID
+	case "meltdown":
+		id = cfg.Assistants.MeltdownAssistantID
+	case "groupme":
+		id = cfg.Assistants.GroupMeAssistantID
+	default:
+		if !strings.HasPrefix(name, "asst_") {
+			return "", fmt.Errorf("unknown assistant %q: use test, meltdown, groupme or an asst_ ID", name)
+		}
+		return name, nil
+	}
+	if id == "" {
+		return "", fmt.Errorf("no %s assistant configured", name)
+	}
+	return id, nil
+}
+
+// threadsCmd lists the active GroupMe conversation threads or shows the
+// thread history of a context. Threads are reset from the chat, where the
+// running server sees it.
+func threadsCmd(ctx context.Context, args []string) error {
+	usage := errors.New("usage: crowfather threads list|history CONTEXT_ID")
+	if len(args) == 0 {
+		return usage
+	}
+	switch args[0] {
+	case "list":
+	case "history":
+		if len(args) != 2 {
+			return usage
+		}
+	default:
+		return usage
+	}
+
+	repos, err := requireRepositories(ctx)
+	if err != nil {
+		return err
+	}
+	defer repos.Close()
+	if err := migrateUp(ctx, repos.db); err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "list":
+		threads, err := repos.threads.ListThreads(ctx)
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "CONTEXT\tTHREAD\tMESSAGES\tSTARTED\tUPDATED")
+		for _, t := range threads {
+			fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\n", t.ContextID, t.ThreadID, t.MessageCount,
+				t.CreatedAt.Format(time.RFC3339), t.UpdatedAt.Format(time.RFC3339))
+		}
+		return w.Flush()
+
+	case "history":
+		threads, err := repos.threads.ListThreadHistory(ctx, args[1])
+		if err != nil {
+			return err
+		}
+		if len(threads) == 0 {
+			fmt.Printf("No threads stored for %s\n", args[1])
+			return nil
+		}
+		for _, t := range threads {
+			status := "active"
+			if !t.RetiredAt.IsZero() {
+				status = fmt.Sprintf("retired %s (%s)", t.RetiredAt.Format(time.RFC3339), t.RetireReason)
+			}
+			fmt.Printf("%s  started %s  %d message(s)  %s\n", t.ThreadID, t.CreatedAt.Format(time.RFC3339), t.MessageCount, status)
+			if t.Summary != "" {
+				fmt.Printf("  %s\n", strings.ReplaceAll(t.Summary, "\n", "\n  "))
+			}
+		}
+		return nil
+	}
+	return nil
+}
+
+// vectorStoresCmd lists the account's vector stores, or deletes the ones the
+// reconciler created but no configured assistant uses.
+func vectorStoresCmd(ctx context.Context, args []string) error {
+	if len(args) == 0 || (args[0] != "list" && args[0] != "prune") {
+		return errors.New("usage: crowfather vector-stores list|prune [--dry-run]")
+	}
+	fs := flag.NewFlagSet("vector-stores "+args[0], flag.ContinueOnError)
+	dryRun := fs.Bool("dry-run", false, "with prune, list the stores that would be deleted")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	repos := openRepositories(ctx, cfg.Database)
+	defer repos.Close()
+	svcs, err := newServices(cfg, repos)
+	if err != nil {
+		return err
+	}
+	oai, rec := svcs.oai, svcs.rec
+
+	if args[0] == "prune" {
+		if rec == nil {
+			return errors.New("no Sleeper leagues configured (set SLEEPER_LEAGUE_IDS)")
+		}
+		pruned, err := rec.PruneVectorStores(ctx, *dryRun)
+		verb := "Deleted"
+		if *dryRun {
+			verb = "Would delete"
+		}
+		for _, vs := range pruned {
+			fmt.Printf("%s %s (%s)\n", verb, vs.ID, vs.Name)
+		}
+		if err == nil && len(pruned) == 0 {
+			fmt.Println("No unused vector stores")
+		}
+		return err
+	}
+
+	stores, err := oai.ListVectorStores(ctx)
+	if err != nil {
+		return err
+	}
+	usedBy := make(map[string]string)
+	if rec != nil {
+		if ids, err := rec.VectorStoreIDs(ctx); err == nil {
+			for assistantID, vsID := range ids {
+				usedBy[vsID] = assistantID
+			}
+		}
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tNAME\tFILES\tCREATED\tASSISTANT")
+	for _, vs := range stores {
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\n", vs.ID, vs.Name, vs.Files, vs.CreatedAt.Format(time.RFC3339), usedBy[vs.ID])
+	}
+	return w.Flush()
+}
+
+// migrateCmd applies pending schema migrations (up, the default), rolls back
+// the most recent ones (down [N], default 1) or lists them (status).
+func migrateCmd(ctx context.Context, args []string) error {
+	action := "up"
+	if len(args) > 0 {
+		action, args = args[0], args[1:]
+	}
+	steps := 1
+	switch {
+	case action == "down" && len(args) == 1:
+		n, err := strconv.Atoi(args[0])
+		if err != nil || n < 1 {
+			return fmt.Errorf("down takes a positive number of migrations, got %q", args[0])
+		}
+		steps = n
+	case (action == "up" || action == "status" || action == "down") && len(args) == 0:
+	default:
+		return errors.New("usage: crowfather migrate [up|down [N]|status]")
+	}
+
+	repos, err := requireRepositories(ctx)
+	if err != nil {
+		return err
+	}
+	defer repos.Close()
+	migrator, err := database.NewMigrator(repos.db.DB())
+	if err != nil {
+		return err
+	}
+
+	switch action {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		for _, m := range applied {
+			fmt.Printf("Applied %04d_%s\n", m.Version, m.Name)
+		}
+		if err == nil && len(applied) == 0 {
+			fmt.Println("Schema is up to date")
+		}
+		return err
+	case "down":
+		reverted, err := migrator.Down(ctx, steps)
+		for _, m := range reverted {
+			fmt.Printf("Rolled back %04d_%s\n", m.Version, m.Name)
+		}
+		return err
+	default:
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED")
+		for _, st := range statuses {
+			applied := "pending"
+			if !st.AppliedAt.IsZero() {
+				applied = st.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Fprintf(w, "%04d\t%s\t%s\n", st.Version, st.Name, applied)
+		}
+		return w.Flush()
+	}
+}
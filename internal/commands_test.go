@@ -0,0 +1,30 @@
+package main
+
+import (
+	"crowfather/internal/config"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveAssistant(t *testing.T) {
+	cfg := &config.Config{Assistants: &config.Assistants{
+		TestAssistantID:     "asst_test",
+		MeltdownAssistantID: "asst_meltdown",
+	}}
+
+	id, err := resolveAssistant(cfg, "test")
+	require.NoError(t, err)
+	assert.Equal(t, "asst_test", id)
+
+	id, err = resolveAssistant(cfg, "asst_custom")
+	require.NoError(t, err)
+	assert.Equal(t, "asst_custom", id)
+
+	_, err = resolveAssistant(cfg, "groupme")
+	assert.ErrorContains(t, err, "no groupme assistant configured")
+
+	_, err = resolveAssistant(cfg, "nope")
+	assert.Error(t, err)
+}
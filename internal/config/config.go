@@ -9,11 +9,20 @@ import (
 )
 
 type GroupMeConfig struct {
-	BotID   string        `json:"bot_id"`
-	Token   string        `json:"token"`
-	Timeout time.Duration `json:"timeout"`
-	Host    string        `json:"host"`
-	Path    string        `json:"path"`
+	BotID   string         `json:"bot_id"` // default bot for groups without a binding
+	Token   string         `json:"token"`
+	Timeout time.Duration  `json:"timeout"`
+	Host    string         `json:"host"`
+	Path    string         `json:"path"`
+	Groups  []GroupBinding `json:"groups"` // GROUPME_GROUPS
+}
+
+// GroupBinding routes one GroupMe group to the bot that posts in it and the
+// assistant that answers it. An empty AssistantID uses GroupMeAssistantID.
+type GroupBinding struct {
+	GroupID     string `json:"group_id"`
+	BotID       string `json:"bot_id"`
+	AssistantID string `json:"assistant_id"`
 }
 
 type Assistants struct {
@@ -34,6 +43,9 @@ type Config struct {
 	Auth       *AuthConfig       `json:"auth"`
 	Assistants *Assistants       `json:"assistants"`
 	Reconciler *ReconcilerConfig `json:"reconciler"` // nil if not configured
+	Jobs       *JobsConfig       `json:"jobs"`
+	Database   *DatabaseConfig   `json:"database"` // nil if not configured
+	Threads    *ThreadsConfig    `json:"threads"`
 }
 
 type AuthConfig struct {
@@ -41,175 +53,455 @@ type AuthConfig struct {
 }
 
 type ReconcilerConfig struct {
-	LeagueIDs         []string      // SLEEPER_LEAGUE_IDS (comma-separated)
-	OnStartup         bool          // RECONCILE_ON_STARTUP (default true)
-	Interval          time.Duration // RECONCILE_INTERVAL_HOURS (default 168h)
-	CooldownMinutes   time.Duration // RECONCILE_COOLDOWN_MINUTES (default 30m)
-	ApprovedUsers     []string      // RECONCILE_APPROVED_USERS (comma-separated GroupMe user_ids)
-	TransactionRounds int           // RECONCILE_TRANSACTION_ROUNDS (default 2)
+	LeagueIDs         []string        `json:"league_ids"`         // SLEEPER_LEAGUE_IDS (comma-separated) plus every bound league
+	Leagues           []LeagueBinding `json:"leagues"`            // SLEEPER_LEAGUES (comma-separated league_id:group_id[:assistant_id])
+	OnStartup         bool            `json:"on_startup"`         // RECONCILE_ON_STARTUP (default true)
+	Interval          time.Duration   `json:"interval"`           // RECONCILE_INTERVAL_HOURS (default 168h)
+	CooldownMinutes   time.Duration   `json:"cooldown"`           // RECONCILE_COOLDOWN_MINUTES (default 30m)
+	ApprovedUsers     []string        `json:"approved_users"`     // RECONCILE_APPROVED_USERS (comma-separated GroupMe user_ids)
+	TransactionRounds int             `json:"transaction_rounds"` // RECONCILE_TRANSACTION_ROUNDS (recent weeks, 0 for the whole season; default 2)
 }
 
-func LoadConfig() (*Config, error) {
-	openAIConfig, err := loadOpenAIConfig()
-
-	if err != nil {
-		return nil, err
-	}
+// LeagueBinding ties a Sleeper league to the GroupMe group that follows it and,
+// optionally, the assistant whose vector store holds its documents. An empty
+// AssistantID uses the group's assistant.
+type LeagueBinding struct {
+	LeagueID    string `json:"league_id"`
+	GroupID     string `json:"group_id"`
+	AssistantID string `json:"assistant_id"`
+}
 
-	groupMeConfig, err := loadGroupMeConfig()
+// ThreadsConfig controls when a group's conversation thread is rotated. A
+// zero limit disables that policy.
+type ThreadsConfig struct {
+	MaxMessages  int           `json:"max_messages"`  // THREAD_MAX_MESSAGES (default 500)
+	MaxAge       time.Duration `json:"max_age"`       // THREAD_MAX_AGE_DAYS (default 30 days)
+	SummaryModel string        `json:"summary_model"` // THREAD_SUMMARY_MODEL, summarizes the old thread (default gpt-4o-mini)
+}
 
-	if err != nil {
-		return nil, err
-	}
+type JobsConfig struct {
+	Workers      int           `json:"workers"`       // JOB_WORKERS (default 4)
+	QueueSize    int           `json:"queue_size"`    // JOB_QUEUE_SIZE (default 100)
+	MaxAttempts  int           `json:"max_attempts"`  // JOB_MAX_ATTEMPTS (default 3)
+	RetryBackoff time.Duration `json:"retry_backoff"` // JOB_RETRY_BACKOFF_SECONDS (default 2s)
+}
 
-	authConfig, err := loadAuthConfig()
+// LoadConfig builds the configuration from defaults, then the optional JSON
+// file named by CONFIG_FILE, then environment variables, which override file
+// values. Every missing or invalid setting is reported in a single
+// *ValidationError rather than stopping at the first one.
+func LoadConfig() (*Config, error) {
+	cfg := defaultConfig()
+	errs := &ValidationError{}
 
-	if err != nil {
-		return nil, err
+	if path := os.Getenv("CONFIG_FILE"); path != "" {
+		loadConfigFile(path, cfg, errs)
+		errs.file = path
 	}
-	assistants, err := loadAssistants()
 
-	if err != nil {
-		return nil, err
+	cfg = &Config{
+		OpenAI:     loadOpenAIConfig(cfg.OpenAI, errs),
+		GroupMe:    loadGroupMeConfig(cfg.GroupMe, errs),
+		Auth:       loadAuthConfig(cfg.Auth, errs),
+		Assistants: loadAssistants(cfg.Assistants, errs),
+		Reconciler: loadReconcilerConfig(cfg.Reconciler, errs),
+		Jobs:       loadJobsConfig(cfg.Jobs, errs),
+		Database:   loadDatabaseConfig(cfg.Database, errs),
+		Threads:    loadThreadsConfig(cfg.Threads, errs),
 	}
 
-	reconcilerConfig := loadReconcilerConfig()
+	if len(errs.Problems) > 0 {
+		return nil, errs
+	}
 
-	return &Config{
-		OpenAI:     openAIConfig,
-		GroupMe:    groupMeConfig,
-		Auth:       authConfig,
-		Assistants: assistants,
-		Reconciler: reconcilerConfig,
-	}, nil
+	return cfg, nil
 }
 
-func loadOpenAIConfig() (*OpenAIConfig, error) {
-	APIKey := os.Getenv("OPENAI_API_KEY")
-
-	if APIKey == "" {
-		return nil, fmt.Errorf("OPENAI_API_KEY environment variable is not set")
+// defaultConfig returns every section populated with its defaults. A config
+// file is decoded on top of it, so omitted fields keep these values.
+func defaultConfig() *Config {
+	return &Config{
+		OpenAI:     defaultOpenAIConfig(),
+		GroupMe:    defaultGroupMeConfig(),
+		Auth:       &AuthConfig{},
+		Assistants: &Assistants{},
+		Reconciler: defaultReconcilerConfig(),
+		Jobs:       defaultJobsConfig(),
+		Database:   defaultDatabaseConfig(),
+		Threads:    defaultThreadsConfig(),
 	}
+}
 
+func defaultOpenAIConfig() *OpenAIConfig {
 	return &OpenAIConfig{
-		APIKey:  APIKey,
 		BaseURL: "https://api.openai.com/v1",
 		Timeout: 60 * time.Second,
-	}, nil
+	}
+}
+
+func defaultGroupMeConfig() *GroupMeConfig {
+	return &GroupMeConfig{
+		Timeout: 20 * time.Second,
+		Host:    "api.groupme.com",
+		Path:    "/v3/bots/post",
+	}
+}
+
+func defaultReconcilerConfig() *ReconcilerConfig {
+	return &ReconcilerConfig{
+		OnStartup:         true,
+		Interval:          168 * time.Hour,
+		CooldownMinutes:   30 * time.Minute,
+		TransactionRounds: 2,
+	}
 }
 
-func loadGroupMeConfig() (*GroupMeConfig, error) {
-	BotID := os.Getenv("GROUPME_BOT_ID")
+func defaultJobsConfig() *JobsConfig {
+	return &JobsConfig{
+		Workers:      4,
+		QueueSize:    100,
+		MaxAttempts:  3,
+		RetryBackoff: 2 * time.Second,
+	}
+}
+
+func defaultThreadsConfig() *ThreadsConfig {
+	return &ThreadsConfig{
+		MaxMessages:  500,
+		MaxAge:       30 * 24 * time.Hour,
+		SummaryModel: "gpt-4o-mini",
+	}
+}
 
-	if BotID == "" {
-		return nil, fmt.Errorf("GROUPME_BOT_ID environment variable is not set")
+func loadOpenAIConfig(base *OpenAIConfig, errs *ValidationError) *OpenAIConfig {
+	cfg := *defaultOpenAIConfig()
+	if base != nil {
+		cfg = *base
 	}
 
-	token := os.Getenv("GROUPME_BOT_TOKEN")
+	envString("OPENAI_API_KEY", &cfg.APIKey)
 
-	if token == "" {
-		return nil, fmt.Errorf("GROUPME_BOT_TOKEN environment variable is not set")
+	if cfg.APIKey == "" {
+		errs.missing("OPENAI_API_KEY", "openai.api_key")
+	}
+	if cfg.Timeout <= 0 {
+		errs.add("openai.timeout must be positive")
 	}
 
-	return &GroupMeConfig{
-		BotID:   BotID,
-		Token:   token,
-		Timeout: 20 * time.Second,
-		Host:    "api.groupme.com",
-		Path:    "/v3/bots/post",
-	}, nil
+	return &cfg
 }
 
-func loadAuthConfig() (*AuthConfig, error) {
-	APIKey := os.Getenv("API_KEY")
+func loadGroupMeConfig(base *GroupMeConfig, errs *ValidationError) *GroupMeConfig {
+	cfg := *defaultGroupMeConfig()
+	if base != nil {
+		cfg = *base
+	}
+
+	envString("GROUPME_BOT_ID", &cfg.BotID)
+	envString("GROUPME_BOT_TOKEN", &cfg.Token)
 
-	if APIKey == "" {
-		return nil, fmt.Errorf("API_KEY environment variable is not set")
+	if v := os.Getenv("GROUPME_GROUPS"); v != "" {
+		groups, err := parseGroupBindings(v)
+		if err != nil {
+			errs.add("%v", err)
+		}
+		cfg.Groups = groups
+	}
+
+	if cfg.BotID == "" && len(cfg.Groups) == 0 {
+		errs.missing("GROUPME_BOT_ID", "groupme.bot_id")
+	}
+	if cfg.Token == "" {
+		errs.missing("GROUPME_BOT_TOKEN", "groupme.token")
 	}
+	if cfg.Timeout <= 0 {
+		errs.add("groupme.timeout must be positive")
+	}
+	for i, g := range cfg.Groups {
+		if g.GroupID == "" || g.BotID == "" {
+			errs.add("groupme.groups[%d] needs both group_id and bot_id", i)
+		}
+	}
+
+	return &cfg
+}
+
+// parseGroupBindings parses GROUPME_GROUPS, a comma-separated list of
+// group_id:bot_id[:assistant_id] entries.
+func parseGroupBindings(raw string) ([]GroupBinding, error) {
+	var groups []GroupBinding
+	for _, entry := range splitTrimmed(raw) {
+		parts := strings.Split(entry, ":")
+		if len(parts) < 2 || len(parts) > 3 {
+			return nil, fmt.Errorf("GROUPME_GROUPS entry %q must be group_id:bot_id[:assistant_id]", entry)
+		}
+
+		binding := GroupBinding{
+			GroupID: strings.TrimSpace(parts[0]),
+			BotID:   strings.TrimSpace(parts[1]),
+		}
+		if len(parts) == 3 {
+			binding.AssistantID = strings.TrimSpace(parts[2])
+		}
 
-	return &AuthConfig{
-		APIKey: APIKey,
-	}, nil
+		if binding.GroupID == "" || binding.BotID == "" {
+			return nil, fmt.Errorf("GROUPME_GROUPS entry %q must be group_id:bot_id[:assistant_id]", entry)
+		}
+		groups = append(groups, binding)
+	}
+	return groups, nil
 }
 
-func loadAssistants() (*Assistants, error) {
-	GroupMeAssistantID := os.Getenv("GROUPME_ASSISTANT_ID")
+func loadAuthConfig(base *AuthConfig, errs *ValidationError) *AuthConfig {
+	cfg := AuthConfig{}
+	if base != nil {
+		cfg = *base
+	}
+
+	envString("API_KEY", &cfg.APIKey)
 
-	if GroupMeAssistantID == "" {
-		return nil, fmt.Errorf("GROUPME_ASSISTANT_ID environment variable is not set")
+	if cfg.APIKey == "" {
+		errs.missing("API_KEY", "auth.api_key")
 	}
 
-	MeltdownAssistantID := os.Getenv("MELTDOWN_ASSISTANT_ID")
+	return &cfg
+}
 
-	if MeltdownAssistantID == "" {
-		return nil, fmt.Errorf("MELTDOWN_ASSISTANT_ID environment variable is not set")
+func loadAssistants(base *Assistants, errs *ValidationError) *Assistants {
+	cfg := Assistants{}
+	if base != nil {
+		cfg = *base
 	}
 
-	TestAssistantID := os.Getenv("TEST_ASSISTANT_ID")
+	envString("GROUPME_ASSISTANT_ID", &cfg.GroupMeAssistantID)
+	envString("MELTDOWN_ASSISTANT_ID", &cfg.MeltdownAssistantID)
+	envString("TEST_ASSISTANT_ID", &cfg.TestAssistantID)
 
-	if TestAssistantID == "" {
-		return nil, fmt.Errorf("TEST_ASSISTANT_ID environment variable is not set")
+	if cfg.GroupMeAssistantID == "" {
+		errs.missing("GROUPME_ASSISTANT_ID", "assistants.groupme_assistant_id")
+	}
+	if cfg.MeltdownAssistantID == "" {
+		errs.missing("MELTDOWN_ASSISTANT_ID", "assistants.meltdown_assistant_id")
+	}
+	if cfg.TestAssistantID == "" {
+		errs.missing("TEST_ASSISTANT_ID", "assistants.test_assistant_id")
 	}
 
-	return &Assistants{
-		GroupMeAssistantID:  GroupMeAssistantID,
-		MeltdownAssistantID: MeltdownAssistantID,
-		TestAssistantID:     TestAssistantID,
-	}, nil
+	return &cfg
 }
 
 // loadReconcilerConfig loads optional reconciler settings. Returns nil if no
 // league IDs are configured, which disables the reconciler entirely.
-func loadReconcilerConfig() *ReconcilerConfig {
-	leagueIDsRaw := os.Getenv("SLEEPER_LEAGUE_IDS")
-	if leagueIDsRaw == "" {
-		return nil
+func loadReconcilerConfig(base *ReconcilerConfig, errs *ValidationError) *ReconcilerConfig {
+	cfg := *defaultReconcilerConfig()
+	if base != nil {
+		cfg = *base
 	}
 
-	leagueIDs := splitTrimmed(leagueIDsRaw)
-	if len(leagueIDs) == 0 {
-		return nil
+	if v := os.Getenv("SLEEPER_LEAGUE_IDS"); v != "" {
+		cfg.LeagueIDs = splitTrimmed(v)
 	}
 
-	onStartup := true
-	if v := os.Getenv("RECONCILE_ON_STARTUP"); v == "false" {
-		onStartup = false
+	if v := os.Getenv("SLEEPER_LEAGUES"); v != "" {
+		leagues, err := parseLeagueBindings(v)
+		if err != nil {
+			errs.add("%v", err)
+		}
+		cfg.Leagues = leagues
 	}
 
-	intervalHours := 168
-	if v := os.Getenv("RECONCILE_INTERVAL_HOURS"); v != "" {
-		if n, err := strconv.Atoi(v); err == nil && n > 0 {
-			intervalHours = n
+	leagueIDs := append([]string(nil), cfg.LeagueIDs...)
+	for i, l := range cfg.Leagues {
+		if l.LeagueID == "" || l.GroupID == "" {
+			errs.add("reconciler.leagues[%d] needs both league_id and group_id", i)
+			continue
+		}
+		if !contains(leagueIDs, l.LeagueID) {
+			leagueIDs = append(leagueIDs, l.LeagueID)
 		}
 	}
+	cfg.LeagueIDs = leagueIDs
 
-	cooldownMinutes := 30
-	if v := os.Getenv("RECONCILE_COOLDOWN_MINUTES"); v != "" {
-		if n, err := strconv.Atoi(v); err == nil && n > 0 {
-			cooldownMinutes = n
-		}
+	if len(cfg.LeagueIDs) == 0 {
+		return nil
+	}
+
+	envBool(errs, "RECONCILE_ON_STARTUP", &cfg.OnStartup)
+	envDuration(errs, "RECONCILE_INTERVAL_HOURS", time.Hour, &cfg.Interval)
+	envNonNegativeDuration(errs, "RECONCILE_COOLDOWN_MINUTES", time.Minute, &cfg.CooldownMinutes)
+	envNonNegativeInt(errs, "RECONCILE_TRANSACTION_ROUNDS", &cfg.TransactionRounds)
+
+	if v := os.Getenv("RECONCILE_APPROVED_USERS"); v != "" {
+		cfg.ApprovedUsers = splitTrimmed(v)
+	}
+
+	if cfg.Interval <= 0 {
+		errs.add("reconciler.interval must be positive")
+	}
+	if cfg.CooldownMinutes < 0 {
+		errs.add("reconciler.cooldown must not be negative")
 	}
+	if cfg.TransactionRounds < 0 {
+		errs.add("reconciler.transaction_rounds must not be negative")
+	}
+
+	return &cfg
+}
+
+// parseLeagueBindings parses SLEEPER_LEAGUES, a comma-separated list of
+// league_id:group_id[:assistant_id] entries.
+func parseLeagueBindings(raw string) ([]LeagueBinding, error) {
+	var leagues []LeagueBinding
+	for _, entry := range splitTrimmed(raw) {
+		parts := strings.Split(entry, ":")
+		if len(parts) < 2 || len(parts) > 3 {
+			return nil, fmt.Errorf("SLEEPER_LEAGUES entry %q must be league_id:group_id[:assistant_id]", entry)
+		}
+
+		binding := LeagueBinding{
+			LeagueID: strings.TrimSpace(parts[0]),
+			GroupID:  strings.TrimSpace(parts[1]),
+		}
+		if len(parts) == 3 {
+			binding.AssistantID = strings.TrimSpace(parts[2])
+		}
 
-	transactionRounds := 2
-	if v := os.Getenv("RECONCILE_TRANSACTION_ROUNDS"); v != "" {
-		if n, err := strconv.Atoi(v); err == nil && n > 0 {
-			transactionRounds = n
+		if binding.LeagueID == "" || binding.GroupID == "" {
+			return nil, fmt.Errorf("SLEEPER_LEAGUES entry %q must be league_id:group_id[:assistant_id]", entry)
 		}
+		leagues = append(leagues, binding)
 	}
+	return leagues, nil
+}
 
-	var approvedUsers []string
-	if v := os.Getenv("RECONCILE_APPROVED_USERS"); v != "" {
-		approvedUsers = splitTrimmed(v)
+// loadJobsConfig loads the background job queue settings used for GroupMe
+// webhook processing.
+func loadJobsConfig(base *JobsConfig, errs *ValidationError) *JobsConfig {
+	cfg := *defaultJobsConfig()
+	if base != nil {
+		cfg = *base
 	}
 
-	return &ReconcilerConfig{
-		LeagueIDs:         leagueIDs,
-		OnStartup:         onStartup,
-		Interval:          time.Duration(intervalHours) * time.Hour,
-		CooldownMinutes:   time.Duration(cooldownMinutes) * time.Minute,
-		ApprovedUsers:     approvedUsers,
-		TransactionRounds: transactionRounds,
+	envInt(errs, "JOB_WORKERS", &cfg.Workers)
+	envInt(errs, "JOB_QUEUE_SIZE", &cfg.QueueSize)
+	envInt(errs, "JOB_MAX_ATTEMPTS", &cfg.MaxAttempts)
+	envNonNegativeDuration(errs, "JOB_RETRY_BACKOFF_SECONDS", time.Second, &cfg.RetryBackoff)
+
+	if cfg.Workers <= 0 {
+		errs.add("jobs.workers must be positive")
+	}
+	if cfg.QueueSize <= 0 {
+		errs.add("jobs.queue_size must be positive")
+	}
+	if cfg.MaxAttempts <= 0 {
+		errs.add("jobs.max_attempts must be positive")
+	}
+	if cfg.RetryBackoff < 0 {
+		errs.add("jobs.retry_backoff must not be negative")
+	}
+
+	return &cfg
+}
+
+// loadThreadsConfig loads the thread rotation policy.
+func loadThreadsConfig(base *ThreadsConfig, errs *ValidationError) *ThreadsConfig {
+	cfg := *defaultThreadsConfig()
+	if base != nil {
+		cfg = *base
+	}
+
+	envNonNegativeInt(errs, "THREAD_MAX_MESSAGES", &cfg.MaxMessages)
+	days := int(cfg.MaxAge / (24 * time.Hour))
+	envNonNegativeInt(errs, "THREAD_MAX_AGE_DAYS", &days)
+	if os.Getenv("THREAD_MAX_AGE_DAYS") != "" {
+		cfg.MaxAge = time.Duration(days) * 24 * time.Hour
+	}
+	envString("THREAD_SUMMARY_MODEL", &cfg.SummaryModel)
+
+	if cfg.MaxMessages < 0 {
+		errs.add("threads.max_messages must not be negative")
+	}
+	if cfg.MaxAge < 0 {
+		errs.add("threads.max_age must not be negative")
+	}
+	if cfg.SummaryModel == "" {
+		errs.add("threads.summary_model must not be empty")
+	}
+
+	return &cfg
+}
+
+// envString overrides dst with the variable's value when it is set.
+func envString(key string, dst *string) {
+	if v := os.Getenv(key); v != "" {
+		*dst = v
+	}
+}
+
+// envInt overrides dst with the variable's value when it is set, recording a
+// problem if it is not a positive integer.
+func envInt(errs *ValidationError, key string, dst *int) {
+	v := os.Getenv(key)
+	if v == "" {
+		return
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n <= 0 {
+		errs.add("%s must be a positive integer, got %q", key, v)
+		return
+	}
+	*dst = n
+}
+
+// envDuration overrides dst with the variable's value counted in unit.
+func envDuration(errs *ValidationError, key string, unit time.Duration, dst *time.Duration) {
+	v := os.Getenv(key)
+	if v == "" {
+		return
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n <= 0 {
+		errs.add("%s must be a positive integer, got %q", key, v)
+		return
+	}
+	*dst = time.Duration(n) * unit
+}
+
+// envNonNegativeDuration is envDuration for settings where zero turns the
+// delay off.
+func envNonNegativeDuration(errs *ValidationError, key string, unit time.Duration, dst *time.Duration) {
+	v := os.Getenv(key)
+	if v == "" {
+		return
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 {
+		errs.add("%s must be a non-negative integer, got %q", key, v)
+		return
+	}
+	*dst = time.Duration(n) * unit
+}
+
+func envBool(errs *ValidationError, key string, dst *bool) {
+	v := os.Getenv(key)
+	if v == "" {
+		return
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		errs.add("%s must be true or false, got %q", key, v)
+		return
+	}
+	*dst = b
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
 	}
+	return false
 }
 
 func splitTrimmed(s string) []string {
@@ -1,6 +1,9 @@
 package config
 
-import "testing"
+import (
+	"testing"
+	"time"
+)
 
 func TestLoadConfigSuccess(t *testing.T) {
 	t.Setenv("OPENAI_API_KEY", "openai")
@@ -29,14 +32,16 @@ func TestLoadConfigSuccess(t *testing.T) {
 
 func TestLoadOpenAIConfigMissing(t *testing.T) {
 	t.Setenv("OPENAI_API_KEY", "")
-	if _, err := loadOpenAIConfig(); err == nil {
+	errs := &ValidationError{}
+	loadOpenAIConfig(nil, errs)
+	if len(errs.Problems) == 0 {
 		t.Fatalf("expected error when OPENAI_API_KEY missing")
 	}
 }
 
 func TestLoadReconcilerConfig_NoLeagueIDs(t *testing.T) {
 	t.Setenv("SLEEPER_LEAGUE_IDS", "")
-	if cfg := loadReconcilerConfig(); cfg != nil {
+	if cfg := loadReconcilerConfig(nil, &ValidationError{}); cfg != nil {
 		t.Fatalf("expected nil config when SLEEPER_LEAGUE_IDS is empty, got %+v", cfg)
 	}
 }
@@ -49,7 +54,7 @@ func TestLoadReconcilerConfig_WithLeagueIDs(t *testing.T) {
 	t.Setenv("RECONCILE_TRANSACTION_ROUNDS", "3")
 	t.Setenv("RECONCILE_APPROVED_USERS", "user1,user2")
 
-	cfg := loadReconcilerConfig()
+	cfg := loadReconcilerConfig(nil, &ValidationError{})
 	if cfg == nil {
 		t.Fatal("expected non-nil config")
 	}
@@ -81,7 +86,7 @@ func TestLoadReconcilerConfig_Defaults(t *testing.T) {
 	t.Setenv("RECONCILE_TRANSACTION_ROUNDS", "")
 	t.Setenv("RECONCILE_APPROVED_USERS", "")
 
-	cfg := loadReconcilerConfig()
+	cfg := loadReconcilerConfig(nil, &ValidationError{})
 	if cfg == nil {
 		t.Fatal("expected non-nil config")
 	}
@@ -102,6 +107,27 @@ func TestLoadReconcilerConfig_Defaults(t *testing.T) {
 	}
 }
 
+func TestLoadReconcilerConfig_ZeroCooldown(t *testing.T) {
+	t.Setenv("SLEEPER_LEAGUE_IDS", "league1")
+	t.Setenv("RECONCILE_COOLDOWN_MINUTES", "0")
+
+	errs := &ValidationError{}
+	cfg := loadReconcilerConfig(nil, errs)
+	if len(errs.Problems) != 0 {
+		t.Fatalf("expected no problems, got %v", errs.Problems)
+	}
+	if cfg.CooldownMinutes != 0 {
+		t.Errorf("expected cooldown to be off, got %v", cfg.CooldownMinutes)
+	}
+
+	t.Setenv("RECONCILE_COOLDOWN_MINUTES", "-5")
+	errs = &ValidationError{}
+	loadReconcilerConfig(nil, errs)
+	if len(errs.Problems) != 1 {
+		t.Errorf("expected a negative cooldown to be rejected, got %v", errs.Problems)
+	}
+}
+
 func TestSplitTrimmed(t *testing.T) {
 	cases := []struct {
 		input    string
@@ -125,3 +151,141 @@ func TestSplitTrimmed(t *testing.T) {
 		}
 	}
 }
+
+func TestLoadJobsConfig_Defaults(t *testing.T) {
+	t.Setenv("JOB_WORKERS", "")
+	t.Setenv("JOB_QUEUE_SIZE", "")
+	t.Setenv("JOB_MAX_ATTEMPTS", "")
+	t.Setenv("JOB_RETRY_BACKOFF_SECONDS", "")
+
+	errs := &ValidationError{}
+	cfg := loadJobsConfig(nil, errs)
+	if len(errs.Problems) != 0 {
+		t.Fatalf("unexpected problems: %v", errs.Problems)
+	}
+	if cfg.Workers != 4 || cfg.QueueSize != 100 || cfg.MaxAttempts != 3 {
+		t.Errorf("unexpected defaults: %+v", cfg)
+	}
+	if cfg.RetryBackoff.Seconds() != 2 {
+		t.Errorf("expected 2s backoff, got %v", cfg.RetryBackoff)
+	}
+}
+
+func TestLoadJobsConfig_Overrides(t *testing.T) {
+	t.Setenv("JOB_WORKERS", "8")
+	t.Setenv("JOB_QUEUE_SIZE", "50")
+	t.Setenv("JOB_MAX_ATTEMPTS", "5")
+	t.Setenv("JOB_RETRY_BACKOFF_SECONDS", "1")
+
+	cfg := loadJobsConfig(nil, &ValidationError{})
+	if cfg.Workers != 8 || cfg.QueueSize != 50 || cfg.MaxAttempts != 5 || cfg.RetryBackoff.Seconds() != 1 {
+		t.Errorf("unexpected config: %+v", cfg)
+	}
+}
+
+func TestParseGroupBindings(t *testing.T) {
+	groups, err := parseGroupBindings("g1:bot1:asst1, g2:bot2")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(groups) != 2 {
+		t.Fatalf("expected 2 groups, got %v", groups)
+	}
+	if groups[0] != (GroupBinding{GroupID: "g1", BotID: "bot1", AssistantID: "asst1"}) {
+		t.Errorf("unexpected first group: %+v", groups[0])
+	}
+	if groups[1] != (GroupBinding{GroupID: "g2", BotID: "bot2"}) {
+		t.Errorf("unexpected second group: %+v", groups[1])
+	}
+
+	for _, bad := range []string{"g1", "g1:", ":bot", "a:b:c:d"} {
+		if _, err := parseGroupBindings(bad); err == nil {
+			t.Errorf("expected error for %q", bad)
+		}
+	}
+}
+
+func TestLoadGroupMeConfig_GroupsWithoutDefaultBot(t *testing.T) {
+	t.Setenv("GROUPME_BOT_ID", "")
+	t.Setenv("GROUPME_BOT_TOKEN", "token")
+	t.Setenv("GROUPME_GROUPS", "g1:bot1")
+
+	errs := &ValidationError{}
+	cfg := loadGroupMeConfig(nil, errs)
+	if len(errs.Problems) != 0 {
+		t.Fatalf("expected no error, got %v", errs.Problems)
+	}
+	if len(cfg.Groups) != 1 || cfg.Groups[0].BotID != "bot1" {
+		t.Errorf("unexpected groups: %+v", cfg.Groups)
+	}
+}
+
+func TestLoadReconcilerConfig_LeagueBindings(t *testing.T) {
+	t.Setenv("SLEEPER_LEAGUE_IDS", "league1")
+	t.Setenv("SLEEPER_LEAGUES", "league1:g1, league2:g2:asst2")
+
+	cfg := loadReconcilerConfig(nil, &ValidationError{})
+	if cfg == nil {
+		t.Fatal("expected non-nil config")
+	}
+	if len(cfg.LeagueIDs) != 2 || cfg.LeagueIDs[0] != "league1" || cfg.LeagueIDs[1] != "league2" {
+		t.Errorf("expected bound leagues merged into LeagueIDs, got %v", cfg.LeagueIDs)
+	}
+	if len(cfg.Leagues) != 2 {
+		t.Fatalf("expected 2 league bindings, got %+v", cfg.Leagues)
+	}
+	if cfg.Leagues[1] != (LeagueBinding{LeagueID: "league2", GroupID: "g2", AssistantID: "asst2"}) {
+		t.Errorf("unexpected binding: %+v", cfg.Leagues[1])
+	}
+}
+
+func TestLoadReconcilerConfig_OnlyLeagueBindings(t *testing.T) {
+	t.Setenv("SLEEPER_LEAGUE_IDS", "")
+	t.Setenv("SLEEPER_LEAGUES", "league9:g9")
+
+	cfg := loadReconcilerConfig(nil, &ValidationError{})
+	if cfg == nil || len(cfg.LeagueIDs) != 1 || cfg.LeagueIDs[0] != "league9" {
+		t.Fatalf("expected league9 to enable the reconciler, got %+v", cfg)
+	}
+}
+
+func TestLoadThreadsConfig_Defaults(t *testing.T) {
+	clearEnv(t)
+
+	errs := &ValidationError{}
+	cfg := loadThreadsConfig(nil, errs)
+	if len(errs.Problems) != 0 {
+		t.Fatalf("unexpected problems: %v", errs.Problems)
+	}
+	if cfg.MaxMessages != 500 || cfg.MaxAge != 30*24*time.Hour || cfg.SummaryModel != "gpt-4o-mini" {
+		t.Errorf("unexpected defaults: %+v", cfg)
+	}
+}
+
+func TestLoadThreadsConfig_Overrides(t *testing.T) {
+	clearEnv(t)
+	t.Setenv("THREAD_MAX_MESSAGES", "0")
+	t.Setenv("THREAD_MAX_AGE_DAYS", "7")
+	t.Setenv("THREAD_SUMMARY_MODEL", "gpt-4o")
+
+	errs := &ValidationError{}
+	cfg := loadThreadsConfig(nil, errs)
+	if len(errs.Problems) != 0 {
+		t.Fatalf("unexpected problems: %v", errs.Problems)
+	}
+	if cfg.MaxMessages != 0 || cfg.MaxAge != 7*24*time.Hour || cfg.SummaryModel != "gpt-4o" {
+		t.Errorf("unexpected config: %+v", cfg)
+	}
+}
+
+func TestLoadThreadsConfig_Invalid(t *testing.T) {
+	clearEnv(t)
+	t.Setenv("THREAD_MAX_MESSAGES", "-1")
+	t.Setenv("THREAD_MAX_AGE_DAYS", "soon")
+
+	errs := &ValidationError{}
+	loadThreadsConfig(nil, errs)
+	if len(errs.Problems) != 2 {
+		t.Errorf("expected 2 problems, got %v", errs.Problems)
+	}
+}
@@ -0,0 +1,152 @@
+package config
+
+import (
+	"os"
+	"strconv"
+	"time"
+)
+
+// DatabaseConfig holds the Postgres connection settings. Either URL or the
+// individual fields describe the server; when URL is set, non-empty SSL
+// fields are added to it.
+type DatabaseConfig struct {
+	URL             string        `json:"url"`                // DATABASE_URL, a postgres:// URL or key=value DSN
+	Host            string        `json:"host"`               // DB_HOST (HOST is still read)
+	Port            int           `json:"port"`               // DB_PORT (default 5432)
+	User            string        `json:"user"`               // DB_USER
+	Password        string        `json:"password"`           // DB_PASS
+	Name            string        `json:"name"`               // DB_NAME
+	SSLMode         string        `json:"sslmode"`            // DB_SSLMODE: disable (default), require, verify-ca or verify-full
+	SSLRootCert     string        `json:"sslrootcert"`        // DB_SSLROOTCERT, CA bundle for verify-ca/verify-full
+	SSLCert         string        `json:"sslcert"`            // DB_SSLCERT, client certificate
+	SSLKey          string        `json:"sslkey"`             // DB_SSLKEY, client key
+	MaxOpenConns    int           `json:"max_open_conns"`     // DB_MAX_OPEN_CONNS (default 10)
+	MaxIdleConns    int           `json:"max_idle_conns"`     // DB_MAX_IDLE_CONNS (default 5)
+	ConnMaxLifetime time.Duration `json:"conn_max_lifetime"`  // DB_CONN_MAX_LIFETIME_MINUTES (default 30m)
+	ConnMaxIdleTime time.Duration `json:"conn_max_idle_time"` // DB_CONN_MAX_IDLE_TIME_MINUTES (default 5m)
+	ConnectTimeout  time.Duration `json:"connect_timeout"`    // DB_CONNECT_TIMEOUT_SECONDS (default 10s)
+	ConnectAttempts int           `json:"connect_attempts"`   // DB_CONNECT_ATTEMPTS (default 6)
+	RetryBackoff    time.Duration `json:"retry_backoff"`      // DB_RETRY_BACKOFF_SECONDS, first retry delay (default 1s)
+	MaxRetryBackoff time.Duration `json:"max_retry_backoff"`  // DB_MAX_RETRY_BACKOFF_SECONDS (default 30s)
+}
+
+// sslModes are the sslmode values the Postgres driver supports.
+var sslModes = map[string]bool{"disable": true, "require": true, "verify-ca": true, "verify-full": true}
+
+func defaultDatabaseConfig() *DatabaseConfig {
+	return &DatabaseConfig{
+		Port:            5432,
+		MaxOpenConns:    10,
+		MaxIdleConns:    5,
+		ConnMaxLifetime: 30 * time.Minute,
+		ConnMaxIdleTime: 5 * time.Minute,
+		ConnectTimeout:  10 * time.Second,
+		ConnectAttempts: 6,
+		RetryBackoff:    time.Second,
+		MaxRetryBackoff: 30 * time.Second,
+	}
+}
+
+// LoadDatabaseConfig loads only the database settings, from CONFIG_FILE and
+// the environment, for commands that need nothing else. It returns nil if no
+// database is configured.
+func LoadDatabaseConfig() (*DatabaseConfig, error) {
+	cfg := defaultConfig()
+	errs := &ValidationError{}
+	if path := os.Getenv("CONFIG_FILE"); path != "" {
+		loadConfigFile(path, cfg, errs)
+	}
+
+	db := loadDatabaseConfig(cfg.Database, errs)
+	if len(errs.Problems) > 0 {
+		return nil, errs
+	}
+	return db, nil
+}
+
+// loadDatabaseConfig loads optional database settings. Returns nil if neither
+// a URL nor a host or database name is configured, which runs the service in
+// memory-only mode.
+func loadDatabaseConfig(base *DatabaseConfig, errs *ValidationError) *DatabaseConfig {
+	cfg := *defaultDatabaseConfig()
+	if base != nil {
+		cfg = *base
+	}
+
+	envString("DATABASE_URL", &cfg.URL)
+	envString("HOST", &cfg.Host)
+	envString("DB_HOST", &cfg.Host)
+	envInt(errs, "DB_PORT", &cfg.Port)
+	envString("DB_USER", &cfg.User)
+	envString("DB_PASS", &cfg.Password)
+	envString("DB_NAME", &cfg.Name)
+
+	if cfg.URL == "" && cfg.Host == "" && cfg.Name == "" {
+		return nil
+	}
+
+	envString("DB_SSLMODE", &cfg.SSLMode)
+	envString("DB_SSLROOTCERT", &cfg.SSLRootCert)
+	envString("DB_SSLCERT", &cfg.SSLCert)
+	envString("DB_SSLKEY", &cfg.SSLKey)
+	envInt(errs, "DB_MAX_OPEN_CONNS", &cfg.MaxOpenConns)
+	envNonNegativeInt(errs, "DB_MAX_IDLE_CONNS", &cfg.MaxIdleConns)
+	envDuration(errs, "DB_CONN_MAX_LIFETIME_MINUTES", time.Minute, &cfg.ConnMaxLifetime)
+	envDuration(errs, "DB_CONN_MAX_IDLE_TIME_MINUTES", time.Minute, &cfg.ConnMaxIdleTime)
+	envDuration(errs, "DB_CONNECT_TIMEOUT_SECONDS", time.Second, &cfg.ConnectTimeout)
+	envInt(errs, "DB_CONNECT_ATTEMPTS", &cfg.ConnectAttempts)
+	envDuration(errs, "DB_RETRY_BACKOFF_SECONDS", time.Second, &cfg.RetryBackoff)
+	envDuration(errs, "DB_MAX_RETRY_BACKOFF_SECONDS", time.Second, &cfg.MaxRetryBackoff)
+
+	// Without a URL the old behaviour of an unencrypted connection is kept
+	// unless a mode is chosen; a URL keeps its own sslmode or the driver's.
+	if cfg.SSLMode == "" && cfg.URL == "" {
+		cfg.SSLMode = "disable"
+	}
+	if cfg.SSLMode != "" && !sslModes[cfg.SSLMode] {
+		errs.add("database.sslmode must be disable, require, verify-ca or verify-full, got %q", cfg.SSLMode)
+	}
+	if cfg.SSLMode == "disable" && (cfg.SSLRootCert != "" || cfg.SSLCert != "" || cfg.SSLKey != "") {
+		errs.add("database SSL certificates are set but database.sslmode is disable")
+	}
+	if (cfg.SSLCert == "") != (cfg.SSLKey == "") {
+		errs.add("database.sslcert and database.sslkey must be set together")
+	}
+	if cfg.Port <= 0 || cfg.Port > 65535 {
+		errs.add("database.port must be between 1 and 65535")
+	}
+	if cfg.MaxOpenConns <= 0 {
+		errs.add("database.max_open_conns must be positive")
+	}
+	if cfg.MaxIdleConns < 0 || cfg.MaxIdleConns > cfg.MaxOpenConns {
+		errs.add("database.max_idle_conns must be between 0 and max_open_conns")
+	}
+	if cfg.ConnMaxLifetime < 0 || cfg.ConnMaxIdleTime < 0 {
+		errs.add("database connection lifetimes must not be negative")
+	}
+	if cfg.ConnectTimeout <= 0 {
+		errs.add("database.connect_timeout must be positive")
+	}
+	if cfg.ConnectAttempts <= 0 {
+		errs.add("database.connect_attempts must be positive")
+	}
+	if cfg.RetryBackoff <= 0 || cfg.MaxRetryBackoff < cfg.RetryBackoff {
+		errs.add("database.retry_backoff must be positive and no more than max_retry_backoff")
+	}
+
+	return &cfg
+}
+
+// envNonNegativeInt is envInt for settings where zero is meaningful.
+func envNonNegativeInt(errs *ValidationError, key string, dst *int) {
+	v := os.Getenv(key)
+	if v == "" {
+		return
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 {
+		errs.add("%s must be a non-negative integer, got %q", key, v)
+		return
+	}
+	*dst = n
+}
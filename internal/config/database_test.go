@@ -0,0 +1,107 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadDatabaseConfig_NotConfigured(t *testing.T) {
+	clearEnv(t)
+	cfg, err := LoadDatabaseConfig()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg != nil {
+		t.Fatalf("expected nil config without a database, got %+v", cfg)
+	}
+}
+
+func TestLoadDatabaseConfig_LegacyEnv(t *testing.T) {
+	clearEnv(t)
+	t.Setenv("HOST", "db.internal")
+	t.Setenv("DB_USER", "crow")
+	t.Setenv("DB_PASS", "secret")
+	t.Setenv("DB_NAME", "crowfather")
+
+	cfg, err := LoadDatabaseConfig()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Host != "db.internal" || cfg.Port != 5432 || cfg.SSLMode != "disable" {
+		t.Errorf("unexpected config: %+v", cfg)
+	}
+	if cfg.ConnectAttempts != 6 || cfg.RetryBackoff != time.Second || cfg.MaxOpenConns != 10 {
+		t.Errorf("expected defaults, got %+v", cfg)
+	}
+}
+
+func TestLoadDatabaseConfig_Overrides(t *testing.T) {
+	clearEnv(t)
+	t.Setenv("DATABASE_URL", "postgres://crow@db/crowfather")
+	t.Setenv("DB_SSLMODE", "verify-full")
+	t.Setenv("DB_SSLROOTCERT", "/etc/ssl/rds.pem")
+	t.Setenv("DB_MAX_OPEN_CONNS", "20")
+	t.Setenv("DB_MAX_IDLE_CONNS", "0")
+	t.Setenv("DB_CONN_MAX_LIFETIME_MINUTES", "15")
+	t.Setenv("DB_CONNECT_ATTEMPTS", "3")
+
+	cfg, err := LoadDatabaseConfig()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.SSLMode != "verify-full" || cfg.SSLRootCert != "/etc/ssl/rds.pem" {
+		t.Errorf("unexpected SSL settings: %+v", cfg)
+	}
+	if cfg.MaxOpenConns != 20 || cfg.MaxIdleConns != 0 || cfg.ConnMaxLifetime != 15*time.Minute || cfg.ConnectAttempts != 3 {
+		t.Errorf("unexpected pool settings: %+v", cfg)
+	}
+}
+
+func TestLoadDatabaseConfig_URLKeepsItsSSLMode(t *testing.T) {
+	clearEnv(t)
+	t.Setenv("DATABASE_URL", "postgres://crow@db/crowfather?sslmode=require")
+	cfg, err := LoadDatabaseConfig()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.SSLMode != "" {
+		t.Errorf("expected no sslmode override, got %q", cfg.SSLMode)
+	}
+}
+
+func TestLoadDatabaseConfig_Invalid(t *testing.T) {
+	clearEnv(t)
+	t.Setenv("DB_HOST", "db")
+	t.Setenv("DB_SSLMODE", "prefer-ish")
+	t.Setenv("DB_SSLCERT", "/client.pem")
+	t.Setenv("DB_MAX_IDLE_CONNS", "50")
+
+	_, err := LoadDatabaseConfig()
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	for _, want := range []string{"sslmode", "sslkey", "max_idle_conns"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected error to mention %s, got:\n%v", want, err)
+		}
+	}
+}
+
+func TestLoadDatabaseConfig_File(t *testing.T) {
+	clearEnv(t)
+	t.Setenv("CONFIG_FILE", writeConfigFile(t, `{
+		"database": {"host": "file-db", "name": "crowfather", "sslmode": "require", "retry_backoff": "2s", "connect_timeout": "5s"}
+	}`))
+
+	cfg, err := LoadDatabaseConfig()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Host != "file-db" || cfg.SSLMode != "require" || cfg.RetryBackoff != 2*time.Second || cfg.ConnectTimeout != 5*time.Second {
+		t.Errorf("unexpected config: %+v", cfg)
+	}
+	if cfg.MaxRetryBackoff != 30*time.Second {
+		t.Errorf("expected omitted fields to keep defaults, got %+v", cfg)
+	}
+}
@@ -0,0 +1,170 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// ValidationError lists every missing or invalid setting found while loading
+// the configuration, so all of them can be fixed in one pass.
+type ValidationError struct {
+	Problems []string
+
+	file string // CONFIG_FILE, if settings were read from one
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("invalid configuration:\n  - %s", strings.Join(e.Problems, "\n  - "))
+}
+
+func (e *ValidationError) add(format string, args ...any) {
+	e.Problems = append(e.Problems, fmt.Sprintf(format, args...))
+}
+
+// missing records a required setting that is unset, naming the config file
+// field alongside the environment variable when a file was loaded.
+func (e *ValidationError) missing(key, field string) {
+	if e.file == "" {
+		e.add("%s environment variable is not set", key)
+		return
+	}
+	e.add("neither the %s environment variable nor %s in %s is set", key, field, e.file)
+}
+
+// loadConfigFile decodes the JSON file at path on top of cfg. Fields missing
+// from the file keep their current values; unknown fields are rejected so a
+// typo doesn't silently fall back to a default. A file that can't be read or
+// parsed is added to errs and ignored, leaving cfg at its defaults, so the
+// remaining settings are still checked.
+func loadConfigFile(path string, cfg *Config, errs *ValidationError) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		errs.add("failed to read config file %s: %v", path, err)
+		return
+	}
+	if err := decodeStrict(data, cfg); err != nil {
+		errs.add("failed to parse config file %s: %v", path, err)
+		*cfg = *defaultConfig()
+	}
+}
+
+func decodeStrict(data []byte, v any) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	return dec.Decode(v)
+}
+
+// duration decodes a Go duration string such as "90s" or "168h".
+type duration time.Duration
+
+func (d *duration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return fmt.Errorf("duration must be a string like \"30s\", got %s", b)
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = duration(v)
+	return nil
+}
+
+func (c *OpenAIConfig) UnmarshalJSON(b []byte) error {
+	type alias OpenAIConfig
+	aux := struct {
+		*alias
+		Timeout duration `json:"timeout"`
+	}{alias: (*alias)(c), Timeout: duration(c.Timeout)}
+	if err := decodeStrict(b, &aux); err != nil {
+		return err
+	}
+	c.Timeout = time.Duration(aux.Timeout)
+	return nil
+}
+
+func (c *GroupMeConfig) UnmarshalJSON(b []byte) error {
+	type alias GroupMeConfig
+	aux := struct {
+		*alias
+		Timeout duration `json:"timeout"`
+	}{alias: (*alias)(c), Timeout: duration(c.Timeout)}
+	if err := decodeStrict(b, &aux); err != nil {
+		return err
+	}
+	c.Timeout = time.Duration(aux.Timeout)
+	return nil
+}
+
+func (c *ReconcilerConfig) UnmarshalJSON(b []byte) error {
+	type alias ReconcilerConfig
+	aux := struct {
+		*alias
+		Interval        duration `json:"interval"`
+		CooldownMinutes duration `json:"cooldown"`
+	}{alias: (*alias)(c), Interval: duration(c.Interval), CooldownMinutes: duration(c.CooldownMinutes)}
+	if err := decodeStrict(b, &aux); err != nil {
+		return err
+	}
+	c.Interval = time.Duration(aux.Interval)
+	c.CooldownMinutes = time.Duration(aux.CooldownMinutes)
+	return nil
+}
+
+func (c *JobsConfig) UnmarshalJSON(b []byte) error {
+	type alias JobsConfig
+	aux := struct {
+		*alias
+		RetryBackoff duration `json:"retry_backoff"`
+	}{alias: (*alias)(c), RetryBackoff: duration(c.RetryBackoff)}
+	if err := decodeStrict(b, &aux); err != nil {
+		return err
+	}
+	c.RetryBackoff = time.Duration(aux.RetryBackoff)
+	return nil
+}
+
+func (c *DatabaseConfig) UnmarshalJSON(b []byte) error {
+	type alias DatabaseConfig
+	aux := struct {
+		*alias
+		ConnMaxLifetime duration `json:"conn_max_lifetime"`
+		ConnMaxIdleTime duration `json:"conn_max_idle_time"`
+		ConnectTimeout  duration `json:"connect_timeout"`
+		RetryBackoff    duration `json:"retry_backoff"`
+		MaxRetryBackoff duration `json:"max_retry_backoff"`
+	}{
+		alias:           (*alias)(c),
+		ConnMaxLifetime: duration(c.ConnMaxLifetime),
+		ConnMaxIdleTime: duration(c.ConnMaxIdleTime),
+		ConnectTimeout:  duration(c.ConnectTimeout),
+		RetryBackoff:    duration(c.RetryBackoff),
+		MaxRetryBackoff: duration(c.MaxRetryBackoff),
+	}
+	if err := decodeStrict(b, &aux); err != nil {
+		return err
+	}
+	c.ConnMaxLifetime = time.Duration(aux.ConnMaxLifetime)
+	c.ConnMaxIdleTime = time.Duration(aux.ConnMaxIdleTime)
+	c.ConnectTimeout = time.Duration(aux.ConnectTimeout)
+	c.RetryBackoff = time.Duration(aux.RetryBackoff)
+	c.MaxRetryBackoff = time.Duration(aux.MaxRetryBackoff)
+	return nil
+}
+
+func (c *ThreadsConfig) UnmarshalJSON(b []byte) error {
+	type alias ThreadsConfig
+	aux := struct {
+		*alias
+		MaxAge duration `json:"max_age"`
+	}{alias: (*alias)(c), MaxAge: duration(c.MaxAge)}
+	if err := decodeStrict(b, &aux); err != nil {
+		return err
+	}
+	c.MaxAge = time.Duration(aux.MaxAge)
+	return nil
+}
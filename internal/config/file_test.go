@@ -0,0 +1,196 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// clearEnv unsets every variable LoadConfig reads so the host environment
+// can't leak into a test.
+func clearEnv(t *testing.T) {
+	for _, key := range []string{
+		"CONFIG_FILE", "OPENAI_API_KEY", "GROUPME_BOT_ID", "GROUPME_BOT_TOKEN", "GROUPME_GROUPS",
+		"API_KEY", "GROUPME_ASSISTANT_ID", "MELTDOWN_ASSISTANT_ID", "TEST_ASSISTANT_ID",
+		"SLEEPER_LEAGUE_IDS", "SLEEPER_LEAGUES", "RECONCILE_ON_STARTUP", "RECONCILE_INTERVAL_HOURS",
+		"RECONCILE_COOLDOWN_MINUTES", "RECONCILE_TRANSACTION_ROUNDS", "RECONCILE_APPROVED_USERS",
+		"JOB_WORKERS", "JOB_QUEUE_SIZE", "JOB_MAX_ATTEMPTS", "JOB_RETRY_BACKOFF_SECONDS",
+		"DATABASE_URL", "HOST", "DB_HOST", "DB_PORT", "DB_USER", "DB_PASS", "DB_NAME",
+		"DB_SSLMODE", "DB_SSLROOTCERT", "DB_SSLCERT", "DB_SSLKEY", "DB_MAX_OPEN_CONNS", "DB_MAX_IDLE_CONNS",
+		"DB_CONN_MAX_LIFETIME_MINUTES", "DB_CONN_MAX_IDLE_TIME_MINUTES", "DB_CONNECT_TIMEOUT_SECONDS",
+		"DB_CONNECT_ATTEMPTS", "DB_RETRY_BACKOFF_SECONDS", "DB_MAX_RETRY_BACKOFF_SECONDS",
+		"THREAD_MAX_MESSAGES", "THREAD_MAX_AGE_DAYS", "THREAD_SUMMARY_MODEL",
+	} {
+		t.Setenv(key, "")
+	}
+}
+
+func writeConfigFile(t *testing.T, body string) string {
+	path := filepath.Join(t.TempDir(), "crowfather.json")
+	if err := os.WriteFile(path, []byte(body), 0o600); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+	return path
+}
+
+const validConfigFile = `{
+	"openai": {"api_key": "file-openai", "timeout": "90s"},
+	"groupme": {"bot_id": "file-bot", "token": "file-token"},
+	"auth": {"api_key": "file-secret"},
+	"assistants": {
+		"groupme_assistant_id": "gm",
+		"meltdown_assistant_id": "md",
+		"test_assistant_id": "test"
+	},
+	"reconciler": {
+		"leagues": [{"league_id": "league1", "group_id": "g1"}],
+		"interval": "24h",
+		"on_startup": false
+	},
+	"jobs": {"workers": 2, "retry_backoff": "5s"}
+}`
+
+func TestLoadConfig_File(t *testing.T) {
+	clearEnv(t)
+	t.Setenv("CONFIG_FILE", writeConfigFile(t, validConfigFile))
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if cfg.OpenAI.APIKey != "file-openai" || cfg.OpenAI.Timeout != 90*time.Second {
+		t.Errorf("unexpected openai config: %+v", cfg.OpenAI)
+	}
+	if cfg.OpenAI.BaseURL != "https://api.openai.com/v1" {
+		t.Errorf("expected default base URL to survive, got %q", cfg.OpenAI.BaseURL)
+	}
+	if cfg.GroupMe.Host != "api.groupme.com" || cfg.GroupMe.Timeout != 20*time.Second {
+		t.Errorf("expected groupme defaults to survive, got %+v", cfg.GroupMe)
+	}
+	if cfg.Reconciler == nil || len(cfg.Reconciler.LeagueIDs) != 1 || cfg.Reconciler.LeagueIDs[0] != "league1" {
+		t.Fatalf("expected bound league to enable the reconciler, got %+v", cfg.Reconciler)
+	}
+	if cfg.Reconciler.OnStartup || cfg.Reconciler.Interval != 24*time.Hour || cfg.Reconciler.TransactionRounds != 2 {
+		t.Errorf("unexpected reconciler config: %+v", cfg.Reconciler)
+	}
+	if cfg.Jobs.Workers != 2 || cfg.Jobs.QueueSize != 100 || cfg.Jobs.RetryBackoff != 5*time.Second {
+		t.Errorf("unexpected jobs config: %+v", cfg.Jobs)
+	}
+}
+
+func TestLoadConfig_EnvOverridesFile(t *testing.T) {
+	clearEnv(t)
+	t.Setenv("CONFIG_FILE", writeConfigFile(t, validConfigFile))
+	t.Setenv("OPENAI_API_KEY", "env-openai")
+	t.Setenv("RECONCILE_INTERVAL_HOURS", "6")
+	t.Setenv("JOB_WORKERS", "9")
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if cfg.OpenAI.APIKey != "env-openai" {
+		t.Errorf("expected env api key, got %q", cfg.OpenAI.APIKey)
+	}
+	if cfg.Reconciler.Interval != 6*time.Hour {
+		t.Errorf("expected env interval, got %v", cfg.Reconciler.Interval)
+	}
+	if cfg.Jobs.Workers != 9 {
+		t.Errorf("expected env workers, got %d", cfg.Jobs.Workers)
+	}
+	if cfg.GroupMe.BotID != "file-bot" {
+		t.Errorf("expected file bot id to remain, got %q", cfg.GroupMe.BotID)
+	}
+}
+
+func TestLoadConfig_ReportsEveryProblem(t *testing.T) {
+	clearEnv(t)
+	t.Setenv("SLEEPER_LEAGUE_IDS", "league1")
+	t.Setenv("RECONCILE_INTERVAL_HOURS", "abc")
+	t.Setenv("JOB_WORKERS", "-1")
+
+	_, err := LoadConfig()
+
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("expected *ValidationError, got %v", err)
+	}
+	for _, want := range []string{
+		"OPENAI_API_KEY", "GROUPME_BOT_ID", "GROUPME_BOT_TOKEN", "API_KEY",
+		"GROUPME_ASSISTANT_ID", "MELTDOWN_ASSISTANT_ID", "TEST_ASSISTANT_ID",
+		"RECONCILE_INTERVAL_HOURS", "JOB_WORKERS",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected error to mention %s, got:\n%v", want, err)
+		}
+	}
+}
+
+// With a config file, a missing setting names the file field as well as the
+// environment variable.
+func TestLoadConfig_MissingNamesFileField(t *testing.T) {
+	clearEnv(t)
+	path := writeConfigFile(t, `{"groupme": {"bot_id": "file-bot"}}`)
+	t.Setenv("CONFIG_FILE", path)
+
+	_, err := LoadConfig()
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("expected *ValidationError, got %v", err)
+	}
+	want := "neither the GROUPME_BOT_TOKEN environment variable nor groupme.token in " + path + " is set"
+	if !strings.Contains(err.Error(), want) {
+		t.Errorf("expected error to contain %q, got:\n%v", want, err)
+	}
+	if strings.Contains(err.Error(), "GROUPME_BOT_ID") {
+		t.Errorf("expected the file's bot id to count, got:\n%v", err)
+	}
+}
+
+func TestLoadConfig_FileErrors(t *testing.T) {
+	cases := map[string]string{
+		"unknown field":    `{"openai": {"api_kee": "typo"}}`,
+		"bad duration":     `{"jobs": {"retry_backoff": "soon"}}`,
+		"numeric duration": `{"openai": {"timeout": 60}}`,
+		"malformed":        `{"openai": `,
+	}
+	for name, body := range cases {
+		t.Run(name, func(t *testing.T) {
+			clearEnv(t)
+			t.Setenv("CONFIG_FILE", writeConfigFile(t, body))
+			if _, err := LoadConfig(); err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+}
+
+// A bad file is reported alongside the other problems, not instead of them.
+func TestLoadConfig_FileErrorJoinsValidation(t *testing.T) {
+	clearEnv(t)
+	t.Setenv("CONFIG_FILE", writeConfigFile(t, `{"openai": {"api_kee": "typo"}}`))
+	t.Setenv("JOB_WORKERS", "many")
+
+	_, err := LoadConfig()
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("expected *ValidationError, got %v", err)
+	}
+	for _, want := range []string{"failed to parse config file", "OPENAI_API_KEY", "JOB_WORKERS"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected error to mention %s, got:\n%v", want, err)
+		}
+	}
+}
+
+func TestLoadConfig_MissingFile(t *testing.T) {
+	clearEnv(t)
+	t.Setenv("CONFIG_FILE", filepath.Join(t.TempDir(), "missing.json"))
+	if _, err := LoadConfig(); err == nil {
+		t.Fatal("expected error for missing config file")
+	}
+}
@@ -0,0 +1,75 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// Watch reloads the configuration whenever the process receives SIGHUP or,
+// when CONFIG_FILE is set, the file's modification time or size changes.
+// Each config that loads cleanly is passed to apply; one that fails to load,
+// or that apply rejects, is logged and the previous config stays in effect.
+// Watch blocks until ctx is done.
+func Watch(ctx context.Context, pollInterval time.Duration, apply func(*Config) error) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	watch(ctx, os.Getenv("CONFIG_FILE"), pollInterval, hup, apply)
+}
+
+func watch(ctx context.Context, path string, pollInterval time.Duration, hup <-chan os.Signal, apply func(*Config) error) {
+	var poll <-chan time.Time
+	var last fileStamp
+	if path != "" && pollInterval > 0 {
+		ticker := time.NewTicker(pollInterval)
+		defer ticker.Stop()
+		poll = ticker.C
+		last = stampFile(path)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			fmt.Println("config: SIGHUP received, reloading")
+		case <-poll:
+			stamp := stampFile(path)
+			if stamp == last {
+				continue
+			}
+			last = stamp
+			fmt.Printf("config: %s changed, reloading\n", path)
+		}
+
+		cfg, err := LoadConfig()
+		if err != nil {
+			fmt.Printf("config: reload failed, keeping current config: %v\n", err)
+			continue
+		}
+		if err := apply(cfg); err != nil {
+			fmt.Printf("config: reload rejected, keeping current config: %v\n", err)
+			continue
+		}
+		fmt.Println("config: reload applied")
+	}
+}
+
+// fileStamp identifies a version of the config file on disk.
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+func stampFile(path string) fileStamp {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileStamp{}
+	}
+	return fileStamp{modTime: info.ModTime(), size: info.Size()}
+}
@@ -0,0 +1,79 @@
+package config
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestWatch_ReloadsOnSignal(t *testing.T) {
+	clearEnv(t)
+	path := writeConfigFile(t, validConfigFile)
+	t.Setenv("CONFIG_FILE", path)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	hup := make(chan os.Signal, 1)
+	applied := make(chan *Config, 1)
+	go watch(ctx, path, 0, hup, func(cfg *Config) error { applied <- cfg; return nil })
+
+	t.Setenv("API_KEY", "rotated")
+	hup <- os.Interrupt
+
+	select {
+	case cfg := <-applied:
+		if cfg.Auth.APIKey != "rotated" {
+			t.Errorf("expected reloaded api key, got %q", cfg.Auth.APIKey)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("config was not reloaded")
+	}
+}
+
+func TestWatch_ReloadsOnFileChange(t *testing.T) {
+	clearEnv(t)
+	path := writeConfigFile(t, validConfigFile)
+	t.Setenv("CONFIG_FILE", path)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	applied := make(chan *Config, 1)
+	go watch(ctx, path, 10*time.Millisecond, nil, func(cfg *Config) error { applied <- cfg; return nil })
+
+	time.Sleep(30 * time.Millisecond)
+	updated := `{"openai": {"api_key": "k"}, "groupme": {"bot_id": "b", "token": "t"},
+		"auth": {"api_key": "from-file"},
+		"assistants": {"groupme_assistant_id": "a", "meltdown_assistant_id": "m", "test_assistant_id": "x"}}`
+	if err := os.WriteFile(path, []byte(updated), 0o600); err != nil {
+		t.Fatalf("failed to rewrite config file: %v", err)
+	}
+
+	select {
+	case cfg := <-applied:
+		if cfg.Auth.APIKey != "from-file" || cfg.Reconciler != nil {
+			t.Errorf("unexpected reloaded config: auth=%+v reconciler=%+v", cfg.Auth, cfg.Reconciler)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("config was not reloaded after file change")
+	}
+}
+
+func TestWatch_KeepsConfigOnInvalidReload(t *testing.T) {
+	clearEnv(t)
+	path := writeConfigFile(t, `{"openai": `)
+	t.Setenv("CONFIG_FILE", path)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	hup := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	go func() {
+		watch(ctx, path, 0, hup, func(*Config) error { t.Error("invalid config must not be applied"); return nil })
+		close(done)
+	}()
+
+	hup <- os.Interrupt
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+	<-done
+}
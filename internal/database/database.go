@@ -1,9 +1,15 @@
 package database
 
 import (
+	"context"
+	"crowfather/internal/config"
 	"database/sql"
 	"fmt"
-	"os"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	_ "github.com/lib/pq"
 )
@@ -16,18 +22,116 @@ func (s *DatabaseService) DB() *sql.DB {
 	return s.db
 }
 
-func ConnectDb() (*DatabaseService, error) {
-	connStr := fmt.Sprintf("user=%s password=%s host=%s dbname=%s sslmode=disable",
-		os.Getenv("DB_USER"), os.Getenv("DB_PASS"), os.Getenv("HOST"), os.Getenv("DB_NAME"))
-	db, err := sql.Open("postgres", connStr)
+// Close releases the connection pool.
+func (s *DatabaseService) Close() error {
+	return s.db.Close()
+}
 
+// ConnectDb opens a connection pool configured by cfg and waits until the
+// server answers, retrying with exponential backoff for up to
+// cfg.ConnectAttempts attempts so a database that starts after the service
+// is still picked up. It gives up early if ctx is cancelled.
+func ConnectDb(ctx context.Context, cfg *config.DatabaseConfig) (*DatabaseService, error) {
+	db, err := sql.Open("postgres", dsn(cfg))
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database connection: %w", err)
 	}
+	db.SetMaxOpenConns(cfg.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.MaxIdleConns)
+	db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
 
-	if err := db.Ping(); err != nil {
+	err = retry(ctx, cfg.ConnectAttempts, cfg.RetryBackoff, cfg.MaxRetryBackoff, func(attempt int) error {
+		pingCtx, cancel := context.WithTimeout(ctx, cfg.ConnectTimeout)
+		defer cancel()
+		err := db.PingContext(pingCtx)
+		if err != nil && attempt < cfg.ConnectAttempts {
+			fmt.Printf("database: attempt %d/%d failed, retrying: %v\n", attempt, cfg.ConnectAttempts, err)
+		}
+		return err
+	})
+	if err != nil {
+		db.Close()
 		return nil, fmt.Errorf("failed to reach database: %w", err)
 	}
 
 	return &DatabaseService{db: db}, nil
 }
+
+// retry calls fn until it succeeds or attempts are used up, sleeping backoff
+// between calls and doubling it up to maxBackoff. It returns fn's last error,
+// or ctx's error if ctx is cancelled while waiting.
+func retry(ctx context.Context, attempts int, backoff, maxBackoff time.Duration, fn func(attempt int) error) error {
+	var err error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if err = fn(attempt); err == nil || attempt == attempts {
+			return err
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+		backoff = min(backoff*2, maxBackoff)
+	}
+	return err
+}
+
+// dsn builds the driver connection string. A configured URL is used as given
+// with any SSL settings from cfg added; otherwise a key=value DSN is built
+// from the individual fields.
+func dsn(cfg *config.DatabaseConfig) string {
+	params := map[string]string{
+		"sslmode":         cfg.SSLMode,
+		"sslrootcert":     cfg.SSLRootCert,
+		"sslcert":         cfg.SSLCert,
+		"sslkey":          cfg.SSLKey,
+		"connect_timeout": strconv.Itoa(int(cfg.ConnectTimeout.Seconds())),
+	}
+	if cfg.ConnectTimeout <= 0 {
+		delete(params, "connect_timeout")
+	}
+
+	if cfg.URL != "" {
+		if u, err := url.Parse(cfg.URL); err == nil && (u.Scheme == "postgres" || u.Scheme == "postgresql") {
+			q := u.Query()
+			for k, v := range params {
+				if v != "" {
+					q.Set(k, v)
+				}
+			}
+			u.RawQuery = q.Encode()
+			return u.String()
+		}
+		// A key=value DSN: later keys override earlier ones.
+		return strings.TrimSpace(cfg.URL + " " + keyValues(params))
+	}
+
+	params["host"] = cfg.Host
+	params["port"] = strconv.Itoa(cfg.Port)
+	params["user"] = cfg.User
+	params["password"] = cfg.Password
+	params["dbname"] = cfg.Name
+	return keyValues(params)
+}
+
+// keyValues renders the non-empty params as a sorted key='value' DSN.
+func keyValues(params map[string]string) string {
+	keys := make([]string, 0, len(params))
+	for k, v := range params {
+		if v != "" {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		v := strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(params[k])
+		parts = append(parts, fmt.Sprintf("%s='%s'", k, v))
+	}
+	return strings.Join(parts, " ")
+}
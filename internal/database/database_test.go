@@ -0,0 +1,63 @@
+package database
+
+import (
+	"context"
+	"crowfather/internal/config"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDSN_FromFields(t *testing.T) {
+	cfg := &config.DatabaseConfig{
+		Host: "db", Port: 5433, User: "crow", Password: "it's secret", Name: "crowfather",
+		SSLMode: "verify-full", SSLRootCert: "/ca.pem", ConnectTimeout: 10 * time.Second,
+	}
+	assert.Equal(t,
+		`connect_timeout='10' dbname='crowfather' host='db' password='it\'s secret' port='5433' sslmode='verify-full' sslrootcert='/ca.pem' user='crow'`,
+		dsn(cfg))
+}
+
+func TestDSN_FromURL(t *testing.T) {
+	cfg := &config.DatabaseConfig{URL: "postgres://crow:pw@db:5432/crowfather?application_name=crowfather"}
+	assert.Equal(t, "postgres://crow:pw@db:5432/crowfather?application_name=crowfather", dsn(cfg))
+
+	cfg.SSLMode = "verify-ca"
+	cfg.SSLRootCert = "/ca.pem"
+	assert.Equal(t, "postgres://crow:pw@db:5432/crowfather?application_name=crowfather&sslmode=verify-ca&sslrootcert=%2Fca.pem", dsn(cfg))
+}
+
+func TestDSN_FromKeyValueURL(t *testing.T) {
+	cfg := &config.DatabaseConfig{URL: "host=db dbname=crowfather", SSLMode: "require"}
+	assert.Equal(t, "host=db dbname=crowfather sslmode='require'", dsn(cfg))
+}
+
+func TestRetry(t *testing.T) {
+	calls := 0
+	err := retry(context.Background(), 3, time.Millisecond, 2*time.Millisecond, func(int) error {
+		calls++
+		if calls < 3 {
+			return errors.New("not yet")
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, calls)
+
+	calls = 0
+	err = retry(context.Background(), 2, time.Millisecond, time.Millisecond, func(int) error {
+		calls++
+		return errors.New("down")
+	})
+	assert.EqualError(t, err, "down")
+	assert.Equal(t, 2, calls)
+}
+
+func TestRetry_StopsWhenCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := retry(ctx, 5, time.Hour, time.Hour, func(int) error { return errors.New("down") })
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,54 @@
+package database
+
+import (
+	"context"
+	"crowfather/internal/config"
+	"database/sql"
+	"fmt"
+)
+
+type PgGroupRepository struct {
+	db *sql.DB
+}
+
+func NewPgGroupRepository(db *sql.DB) *PgGroupRepository {
+	return &PgGroupRepository{db: db}
+}
+
+func (r *PgGroupRepository) ListGroupBindings(ctx context.Context) ([]config.GroupBinding, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT group_id, bot_id, assistant_id FROM groupme_groups ORDER BY group_id`,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list group bindings: %w", err)
+	}
+	defer rows.Close()
+
+	var groups []config.GroupBinding
+	for rows.Next() {
+		var b config.GroupBinding
+		if err := rows.Scan(&b.GroupID, &b.BotID, &b.AssistantID); err != nil {
+			return nil, fmt.Errorf("failed to scan group binding: %w", err)
+		}
+		groups = append(groups, b)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list group bindings: %w", err)
+	}
+	return groups, nil
+}
+
+func (r *PgGroupRepository) SaveGroupBinding(ctx context.Context, b config.GroupBinding) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO groupme_groups (group_id, bot_id, assistant_id)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (group_id) DO UPDATE
+			SET bot_id       = EXCLUDED.bot_id,
+			    assistant_id = EXCLUDED.assistant_id,
+			    updated_at   = NOW()
+	`, b.GroupID, b.BotID, b.AssistantID)
+	if err != nil {
+		return fmt.Errorf("failed to save group binding for %s: %w", b.GroupID, err)
+	}
+	return nil
+}
@@ -14,20 +14,6 @@ func NewPgMetadataRepository(db *sql.DB) *PgMetadataRepository {
 	return &PgMetadataRepository{db: db}
 }
 
-func (r *PgMetadataRepository) Migrate(ctx context.Context) error {
-	_, err := r.db.ExecContext(ctx, `
-		CREATE TABLE IF NOT EXISTS metadata (
-			key        TEXT PRIMARY KEY,
-			value      TEXT NOT NULL,
-			updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
-		)
-	`)
-	if err != nil {
-		return fmt.Errorf("failed to run metadata migration: %w", err)
-	}
-	return nil
-}
-
 func (r *PgMetadataRepository) GetMetadata(ctx context.Context, key string) (string, error) {
 	var value string
 	err := r.db.QueryRowContext(ctx,
@@ -0,0 +1,53 @@
+package database
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadMigrations_OrdersByVersion(t *testing.T) {
+	dir := fstest.MapFS{
+		"0002_second.up.sql":  {Data: []byte("CREATE TABLE b ();")},
+		"0001_first.up.sql":   {Data: []byte("CREATE TABLE a ();")},
+		"0001_first.down.sql": {Data: []byte("DROP TABLE a;")},
+		"README.md":           {Data: []byte("ignored")},
+		"0010_tenth.up.sql":   {Data: []byte("SELECT 1;")},
+		"0010_tenth.down.sql": {Data: []byte("SELECT 1;")},
+	}
+
+	migrations, err := loadMigrations(dir)
+	require.NoError(t, err)
+	require.Len(t, migrations, 3)
+	assert.Equal(t, Migration{Version: 1, Name: "first", Up: "CREATE TABLE a ();", Down: "DROP TABLE a;"}, migrations[0])
+	assert.Equal(t, int64(2), migrations[1].Version)
+	assert.Empty(t, migrations[1].Down)
+	assert.Equal(t, int64(10), migrations[2].Version)
+}
+
+func TestLoadMigrations_Invalid(t *testing.T) {
+	cases := map[string]fstest.MapFS{
+		"bad name": {"create_tables.sql": {Data: []byte("x")}},
+		"no up":    {"0001_first.down.sql": {Data: []byte("x")}},
+		"duplicate version": {
+			"0001_first.up.sql":  {Data: []byte("x")},
+			"0001_second.up.sql": {Data: []byte("x")},
+		},
+	}
+	for name, dir := range cases {
+		_, err := loadMigrations(dir)
+		assert.Error(t, err, name)
+	}
+}
+
+func TestEmbeddedMigrations(t *testing.T) {
+	m, err := NewMigrator(nil)
+	require.NoError(t, err)
+	require.NotEmpty(t, m.migrations)
+	for i, mig := range m.migrations {
+		assert.Equal(t, int64(i+1), mig.Version, "versions are contiguous")
+		assert.NotEmpty(t, mig.Down, "%04d_%s has a down migration", mig.Version, mig.Name)
+	}
+}
@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS thread_ids (
+	context_id  TEXT PRIMARY KEY,
+	thread_id   TEXT NOT NULL,
+	created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	updated_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,5 @@
+CREATE TABLE IF NOT EXISTS metadata (
+	key        TEXT PRIMARY KEY,
+	value      TEXT NOT NULL,
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS groupme_groups (
+	group_id     TEXT PRIMARY KEY,
+	bot_id       TEXT NOT NULL,
+	assistant_id TEXT NOT NULL DEFAULT '',
+	created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	updated_at   TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS reconcile_run_documents;
+DROP TABLE IF EXISTS reconcile_runs;
@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS reconcile_runs (
+	id               BIGSERIAL PRIMARY KEY,
+	triggered_by     TEXT NOT NULL,
+	status           TEXT NOT NULL,
+	started_at       TIMESTAMPTZ NOT NULL,
+	finished_at      TIMESTAMPTZ,
+	steps            JSONB NOT NULL DEFAULT '[]',
+	documents        JSONB NOT NULL DEFAULT '[]',
+	vector_store_ids JSONB NOT NULL DEFAULT '{}',
+	error            TEXT NOT NULL DEFAULT '',
+	summary          TEXT NOT NULL DEFAULT ''
+);
+CREATE INDEX IF NOT EXISTS reconcile_runs_started_at_idx ON reconcile_runs (started_at DESC);
+
+CREATE TABLE IF NOT EXISTS reconcile_run_documents (
+	run_id  BIGINT NOT NULL REFERENCES reconcile_runs (id) ON DELETE CASCADE,
+	name    TEXT NOT NULL,
+	content TEXT NOT NULL,
+	PRIMARY KEY (run_id, name)
+);
@@ -0,0 +1,8 @@
+DELETE FROM thread_ids WHERE retired_at IS NOT NULL;
+DROP INDEX IF EXISTS thread_ids_active_idx;
+ALTER TABLE thread_ids DROP COLUMN summary;
+ALTER TABLE thread_ids DROP COLUMN retire_reason;
+ALTER TABLE thread_ids DROP COLUMN retired_at;
+ALTER TABLE thread_ids DROP COLUMN message_count;
+ALTER TABLE thread_ids DROP COLUMN id;
+ALTER TABLE thread_ids ADD PRIMARY KEY (context_id);
@@ -0,0 +1,9 @@
+-- thread_ids keeps every thread a context has used. The active thread has
+-- retired_at NULL; retired threads keep the summary carried into the next.
+ALTER TABLE thread_ids DROP CONSTRAINT thread_ids_pkey;
+ALTER TABLE thread_ids ADD COLUMN id BIGSERIAL PRIMARY KEY;
+ALTER TABLE thread_ids ADD COLUMN message_count INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE thread_ids ADD COLUMN retired_at TIMESTAMPTZ;
+ALTER TABLE thread_ids ADD COLUMN retire_reason TEXT NOT NULL DEFAULT '';
+ALTER TABLE thread_ids ADD COLUMN summary TEXT NOT NULL DEFAULT '';
+CREATE UNIQUE INDEX thread_ids_active_idx ON thread_ids (context_id) WHERE retired_at IS NULL;
@@ -0,0 +1,235 @@
+package database
+
+import (
+	"context"
+	"crowfather/internal/records"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+type PgRunRepository struct {
+	db *sql.DB
+}
+
+func NewPgRunRepository(db *sql.DB) *PgRunRepository {
+	return &PgRunRepository{db: db}
+}
+
+func (r *PgRunRepository) CreateRun(ctx context.Context, run records.RunRecord) (int64, error) {
+	steps, documents, stores, err := marshalRunDetails(run)
+	if err != nil {
+		return 0, err
+	}
+
+	var id int64
+	err = r.db.QueryRowContext(ctx, `
+		INSERT INTO reconcile_runs
+			(triggered_by, status, started_at, finished_at, steps, documents, vector_store_ids, error, summary)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id
+	`, run.Trigger, run.Status, run.StartedAt, nullTime(run.FinishedAt), steps, documents, stores, run.Error, run.Summary,
+	).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create reconcile run: %w", err)
+	}
+	return id, nil
+}
+
+func (r *PgRunRepository) UpdateRun(ctx context.Context, run records.RunRecord) error {
+	steps, documents, stores, err := marshalRunDetails(run)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.ExecContext(ctx, `
+		UPDATE reconcile_runs
+		   SET status           = $2,
+		       finished_at      = $3,
+		       steps            = $4,
+		       documents        = $5,
+		       vector_store_ids = $6,
+		       error            = $7,
+		       summary          = $8
+		 WHERE id = $1
+	`, run.ID, run.Status, nullTime(run.FinishedAt), steps, documents, stores, run.Error, run.Summary)
+	if err != nil {
+		return fmt.Errorf("failed to update reconcile run %d: %w", run.ID, err)
+	}
+	return nil
+}
+
+func (r *PgRunRepository) LastRun(ctx context.Context) (*records.RunRecord, error) {
+	row := r.db.QueryRowContext(ctx, `
+		SELECT id, triggered_by, status, started_at, finished_at, steps, documents, vector_store_ids, error, summary
+		  FROM reconcile_runs
+		 ORDER BY started_at DESC
+		 LIMIT 1
+	`)
+	run, err := scanRun(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get last reconcile run: %w", err)
+	}
+	return run, nil
+}
+
+func (r *PgRunRepository) ListRuns(ctx context.Context, limit int) ([]records.RunRecord, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, triggered_by, status, started_at, finished_at, steps, documents, vector_store_ids, error, summary
+		  FROM reconcile_runs
+		 ORDER BY started_at DESC
+		 LIMIT $1
+	`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list reconcile runs: %w", err)
+	}
+	defer rows.Close()
+
+	var runs []records.RunRecord
+	for rows.Next() {
+		run, err := scanRun(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan reconcile run: %w", err)
+		}
+		runs = append(runs, *run)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list reconcile runs: %w", err)
+	}
+	return runs, nil
+}
+
+func (r *PgRunRepository) GetRun(ctx context.Context, id int64) (*records.RunRecord, error) {
+	row := r.db.QueryRowContext(ctx, `
+		SELECT id, triggered_by, status, started_at, finished_at, steps, documents, vector_store_ids, error, summary
+		  FROM reconcile_runs
+		 WHERE id = $1
+	`, id)
+	run, err := scanRun(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reconcile run %d: %w", id, err)
+	}
+	return run, nil
+}
+
+// keptRunDocuments is how many of the most recent runs keep their documents.
+// The latest successful run keeps them regardless, as the dry run baseline.
+const keptRunDocuments = 20
+
+// SaveRunDocuments stores a run's documents and drops those of runs older
+// than the last keptRunDocuments, other than the latest successful run.
+func (r *PgRunRepository) SaveRunDocuments(ctx context.Context, runID int64, docs []records.RunDocument) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to save documents for run %d: %w", runID, err)
+	}
+	defer tx.Rollback()
+
+	for _, d := range docs {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO reconcile_run_documents (run_id, name, content)
+			VALUES ($1, $2, $3)
+			ON CONFLICT (run_id, name) DO UPDATE SET content = EXCLUDED.content
+		`, runID, d.Name, d.Content)
+		if err != nil {
+			return fmt.Errorf("failed to save document %s for run %d: %w", d.Name, runID, err)
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		DELETE FROM reconcile_run_documents
+		 WHERE run_id NOT IN (
+		           SELECT DISTINCT run_id FROM reconcile_run_documents
+		            ORDER BY run_id DESC
+		            LIMIT $1)
+		   AND run_id <> COALESCE((SELECT MAX(id) FROM reconcile_runs WHERE status = 'succeeded'), 0)
+	`, keptRunDocuments); err != nil {
+		return fmt.Errorf("failed to prune documents of old runs: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to save documents for run %d: %w", runID, err)
+	}
+	return nil
+}
+
+func (r *PgRunRepository) ListRunDocuments(ctx context.Context, runID int64) ([]records.RunDocument, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT name, content FROM reconcile_run_documents WHERE run_id = $1 ORDER BY name`, runID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list documents for run %d: %w", runID, err)
+	}
+	defer rows.Close()
+
+	var docs []records.RunDocument
+	for rows.Next() {
+		var d records.RunDocument
+		if err := rows.Scan(&d.Name, &d.Content); err != nil {
+			return nil, fmt.Errorf("failed to scan document for run %d: %w", runID, err)
+		}
+		docs = append(docs, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list documents for run %d: %w", runID, err)
+	}
+	return docs, nil
+}
+
+// scanRun reads one reconcile_runs row selected in column order.
+func scanRun(row interface{ Scan(...any) error }) (*records.RunRecord, error) {
+	var run records.RunRecord
+	var finishedAt sql.NullTime
+	var steps, documents, stores []byte
+	err := row.Scan(&run.ID, &run.Trigger, &run.Status, &run.StartedAt, &finishedAt,
+		&steps, &documents, &stores, &run.Error, &run.Summary)
+	if err != nil {
+		return nil, err
+	}
+	if finishedAt.Valid {
+		run.FinishedAt = finishedAt.Time
+	}
+	if err := json.Unmarshal(steps, &run.Steps); err != nil {
+		return nil, fmt.Errorf("failed to decode steps for run %d: %w", run.ID, err)
+	}
+	if err := json.Unmarshal(documents, &run.Documents); err != nil {
+		return nil, fmt.Errorf("failed to decode documents for run %d: %w", run.ID, err)
+	}
+	if err := json.Unmarshal(stores, &run.VectorStoreIDs); err != nil {
+		return nil, fmt.Errorf("failed to decode vector store IDs for run %d: %w", run.ID, err)
+	}
+	return &run, nil
+}
+
+func marshalRunDetails(run records.RunRecord) (steps, documents, stores []byte, err error) {
+	if run.Steps == nil {
+		run.Steps = []records.StepRecord{}
+	}
+	if run.Documents == nil {
+		run.Documents = []string{}
+	}
+	if run.VectorStoreIDs == nil {
+		run.VectorStoreIDs = map[string]string{}
+	}
+	if steps, err = json.Marshal(run.Steps); err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to encode run steps: %w", err)
+	}
+	if documents, err = json.Marshal(run.Documents); err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to encode run documents: %w", err)
+	}
+	if stores, err = json.Marshal(run.VectorStoreIDs); err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to encode run vector store IDs: %w", err)
+	}
+	return steps, documents, stores, nil
+}
+
+func nullTime(t time.Time) sql.NullTime {
+	return sql.NullTime{Time: t, Valid: !t.IsZero()}
+}
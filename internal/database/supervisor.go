@@ -0,0 +1,48 @@
+package database
+
+import (
+	"context"
+	"crowfather/internal/config"
+	"fmt"
+	"time"
+)
+
+// connect is ConnectDb, replaceable in tests.
+var connect = ConnectDb
+
+// Supervise keeps trying to connect until a connection is made and ready
+// accepts it, for a service that started without its database. Attempts back
+// off from cfg.RetryBackoff up to cfg.MaxRetryBackoff and then continue at
+// that interval. ready typically migrates the schema and swaps in live
+// repositories; if it fails the connection is closed and the loop continues.
+// Supervise returns nil once ready succeeds, or ctx.Err() if ctx is cancelled
+// first.
+func Supervise(ctx context.Context, cfg *config.DatabaseConfig, ready func(context.Context, *DatabaseService) error) error {
+	once := *cfg
+	once.ConnectAttempts = 1
+
+	backoff := cfg.RetryBackoff
+	for {
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+		backoff = min(backoff*2, cfg.MaxRetryBackoff)
+
+		dbSvc, err := connect(ctx, &once)
+		if err != nil {
+			fmt.Printf("database: still unavailable, retrying in %v: %v\n", backoff, err)
+			continue
+		}
+		if err := ready(ctx, dbSvc); err != nil {
+			fmt.Printf("database: connected but not ready, retrying in %v: %v\n", backoff, err)
+			dbSvc.Close()
+			continue
+		}
+		fmt.Println("database: connected")
+		return nil
+	}
+}
@@ -0,0 +1,56 @@
+package database
+
+import (
+	"context"
+	"crowfather/internal/config"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func fakeConnect(t *testing.T, failures int) *int {
+	calls := 0
+	orig := connect
+	t.Cleanup(func() { connect = orig })
+	connect = func(context.Context, *config.DatabaseConfig) (*DatabaseService, error) {
+		calls++
+		if calls <= failures {
+			return nil, errors.New("connection refused")
+		}
+		db, err := sql.Open("postgres", "")
+		require.NoError(t, err)
+		return &DatabaseService{db: db}, nil
+	}
+	return &calls
+}
+
+func TestSupervise_RetriesUntilReady(t *testing.T) {
+	calls := fakeConnect(t, 2)
+	cfg := &config.DatabaseConfig{RetryBackoff: time.Millisecond, MaxRetryBackoff: 2 * time.Millisecond}
+
+	readyCalls := 0
+	err := Supervise(context.Background(), cfg, func(context.Context, *DatabaseService) error {
+		readyCalls++
+		if readyCalls == 1 {
+			return errors.New("migration failed")
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 4, *calls, "two failed connects, one rejected by ready, one accepted")
+	assert.Equal(t, 2, readyCalls)
+}
+
+func TestSupervise_StopsWhenCancelled(t *testing.T) {
+	fakeConnect(t, 1000)
+	cfg := &config.DatabaseConfig{RetryBackoff: time.Millisecond, MaxRetryBackoff: time.Millisecond}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err := Supervise(ctx, cfg, func(context.Context, *DatabaseService) error { return nil })
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
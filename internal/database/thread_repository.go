@@ -2,10 +2,16 @@ package database
 
 import (
 	"context"
+	"crowfather/internal/records"
 	"database/sql"
 	"fmt"
 )
 
+// threadColumns are the thread_ids columns scanned by scanThread.
+const threadColumns = `context_id, thread_id, message_count, created_at, updated_at, retired_at, retire_reason, summary`
+
+// PgThreadRepository stores every thread a context has used. Each context has
+// at most one active thread (retired_at IS NULL); the rest are its history.
 type PgThreadRepository struct {
 	db *sql.DB
 }
@@ -14,46 +20,144 @@ func NewPgThreadRepository(db *sql.DB) *PgThreadRepository {
 	return &PgThreadRepository{db: db}
 }
 
-func (r *PgThreadRepository) Migrate(ctx context.Context) error {
-	_, err := r.db.ExecContext(ctx, `
-		CREATE TABLE IF NOT EXISTS thread_ids (
-			context_id  TEXT PRIMARY KEY,
-			thread_id   TEXT NOT NULL,
-			created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
-			updated_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
-		)
-	`)
+func (r *PgThreadRepository) GetThreadID(ctx context.Context, contextID string) (string, error) {
+	t, err := r.GetActiveThread(ctx, contextID)
+	if err != nil || t == nil {
+		return "", err
+	}
+	return t.ThreadID, nil
+}
+
+// replacedReason is the retire_reason of a thread superseded by SaveThreadID.
+const replacedReason = "replaced"
+
+// SaveThreadID makes threadID the context's active thread. A different active
+// thread is retired as replaced, so it stays in the context's history.
+func (r *PgThreadRepository) SaveThreadID(ctx context.Context, contextID, threadID string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
-		return fmt.Errorf("failed to run thread_ids migration: %w", err)
+		return fmt.Errorf("failed to save thread id for context %s: %w", contextID, err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE thread_ids SET retired_at = NOW(), retire_reason = $3, updated_at = NOW()
+		WHERE context_id = $1 AND retired_at IS NULL AND thread_id <> $2
+	`, contextID, threadID, replacedReason); err != nil {
+		return fmt.Errorf("failed to retire thread for context %s: %w", contextID, err)
+	}
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO thread_ids (context_id, thread_id)
+		VALUES ($1, $2)
+		ON CONFLICT (context_id) WHERE retired_at IS NULL DO NOTHING
+	`, contextID, threadID); err != nil {
+		return fmt.Errorf("failed to save thread id for context %s: %w", contextID, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to save thread id for context %s: %w", contextID, err)
 	}
 	return nil
 }
 
-func (r *PgThreadRepository) GetThreadID(ctx context.Context, contextID string) (string, error) {
-	var threadID string
-	err := r.db.QueryRowContext(ctx,
-		`SELECT thread_id FROM thread_ids WHERE context_id = $1`,
+// GetActiveThread returns the context's active thread, or nil if it has none.
+func (r *PgThreadRepository) GetActiveThread(ctx context.Context, contextID string) (*records.ThreadRecord, error) {
+	t, err := scanThread(r.db.QueryRowContext(ctx,
+		`SELECT `+threadColumns+` FROM thread_ids WHERE context_id = $1 AND retired_at IS NULL`,
 		contextID,
-	).Scan(&threadID)
+	))
 	if err == sql.ErrNoRows {
-		return "", nil
+		return nil, nil
 	}
 	if err != nil {
-		return "", fmt.Errorf("failed to get thread id for context %s: %w", contextID, err)
+		return nil, fmt.Errorf("failed to get thread id for context %s: %w", contextID, err)
 	}
-	return threadID, nil
+	return t, nil
 }
 
-func (r *PgThreadRepository) SaveThreadID(ctx context.Context, contextID, threadID string) error {
+// CountMessage adds one message to the context's active thread.
+func (r *PgThreadRepository) CountMessage(ctx context.Context, contextID string) error {
 	_, err := r.db.ExecContext(ctx, `
-		INSERT INTO thread_ids (context_id, thread_id)
-		VALUES ($1, $2)
-		ON CONFLICT (context_id) DO UPDATE
-			SET thread_id  = EXCLUDED.thread_id,
-			    updated_at = NOW()
-	`, contextID, threadID)
+		UPDATE thread_ids SET message_count = message_count + 1, updated_at = NOW()
+		WHERE context_id = $1 AND retired_at IS NULL
+	`, contextID)
 	if err != nil {
+		return fmt.Errorf("failed to count message for context %s: %w", contextID, err)
+	}
+	return nil
+}
+
+// RotateThread retires the context's active thread, if any, with reason and
+// summary, and makes threadID the active thread.
+func (r *PgThreadRepository) RotateThread(ctx context.Context, contextID, threadID, reason, summary string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to rotate thread for context %s: %w", contextID, err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE thread_ids SET retired_at = NOW(), retire_reason = $2, summary = $3, updated_at = NOW()
+		WHERE context_id = $1 AND retired_at IS NULL
+	`, contextID, reason, summary); err != nil {
+		return fmt.Errorf("failed to retire thread for context %s: %w", contextID, err)
+	}
+	if _, err := tx.ExecContext(ctx,
+		`INSERT INTO thread_ids (context_id, thread_id) VALUES ($1, $2)`,
+		contextID, threadID,
+	); err != nil {
 		return fmt.Errorf("failed to save thread id for context %s: %w", contextID, err)
 	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to rotate thread for context %s: %w", contextID, err)
+	}
 	return nil
 }
+
+// ListThreads returns the active thread of every context.
+func (r *PgThreadRepository) ListThreads(ctx context.Context) ([]records.ThreadRecord, error) {
+	return r.queryThreads(ctx,
+		`SELECT `+threadColumns+` FROM thread_ids WHERE retired_at IS NULL ORDER BY context_id`,
+	)
+}
+
+// ListThreadHistory returns every thread the context has used, newest first.
+func (r *PgThreadRepository) ListThreadHistory(ctx context.Context, contextID string) ([]records.ThreadRecord, error) {
+	return r.queryThreads(ctx,
+		`SELECT `+threadColumns+` FROM thread_ids WHERE context_id = $1 ORDER BY id DESC`,
+		contextID,
+	)
+}
+
+func (r *PgThreadRepository) queryThreads(ctx context.Context, query string, args ...any) ([]records.ThreadRecord, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list thread ids: %w", err)
+	}
+	defer rows.Close()
+
+	var threads []records.ThreadRecord
+	for rows.Next() {
+		t, err := scanThread(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan thread id: %w", err)
+		}
+		threads = append(threads, *t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list thread ids: %w", err)
+	}
+	return threads, nil
+}
+
+func scanThread(row interface{ Scan(...any) error }) (*records.ThreadRecord, error) {
+	var t records.ThreadRecord
+	var retiredAt sql.NullTime
+	if err := row.Scan(&t.ContextID, &t.ThreadID, &t.MessageCount, &t.CreatedAt, &t.UpdatedAt,
+		&retiredAt, &t.RetireReason, &t.Summary); err != nil {
+		return nil, err
+	}
+	t.RetiredAt = retiredAt.Time
+	return &t, nil
+}
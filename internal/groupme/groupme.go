@@ -5,20 +5,39 @@ import (
 	"context"
 	"crowfather/internal/config"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"sync"
 	"time"
 )
 
+// GroupRepository is the persistence contract for group bindings.
+// The concrete implementation lives in the database package.
+type GroupRepository interface {
+	ListGroupBindings(ctx context.Context) ([]config.GroupBinding, error)
+}
+
 type GroupMeService struct {
 	Client *http.Client
 	Config *config.GroupMeConfig
+
+	mu     sync.RWMutex
+	groups map[string]config.GroupBinding // group_id → binding from Config
+	stored *storedGroups                  // shared with the services WithConfig derives
+}
+
+// storedGroups are the group bindings loaded from the repository. A nil
+// value holds none.
+type storedGroups struct {
+	mu     sync.RWMutex
+	groups map[string]config.GroupBinding
 }
 
 func NewGroupMeService(config *config.GroupMeConfig) *GroupMeService {
-	return &GroupMeService{
+	g := &GroupMeService{
 		Client: &http.Client{
 			Timeout: 20 * time.Second,
 			Transport: &http.Transport{
@@ -26,11 +45,144 @@ func NewGroupMeService(config *config.GroupMeConfig) *GroupMeService {
 			},
 		},
 		Config: config,
+		stored: &storedGroups{},
 	}
+	g.SetGroups(config.Groups)
+	return g
 }
 
-func (g *GroupMeService) SendMessage(message Message, response string) (bool, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), g.Config.Timeout)
+// WithConfig returns a service for reloaded settings. It shares the client
+// and the bindings loaded from the repository with g, whose replies keep
+// g's settings.
+func (g *GroupMeService) WithConfig(cfg *config.GroupMeConfig) *GroupMeService {
+	return &GroupMeService{
+		Client: g.Client,
+		Config: cfg,
+		groups: mergeGroups(cfg.Groups),
+		stored: g.stored,
+	}
+}
+
+// SetGroups replaces the configured group bindings used to route replies.
+func (g *GroupMeService) SetGroups(bindings []config.GroupBinding) {
+	groups := mergeGroups(bindings)
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.groups = groups
+}
+
+// LoadGroups adds the bindings stored in the repository to those from
+// config, for g and every service derived from it. Config entries win when
+// both define the same group.
+func (g *GroupMeService) LoadGroups(ctx context.Context, repo GroupRepository) error {
+	stored, err := repo.ListGroupBindings(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load group bindings: %w", err)
+	}
+
+	g.stored.mu.Lock()
+	g.stored.groups = mergeGroups(stored)
+	g.stored.mu.Unlock()
+
+	g.SetGroups(g.Config.Groups)
+	return nil
+}
+
+// Bindings returns the group bindings a service for cfg would use: those
+// loaded from the repository, overridden by cfg's.
+func (g *GroupMeService) Bindings(cfg *config.GroupMeConfig) map[string]config.GroupBinding {
+	groups := g.stored.all()
+	for id, b := range mergeGroups(cfg.Groups) {
+		groups[id] = b
+	}
+	return groups
+}
+
+// all returns a copy of the stored bindings.
+func (s *storedGroups) all() map[string]config.GroupBinding {
+	groups := make(map[string]config.GroupBinding)
+	if s == nil {
+		return groups
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for id, b := range s.groups {
+		groups[id] = b
+	}
+	return groups
+}
+
+func (s *storedGroups) get(groupID string) (config.GroupBinding, bool) {
+	if s == nil {
+		return config.GroupBinding{}, false
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	b, ok := s.groups[groupID]
+	return b, ok
+}
+
+func (s *storedGroups) empty() bool {
+	if s == nil {
+		return true
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.groups) == 0
+}
+
+// mergeGroups indexes bindings by group, later entries overriding earlier ones.
+func mergeGroups(lists ...[]config.GroupBinding) map[string]config.GroupBinding {
+	groups := make(map[string]config.GroupBinding)
+	for _, bindings := range lists {
+		for _, b := range bindings {
+			groups[b.GroupID] = b
+		}
+	}
+	return groups
+}
+
+// Group returns the binding for a GroupMe group, if one is configured.
+func (g *GroupMeService) Group(groupID string) (config.GroupBinding, bool) {
+	g.mu.RLock()
+	b, ok := g.groups[groupID]
+	g.mu.RUnlock()
+	if ok {
+		return b, true
+	}
+	return g.stored.get(groupID)
+}
+
+// bound reports whether any group has a binding.
+func (g *GroupMeService) bound() bool {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return len(g.groups) > 0 || !g.stored.empty()
+}
+
+// ErrNoBot is returned when a message is sent to a group no bot can post in.
+var ErrNoBot = errors.New("no GroupMe bot for group")
+
+// botID returns the bot that posts in the group. A bot can only post in its
+// own group, so the default bot is used for unbound groups only while no
+// group bindings exist, when it is the one group the service serves. An
+// empty groupID is the default bot's group.
+func (g *GroupMeService) botID(groupID string) (string, error) {
+	if b, ok := g.Group(groupID); ok {
+		return b.BotID, nil
+	}
+	if g.Config.BotID == "" {
+		return "", fmt.Errorf("%w %q: bind it or set GROUPME_BOT_ID", ErrNoBot, groupID)
+	}
+	if groupID != "" && g.bound() {
+		return "", fmt.Errorf("%w %q: the group has no binding in GROUPME_GROUPS", ErrNoBot, groupID)
+	}
+	return g.Config.BotID, nil
+}
+
+func (g *GroupMeService) SendMessage(ctx context.Context, message Message, response string) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, g.Config.Timeout)
 
 	defer cancel()
 
@@ -52,21 +204,32 @@ func (g *GroupMeService) SendMessage(message Message, response string) (bool, er
 }
 
 func (g *GroupMeService) buildUrl() *url.URL {
+	cfg := g.Config
 	return &url.URL{
 		Scheme: "https",
-		Host:   g.Config.Host,
-		Path:   g.Config.Path,
+		Host:   cfg.Host,
+		Path:   cfg.Path,
 	}
 }
 
-// SendRawMessage sends text to the GroupMe group without an @mention prefix.
+// SendRawMessage sends text through the default bot without an @mention prefix.
 // Used for bot-initiated messages such as reconciliation completion summaries.
 func (g *GroupMeService) SendRawMessage(text string) error {
+	return g.SendGroupMessage("", text)
+}
+
+// SendGroupMessage sends text without an @mention prefix through the bot that
+// posts in groupID; see botID.
+func (g *GroupMeService) SendGroupMessage(groupID string, text string) error {
 	ctx, cancel := context.WithTimeout(context.Background(), g.Config.Timeout)
 	defer cancel()
 
+	botID, err := g.botID(groupID)
+	if err != nil {
+		return err
+	}
 	payload, err := json.Marshal(MessageSendRequest{
-		BotId: g.Config.BotID,
+		BotId: botID,
 		Text:  text,
 	})
 	if err != nil {
@@ -82,8 +245,12 @@ func (g *GroupMeService) SendRawMessage(text string) error {
 }
 
 func (g *GroupMeService) buildPayload(message Message, response string) ([]byte, error) {
+	botID, err := g.botID(message.GroupId)
+	if err != nil {
+		return nil, err
+	}
 	return json.Marshal(MessageSendRequest{
-		BotId: g.Config.BotID,
+		BotId: botID,
 		Text:  fmt.Sprintf("@%s %s", message.Name, response),
 	})
 }
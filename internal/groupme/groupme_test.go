@@ -3,6 +3,7 @@ package groupme
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
@@ -12,7 +13,7 @@ import (
 )
 
 func newService() *GroupMeService {
-	return &GroupMeService{Config: &config.GroupMeConfig{BotID: "bot", Token: "token", Host: "example.com", Path: "/v3/bots/post"}, Client: http.DefaultClient}
+	return &GroupMeService{Config: &config.GroupMeConfig{BotID: "bot", Token: "token", Host: "example.com", Path: "/v3/bots/post"}, Client: http.DefaultClient, stored: &storedGroups{}}
 }
 
 func TestBuildURL(t *testing.T) {
@@ -121,3 +122,150 @@ func TestSendRawMessage_Failure(t *testing.T) {
 		t.Fatal("expected error on non-202 response")
 	}
 }
+
+func TestBuildPayload_UsesGroupBot(t *testing.T) {
+	svc := newService()
+	svc.SetGroups([]config.GroupBinding{{GroupID: "g2", BotID: "bot2"}})
+
+	payload, err := svc.buildPayload(Message{Name: "Bob", GroupId: "g2"}, "hi")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var req MessageSendRequest
+	if err := json.Unmarshal(payload, &req); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+	if req.BotId != "bot2" {
+		t.Errorf("expected group bot bot2, got %q", req.BotId)
+	}
+
+	// The default bot posts in its own group only, so it can't answer a
+	// group without a binding once there are bindings.
+	if _, err := svc.buildPayload(Message{Name: "Bob", GroupId: "unknown"}, "hi"); !errors.Is(err, ErrNoBot) {
+		t.Errorf("expected ErrNoBot for an unbound group, got %v", err)
+	}
+}
+
+func TestBuildPayload_DefaultBotWithoutBindings(t *testing.T) {
+	svc := newService()
+
+	payload, err := svc.buildPayload(Message{Name: "Bob", GroupId: "g1"}, "hi")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var req MessageSendRequest
+	if err := json.Unmarshal(payload, &req); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+	if req.BotId != "bot" {
+		t.Errorf("expected the default bot without bindings, got %q", req.BotId)
+	}
+}
+
+func TestBuildPayload_UnknownGroupWithoutDefaultBot(t *testing.T) {
+	svc := newService()
+	svc.Config.BotID = ""
+	svc.SetGroups([]config.GroupBinding{{GroupID: "g2", BotID: "bot2"}})
+
+	if _, err := svc.buildPayload(Message{Name: "Bob", GroupId: "unknown"}, "hi"); !errors.Is(err, ErrNoBot) {
+		t.Errorf("expected ErrNoBot for an unbound group, got %v", err)
+	}
+	if err := svc.SendRawMessage("hi"); !errors.Is(err, ErrNoBot) {
+		t.Errorf("expected ErrNoBot without a default bot, got %v", err)
+	}
+}
+
+type stubGroupRepo struct {
+	groups []config.GroupBinding
+}
+
+func (s stubGroupRepo) ListGroupBindings(context.Context) ([]config.GroupBinding, error) {
+	return s.groups, nil
+}
+
+func TestLoadGroups_ConfigOverridesStored(t *testing.T) {
+	svc := newService()
+	svc.Config.Groups = []config.GroupBinding{{GroupID: "g1", BotID: "from-config"}}
+
+	err := svc.LoadGroups(context.Background(), stubGroupRepo{groups: []config.GroupBinding{
+		{GroupID: "g1", BotID: "from-db"},
+		{GroupID: "g2", BotID: "db-only"},
+	}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if b, _ := svc.Group("g1"); b.BotID != "from-config" {
+		t.Errorf("expected config binding to win, got %q", b.BotID)
+	}
+	if b, ok := svc.Group("g2"); !ok || b.BotID != "db-only" {
+		t.Errorf("expected stored binding for g2, got %+v", b)
+	}
+}
+
+func TestBindings_DoesNotApplyConfig(t *testing.T) {
+	svc := newService()
+	err := svc.LoadGroups(context.Background(), stubGroupRepo{groups: []config.GroupBinding{
+		{GroupID: "g1", BotID: "from-db"},
+	}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	groups := svc.Bindings(&config.GroupMeConfig{Groups: []config.GroupBinding{{GroupID: "g2", BotID: "next"}}})
+	if groups["g1"].BotID != "from-db" || groups["g2"].BotID != "next" {
+		t.Errorf("expected stored and next bindings, got %+v", groups)
+	}
+	if _, ok := svc.Group("g2"); ok {
+		t.Error("Bindings must not apply the config")
+	}
+}
+
+func TestWithConfig_SharesStoredGroups(t *testing.T) {
+	svc := newService()
+	err := svc.LoadGroups(context.Background(), stubGroupRepo{groups: []config.GroupBinding{
+		{GroupID: "g1", BotID: "from-db"},
+	}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	prev := svc
+	svc = svc.WithConfig(&config.GroupMeConfig{
+		BotID:  "new-default",
+		Token:  "new-token",
+		Groups: []config.GroupBinding{{GroupID: "g2", BotID: "from-reload"}},
+	})
+
+	if b, ok := svc.Group("g1"); !ok || b.BotID != "from-db" {
+		t.Errorf("expected stored binding to survive reload, got %+v", b)
+	}
+	if b, ok := svc.Group("g2"); !ok || b.BotID != "from-reload" {
+		t.Errorf("expected reloaded binding for g2, got %+v", b)
+	}
+	if got, _ := svc.botID(""); got != "new-default" {
+		t.Errorf("expected reloaded default bot, got %q", got)
+	}
+	if req := svc.buildRequest(context.Background(), nil); req.Header.Get("Authorization") != "new-token" {
+		t.Errorf("expected reloaded token, got %q", req.Header.Get("Authorization"))
+	}
+
+	// The original service keeps its settings for replies already under way.
+	if _, ok := prev.Group("g2"); ok {
+		t.Error("the original service must not see the reloaded bindings")
+	}
+	if got, _ := prev.botID(""); got != "bot" {
+		t.Errorf("expected the original default bot, got %q", got)
+	}
+
+	// Bindings loaded later reach the derived service too.
+	err = prev.LoadGroups(context.Background(), stubGroupRepo{groups: []config.GroupBinding{
+		{GroupID: "g3", BotID: "late"},
+	}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if b, ok := svc.Group("g3"); !ok || b.BotID != "late" {
+		t.Errorf("expected binding loaded through the original, got %+v", b)
+	}
+}
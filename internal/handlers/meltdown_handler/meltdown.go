@@ -1,6 +1,7 @@
 package meltdown_handler
 
 import (
+	"context"
 	"crowfather/internal/open_ai"
 )
 
@@ -21,19 +22,11 @@ func processMessage(message string, oai *open_ai.OpenAIService, assistantID stri
 		return "", err
 	}
 
-	msg, err := oai.CreateMessage(message, threadId)
-
-	if err != nil {
-		return "", err
-	}
-
-	run, err := oai.CreateRun(msg.ThreadID, assistantID)
-
-	if err != nil {
+	if err := oai.QueueMessage(threadId, message); err != nil {
 		return "", err
 	}
 
-	resp, err := oai.GetResponse(run, msg.ID)
+	resp, err := oai.RunThread(context.Background(), threadId, assistantID)
 
 	if err != nil {
 		return "", err
@@ -1,15 +1,16 @@
 package message_handler
 
 import (
+	"context"
 	"crowfather/internal/groupme"
+	"crowfather/internal/jobs"
 	"crowfather/internal/open_ai"
 	"fmt"
-	"github.com/openai/openai-go"
 	"strings"
 )
 
 func Handle(message groupme.Message, oai *open_ai.OpenAIService, gms *groupme.GroupMeService, assistantID string) (string, error) {
-	err := validateMessage(message)
+	err := ValidateMessage(message)
 
 	if err != nil {
 		return "", err
@@ -17,14 +18,14 @@ func Handle(message groupme.Message, oai *open_ai.OpenAIService, gms *groupme.Gr
 
 	shouldRespond := strings.Contains(strings.ToLower(message.Text), "hey crowfather")
 	message.Text = cleanMessage(message.Text)
-	resp, err := processMessage(message, oai, assistantID, shouldRespond)
+	resp, err := processMessage(context.Background(), message, oai, assistantID, shouldRespond)
 
 	if err != nil || resp == "" {
 		return "", err
 	}
 
 	if message.UserId != "" {
-		_, err = gms.SendMessage(message, resp)
+		_, err = gms.SendMessage(context.Background(), message, resp)
 
 		if err != nil {
 			return "", err
@@ -34,42 +35,85 @@ func Handle(message groupme.Message, oai *open_ai.OpenAIService, gms *groupme.Gr
 	return "", nil
 }
 
-func processMessage(message groupme.Message, oai *open_ai.OpenAIService, assistantID string, shouldRespond bool) (string, error) {
-	msg, err := addMessageToThread(message, oai)
+// NewJob wraps message processing as a background job. Each step records its
+// result, so a retry resumes after the last step that succeeded instead of
+// posting the message to the thread a second time. Once the job's ctx is done
+// no further step is started, and the run and the reply are cut short.
+func NewJob(message groupme.Message, oai *open_ai.OpenAIService, gms *groupme.GroupMeService, assistantID string) jobs.Job {
+	shouldRespond := strings.Contains(strings.ToLower(message.Text), "hey crowfather")
+	message.Text = cleanMessage(message.Text)
+
+	var threadId string
+	var resp string
+
+	return jobs.Job{
+		Name: fmt.Sprintf("groupme message %s", message.Id),
+		Run: func(ctx context.Context) error {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if threadId == "" {
+				id, err := addMessageToThread(message, oai)
+
+				if err != nil {
+					return err
+				}
+				threadId = id
+			}
+
+			if !shouldRespond {
+				return nil
+			}
+
+			if resp == "" {
+				r, err := respondToThread(ctx, threadId, oai, assistantID)
+
+				if err != nil {
+					return err
+				}
+				resp = r
+			}
+
+			if resp == "" || message.UserId == "" {
+				return nil
+			}
+
+			_, err := gms.SendMessage(ctx, message, resp)
+			return err
+		},
+	}
+}
+
+func processMessage(ctx context.Context, message groupme.Message, oai *open_ai.OpenAIService, assistantID string, shouldRespond bool) (string, error) {
+	threadId, err := addMessageToThread(message, oai)
 
 	if err != nil {
 		return "", err
 	}
 
 	if shouldRespond {
-		return respondToThread(msg, oai, assistantID)
+		return respondToThread(ctx, threadId, oai, assistantID)
 	}
 	return "", nil
 }
 
-func addMessageToThread(message groupme.Message, oai *open_ai.OpenAIService) (openai.Message, error) {
-	threadId, err := oai.GetOrCreateThread(message.GroupId)
+// addMessageToThread posts the message to the group's thread, or buffers it
+// if a run is already in progress there, and returns the thread ID.
+func addMessageToThread(message groupme.Message, oai *open_ai.OpenAIService) (string, error) {
+	threadId, err := oai.ThreadForMessage(message.GroupId)
 
 	if err != nil {
-		return openai.Message{}, err
-	}
-
-	msg, err := oai.CreateMessage(message.Text, threadId)
-
-	if err != nil {
-		return openai.Message{}, err
+		return "", err
 	}
-	return msg, nil
-}
 
-func respondToThread(message openai.Message, oai *open_ai.OpenAIService, assistantID string) (string, error) {
-	run, err := oai.CreateRun(message.ThreadID, assistantID)
-
-	if err != nil {
+	if err := oai.QueueMessage(threadId, message.Text); err != nil {
 		return "", err
 	}
+	return threadId, nil
+}
 
-	resp, err := oai.GetResponse(run, message.ID)
+func respondToThread(ctx context.Context, threadId string, oai *open_ai.OpenAIService, assistantID string) (string, error) {
+	resp, err := oai.RunThread(ctx, threadId, assistantID)
 
 	if err != nil {
 		return "", err
@@ -78,7 +122,9 @@ func respondToThread(message openai.Message, oai *open_ai.OpenAIService, assista
 	return resp, nil
 }
 
-func validateMessage(message groupme.Message) error {
+// ValidateMessage rejects messages that should never reach the assistant,
+// such as the bot's own posts echoed back through the webhook.
+func ValidateMessage(message groupme.Message) error {
 	if message.SenderType != "user" {
 		return fmt.Errorf("message is not from a user")
 	}
@@ -1,6 +1,8 @@
 package message_handler
 
 import (
+	"context"
+	"crowfather/internal/groupme"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -19,3 +21,13 @@ func TestCleanMessageSpaceBeforeComma(t *testing.T) {
 	// Original: TrimPrefix(",") silently skips the comma when whitespace precedes it.
 	assert.Equal(t, "what time is it?", cleanMessage("hey crowfather , what time is it?"))
 }
+
+func TestNewJob_StopsOnceContextIsDone(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// No services are needed: a cancelled job must not start any step.
+	job := NewJob(groupme.Message{Id: "1", Text: "hey crowfather, hi"}, nil, nil, "asst")
+
+	assert.ErrorIs(t, job.Run(ctx), context.Canceled)
+}
@@ -0,0 +1,149 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrQueueFull is returned by Enqueue when the buffer is at capacity.
+var ErrQueueFull = errors.New("job queue is full")
+
+// ErrQueueStopped is returned by Enqueue after Stop has been called.
+var ErrQueueStopped = errors.New("job queue is stopped")
+
+// Job is a unit of background work. Run is retried on error until it succeeds
+// or MaxAttempts is reached, so it must be safe to call more than once.
+type Job struct {
+	Name string
+	Run  func(ctx context.Context) error
+}
+
+// Queue is an in-process worker pool with bounded concurrency and retries.
+type Queue struct {
+	jobs        chan Job
+	workers     int
+	maxAttempts int
+	backoff     time.Duration
+
+	mu      sync.RWMutex
+	stopped bool
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+// NewQueue creates a queue with the given number of workers, buffer capacity,
+// attempts per job and base retry backoff (doubled after each failure).
+func NewQueue(workers, capacity, maxAttempts int, backoff time.Duration) *Queue {
+	if workers < 1 {
+		workers = 1
+	}
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+	return &Queue{
+		jobs:        make(chan Job, capacity),
+		workers:     workers,
+		maxAttempts: maxAttempts,
+		backoff:     backoff,
+	}
+}
+
+// Start launches the workers. Cancelling ctx aborts retries in progress.
+func (q *Queue) Start(ctx context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	q.cancel = cancel
+
+	for i := 0; i < q.workers; i++ {
+		q.wg.Add(1)
+		go q.work(ctx)
+	}
+}
+
+// Enqueue adds a job without blocking.
+func (q *Queue) Enqueue(job Job) error {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	if q.stopped {
+		return ErrQueueStopped
+	}
+
+	select {
+	case q.jobs <- job:
+		return nil
+	default:
+		return ErrQueueFull
+	}
+}
+
+// Stop stops accepting jobs and waits for queued and in-flight jobs to finish.
+// If ctx expires first, pending retries are cancelled and ctx.Err() is returned.
+func (q *Queue) Stop(ctx context.Context) error {
+	q.mu.Lock()
+	if !q.stopped {
+		q.stopped = true
+		close(q.jobs)
+	}
+	q.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		q.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		if q.cancel != nil {
+			q.cancel()
+		}
+		return ctx.Err()
+	}
+}
+
+func (q *Queue) work(ctx context.Context) {
+	defer q.wg.Done()
+	for job := range q.jobs {
+		q.process(ctx, job)
+	}
+}
+
+// process runs a job with retries, recovering from panics so one bad job
+// cannot take down a worker.
+func (q *Queue) process(ctx context.Context, job Job) {
+	delay := q.backoff
+	for attempt := 1; attempt <= q.maxAttempts; attempt++ {
+		err := runSafely(ctx, job)
+		if err == nil {
+			return
+		}
+
+		if attempt == q.maxAttempts {
+			fmt.Printf("jobs: %s failed after %d attempt(s): %v\n", job.Name, attempt, err)
+			return
+		}
+
+		fmt.Printf("jobs: %s attempt %d failed, retrying in %v: %v\n", job.Name, attempt, delay, err)
+		select {
+		case <-ctx.Done():
+			fmt.Printf("jobs: %s abandoned: %v\n", job.Name, ctx.Err())
+			return
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+func runSafely(ctx context.Context, job Job) (err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			err = fmt.Errorf("panic: %v", rec)
+		}
+	}()
+	return job.Run(ctx)
+}
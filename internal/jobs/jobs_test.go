@@ -0,0 +1,113 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueue_RunsJobs(t *testing.T) {
+	q := NewQueue(2, 10, 1, 0)
+	q.Start(context.Background())
+
+	var count atomic.Int32
+	for i := 0; i < 5; i++ {
+		require.NoError(t, q.Enqueue(Job{Name: "count", Run: func(context.Context) error {
+			count.Add(1)
+			return nil
+		}}))
+	}
+
+	require.NoError(t, q.Stop(context.Background()))
+	assert.Equal(t, int32(5), count.Load())
+}
+
+func TestQueue_RetriesUntilSuccess(t *testing.T) {
+	q := NewQueue(1, 1, 3, time.Millisecond)
+	q.Start(context.Background())
+
+	var attempts atomic.Int32
+	require.NoError(t, q.Enqueue(Job{Name: "flaky", Run: func(context.Context) error {
+		if attempts.Add(1) < 3 {
+			return errors.New("not yet")
+		}
+		return nil
+	}}))
+
+	require.NoError(t, q.Stop(context.Background()))
+	assert.Equal(t, int32(3), attempts.Load())
+}
+
+func TestQueue_GivesUpAfterMaxAttempts(t *testing.T) {
+	q := NewQueue(1, 1, 2, time.Millisecond)
+	q.Start(context.Background())
+
+	var attempts atomic.Int32
+	require.NoError(t, q.Enqueue(Job{Name: "broken", Run: func(context.Context) error {
+		attempts.Add(1)
+		return errors.New("always")
+	}}))
+
+	require.NoError(t, q.Stop(context.Background()))
+	assert.Equal(t, int32(2), attempts.Load())
+}
+
+func TestQueue_RecoversFromPanic(t *testing.T) {
+	q := NewQueue(1, 2, 1, 0)
+	q.Start(context.Background())
+
+	var ran atomic.Bool
+	require.NoError(t, q.Enqueue(Job{Name: "panics", Run: func(context.Context) error { panic("boom") }}))
+	require.NoError(t, q.Enqueue(Job{Name: "after", Run: func(context.Context) error {
+		ran.Store(true)
+		return nil
+	}}))
+
+	require.NoError(t, q.Stop(context.Background()))
+	assert.True(t, ran.Load(), "worker should survive a panicking job")
+}
+
+func TestQueue_EnqueueFull(t *testing.T) {
+	q := NewQueue(1, 1, 1, 0) // not started: nothing drains the buffer
+	require.NoError(t, q.Enqueue(Job{Name: "a", Run: func(context.Context) error { return nil }}))
+	assert.ErrorIs(t, q.Enqueue(Job{Name: "b", Run: func(context.Context) error { return nil }}), ErrQueueFull)
+}
+
+func TestQueue_EnqueueAfterStop(t *testing.T) {
+	q := NewQueue(1, 1, 1, 0)
+	q.Start(context.Background())
+	require.NoError(t, q.Stop(context.Background()))
+	assert.ErrorIs(t, q.Enqueue(Job{Name: "late", Run: func(context.Context) error { return nil }}), ErrQueueStopped)
+}
+
+func TestQueue_BoundedConcurrency(t *testing.T) {
+	q := NewQueue(2, 10, 1, 0)
+	q.Start(context.Background())
+
+	var mu sync.Mutex
+	active, peak := 0, 0
+	for i := 0; i < 6; i++ {
+		require.NoError(t, q.Enqueue(Job{Name: "slow", Run: func(context.Context) error {
+			mu.Lock()
+			active++
+			if active > peak {
+				peak = active
+			}
+			mu.Unlock()
+			time.Sleep(5 * time.Millisecond)
+			mu.Lock()
+			active--
+			mu.Unlock()
+			return nil
+		}}))
+	}
+
+	require.NoError(t, q.Stop(context.Background()))
+	assert.LessOrEqual(t, peak, 2)
+}
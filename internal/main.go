@@ -2,90 +2,61 @@ package main
 
 import (
 	"context"
-	"crowfather/internal/config"
-	"crowfather/internal/database"
-	"crowfather/internal/espn"
-	"crowfather/internal/groupme"
-	"crowfather/internal/open_ai"
-	"crowfather/internal/reconciler"
-	"crowfather/internal/router"
-	"crowfather/internal/sleeper"
 	"fmt"
-	"time"
-
-	"github.com/gin-gonic/gin"
+	"os"
+	"os/signal"
+	"syscall"
 )
 
-func main() {
-	cfg, err := config.LoadConfig()
-	if err != nil {
-		fmt.Printf("Failed to load config: %v\n", err)
-		return
-	}
-
-	// Database — optional. Service runs in memory-only mode if unavailable.
-	var threadRepo open_ai.ThreadRepository
-	var metaRepo reconciler.MetadataRepository
-	dbSvc, err := database.ConnectDb()
-	if err != nil {
-		fmt.Printf("Database unavailable, running in memory-only mode: %v\n", err)
-	} else {
-		pgThread := database.NewPgThreadRepository(dbSvc.DB())
-		if err := pgThread.Migrate(context.Background()); err != nil {
-			fmt.Printf("Thread schema migration failed: %v\n", err)
-		} else {
-			threadRepo = pgThread
-		}
-
-		pgMeta := database.NewPgMetadataRepository(dbSvc.DB())
-		if err := pgMeta.Migrate(context.Background()); err != nil {
-			fmt.Printf("Metadata schema migration failed: %v\n", err)
-		} else {
-			metaRepo = pgMeta
-		}
-	}
+// command is one crowfather subcommand. args excludes the subcommand name.
+type command struct {
+	name  string
+	usage string
+	run   func(ctx context.Context, args []string) error
+}
 
-	oai := open_ai.NewOpenAIService(cfg.OpenAI, threadRepo)
-	gms := groupme.NewGroupMeService(cfg.GroupMe)
+var commands = []command{
+	{"serve", "serve                               run the HTTP server (default)", serve},
+	{"reconcile", "reconcile [--dry-run]               run a roster reconciliation and wait for it", reconcileCmd},
+	{"ask", "ask --assistant NAME \"question\"      ask an assistant (test, meltdown, groupme or an asst_ ID)", askCmd},
+	{"threads", "threads list|history [CONTEXT_ID]   list or inspect conversation threads", threadsCmd},
+	{"vector-stores", "vector-stores list|prune [--dry-run] list vector stores or delete unused reconciler stores", vectorStoresCmd},
+	{"migrate", "migrate [up|down [N]|status]        apply, roll back or list schema migrations", migrateCmd},
+}
 
-	// Reconciler — optional. Only constructed when SLEEPER_LEAGUE_IDS is set.
-	var rec *reconciler.Reconciler
-	if cfg.Reconciler != nil {
-		rec = reconciler.NewReconciler(
-			espn.NewESPNService(),
-			sleeper.NewSleeperService(),
-			oai,
-			metaRepo,
-			cfg.Reconciler.LeagueIDs,
-			cfg.Assistants.GroupMeAssistantID,
-			cfg.Reconciler.TransactionRounds,
-			cfg.Reconciler.CooldownMinutes,
-			cfg.Reconciler.ApprovedUsers,
-		)
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
 
-		// Startup trigger.
-		if cfg.Reconciler.OnStartup {
-			fmt.Println("Starting initial roster reconciliation...")
-			rec.Trigger("", nil)
-		}
+	name, args := "serve", os.Args[1:]
+	if len(args) > 0 {
+		name, args = args[0], args[1:]
+	}
 
-		// Periodic cron goroutine.
-		go func() {
-			ticker := time.NewTicker(cfg.Reconciler.Interval)
-			defer ticker.Stop()
-			for range ticker.C {
-				fmt.Println("Cron: triggering scheduled roster reconciliation")
-				rec.Trigger("", nil)
+	for _, cmd := range commands {
+		if cmd.name == name {
+			if err := cmd.run(ctx, args); err != nil {
+				fmt.Fprintf(os.Stderr, "crowfather %s: %v\n", name, err)
+				os.Exit(1)
 			}
-		}()
+			return
+		}
 	}
 
-	r, err := router.NewRouter(oai, gms, rec, cfg)
-	if err != nil {
-		return
+	if name != "help" && name != "-h" && name != "--help" {
+		fmt.Fprintf(os.Stderr, "crowfather: unknown command %q\n\n", name)
+	}
+	printUsage()
+	if name != "help" && name != "-h" && name != "--help" {
+		os.Exit(2)
 	}
+}
 
-	engine := gin.Default()
-	r.RegisterRoutes(engine)
-	engine.Run()
+func printUsage() {
+	fmt.Fprintln(os.Stderr, "Usage: crowfather <command> [arguments]")
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "Commands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %s\n", cmd.usage)
+	}
 }
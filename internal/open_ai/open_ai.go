@@ -6,11 +6,9 @@ import (
 	"fmt"
 	"github.com/openai/openai-go"
 	"github.com/openai/openai-go/option"
-	"github.com/openai/openai-go/packages/pagination"
 	"github.com/openai/openai-go/packages/param"
 	"strings"
 	"sync"
-	"time"
 )
 
 type Config struct {
@@ -29,11 +27,27 @@ type ThreadRepository interface {
 
 type OpenAIService struct {
 	ThreadClient *openai.BetaThreadService
-	ThreadIds    map[string]string
+	ThreadIds    map[string]string // shared with derived services; guarded by mu
 	Config       *config.OpenAIConfig
 	Options      []option.RequestOption
-	Repo         ThreadRepository
-	mu           sync.RWMutex
+	Tools        *ToolRegistry         // nil disables function tool calls
+	policy       *config.ThreadsConfig // nil disables rotation; guarded by mu
+	toolUsers    map[string]bool       // assistants runs send Tools to
+
+	*threadStore
+}
+
+// threadStore is the thread state an OpenAIService shares with the services
+// WithThreadPolicy and WithTools derive from it.
+type threadStore struct {
+	Repo     ThreadRepository // guarded by mu; swap with SetRepo once running
+	mu       sync.RWMutex
+	unsaved  map[string]bool         // contexts whose thread ID has not reached Repo
+	stats    map[string]*threadStats // per-context rotation counters; guarded by mu
+	rotating map[string]bool         // contexts with a rotation in flight; guarded by mu
+
+	statesMu sync.Mutex
+	states   map[string]*threadState // per-thread run serialization
 }
 
 func NewOpenAIService(config *config.OpenAIConfig, repo ThreadRepository) *OpenAIService {
@@ -51,26 +65,61 @@ func NewOpenAIService(config *config.OpenAIConfig, repo ThreadRepository) *OpenA
 		Config:       config,
 		Options:      opts,
 		ThreadIds:    make(map[string]string),
-		Repo:         repo,
+		Tools:        NewToolRegistry(),
+		threadStore:  &threadStore{Repo: repo},
+	}
+}
+
+// WithThreadPolicy returns a service that rotates threads by policy. It
+// shares oai's threads, their state and the repository; runs on oai keep
+// oai's policy.
+func (oai *OpenAIService) WithThreadPolicy(policy *config.ThreadsConfig) *OpenAIService {
+	next := oai.derive()
+	next.policy = policy
+	return next
+}
+
+// WithTools returns a service whose runs of the assistants in toolAssistants
+// get the function tools in tools, sharing oai's threads like
+// WithThreadPolicy.
+func (oai *OpenAIService) WithTools(tools *ToolRegistry, toolAssistants []string) *OpenAIService {
+	next := oai.derive()
+	next.Tools = tools
+	next.toolUsers = toolUserSet(toolAssistants)
+	return next
+}
+
+// derive copies oai's settings into a service sharing its thread state.
+func (oai *OpenAIService) derive() *OpenAIService {
+	oai.mu.RLock()
+	defer oai.mu.RUnlock()
+	return &OpenAIService{
+		ThreadClient: oai.ThreadClient,
+		ThreadIds:    oai.ThreadIds,
+		Config:       oai.Config,
+		Options:      oai.Options,
+		Tools:        oai.Tools,
+		policy:       oai.policy,
+		toolUsers:    oai.toolUsers,
+		threadStore:  oai.threadStore,
 	}
 }
 
 func (oai *OpenAIService) GetOrCreateThread(contextID string) (string, error) {
 	oai.mu.Lock()
 	defer oai.mu.Unlock()
+	return oai.getOrCreateThread(contextID)
+}
 
+// getOrCreateThread is GetOrCreateThread for callers holding oai.mu.
+func (oai *OpenAIService) getOrCreateThread(contextID string) (string, error) {
 	if threadId, exists := oai.ThreadIds[contextID]; exists {
 		return threadId, nil
 	}
 
-	if oai.Repo != nil {
-		threadId, err := oai.Repo.GetThreadID(context.Background(), contextID)
-		if err != nil {
-			fmt.Printf("GetOrCreateThread: db lookup failed for %s: %v\n", contextID, err)
-		} else if threadId != "" {
-			oai.ThreadIds[contextID] = threadId
-			return threadId, nil
-		}
+	if threadId := oai.loadThread(contextID); threadId != "" {
+		oai.ThreadIds[contextID] = threadId
+		return threadId, nil
 	}
 
 	threadId, err := oai.CreateThread()
@@ -79,17 +128,49 @@ func (oai *OpenAIService) GetOrCreateThread(contextID string) (string, error) {
 		return "", err
 	}
 
+	saved := false
 	if oai.Repo != nil {
 		if err := oai.Repo.SaveThreadID(context.Background(), contextID, threadId); err != nil {
 			fmt.Printf("GetOrCreateThread: db save failed for %s: %v\n", contextID, err)
+		} else {
+			saved = true
 		}
 	}
+	if !saved {
+		if oai.unsaved == nil {
+			oai.unsaved = make(map[string]bool)
+		}
+		oai.unsaved[contextID] = true
+	}
 
 	oai.ThreadIds[contextID] = threadId
+	oai.resetStats(contextID)
 
 	return threadId, nil
 }
 
+// SetRepo swaps in a thread repository, for a database that became available
+// after startup, and writes back the thread IDs created while it was
+// unavailable. Those threads carry the latest conversation, so they replace
+// any older thread stored for the same context. IDs that fail to save are
+// retried on the next SetRepo.
+func (oai *OpenAIService) SetRepo(repo ThreadRepository) {
+	oai.mu.Lock()
+	defer oai.mu.Unlock()
+	oai.Repo = repo
+	if repo == nil {
+		return
+	}
+
+	for contextID := range oai.unsaved {
+		if err := repo.SaveThreadID(context.Background(), contextID, oai.ThreadIds[contextID]); err != nil {
+			fmt.Printf("SetRepo: db save failed for %s: %v\n", contextID, err)
+			continue
+		}
+		delete(oai.unsaved, contextID)
+	}
+}
+
 func (oai *OpenAIService) GetThreadId(contextID string) string {
 	oai.mu.RLock()
 	defer oai.mu.RUnlock()
@@ -105,6 +186,15 @@ func (oai *OpenAIService) CreateThread() (string, error) {
 
 	return t.ID, nil
 }
+
+// DeleteThread deletes a thread from OpenAI.
+func (oai *OpenAIService) DeleteThread(ctx context.Context, threadId string) error {
+	if _, err := oai.ThreadClient.Delete(ctx, threadId, oai.Options...); err != nil {
+		return fmt.Errorf("failed to delete thread %s: %w", threadId, err)
+	}
+	return nil
+}
+
 func (oai *OpenAIService) CreateMessage(message string, threadId string) (openai.Message, error) {
 	msg, err := oai.ThreadClient.Messages.New(context.Background(), threadId, openai.BetaThreadMessageNewParams{
 		Role: "user",
@@ -119,55 +209,26 @@ func (oai *OpenAIService) CreateMessage(message string, threadId string) (openai
 	return *msg, nil
 }
 
-func (oai *OpenAIService) CreateRun(threadId string, assistantID string) (openai.Run, error) {
-	run, err := oai.ThreadClient.Runs.New(context.Background(), threadId, openai.BetaThreadRunNewParams{
+// runParams starts a run with the assistant's own tools, plus the registered
+// function tools for the assistants given them with WithTools.
+func (oai *OpenAIService) runParams(assistantID string) openai.BetaThreadRunNewParams {
+	params := openai.BetaThreadRunNewParams{
 		AssistantID: assistantID,
-	}, oai.Options...)
+	}
 
-	if err != nil {
-		return openai.Run{}, fmt.Errorf("failed to create run %v", err)
+	if oai.toolUsers[assistantID] && oai.Tools.Len() > 0 {
+		params.Tools = oai.assistantTools()
 	}
 
-	return *run, nil
+	return params
 }
 
-func (oai *OpenAIService) GetResponse(run openai.Run, messageId string) (string, error) {
-
-	ctx, cancel := context.WithTimeout(context.Background(), oai.Config.Timeout)
-
-	defer cancel()
-
-	done := false
-
-	for !done {
-		select {
-		case <-ctx.Done():
-			return "", fmt.Errorf("operation timed out after 60 seconds")
-		default:
-			resp, err := oai.ThreadClient.Runs.Get(ctx, run.ThreadID, run.ID, oai.Options...)
-
-			if err != nil {
-				return "", fmt.Errorf("failed to retrieve run info %v", err)
-			}
-
-			switch resp.Status {
-			case openai.RunStatusInProgress, openai.RunStatusQueued:
-				time.Sleep(5 * time.Second)
-				continue
-			case openai.RunStatusCompleted:
-				return oai.getCompletedResponse(ctx, run.ThreadID, messageId)
-			case openai.RunStatusFailed:
-				return "", fmt.Errorf("Run failed")
-			case openai.RunStatusCancelled:
-				return "", fmt.Errorf("run was cancelled")
-			case openai.RunStatusRequiresAction:
-				return "", fmt.Errorf("run requires action (tool calls not implemented)")
-			default:
-				return "", fmt.Errorf("failed to get run status with status %s", resp.Status)
-			}
-		}
+func toolUserSet(assistantIDs []string) map[string]bool {
+	users := make(map[string]bool, len(assistantIDs))
+	for _, id := range assistantIDs {
+		users[id] = true
 	}
-	return "", fmt.Errorf("something went wrong")
+	return users
 }
 
 func cleanResponse(s string) string {
@@ -178,47 +239,3 @@ func cleanResponse(s string) string {
 
 	return cleaned
 }
-
-func validateResponse(response *pagination.CursorPage[openai.Message]) bool {
-	if len(response.Data) == 0 {
-		return false
-	}
-
-	if len(response.Data[0].Content) == 0 {
-		return false
-	}
-
-	if response.Data[0].Content[0].Text.Value == "" {
-		return false
-	}
-
-	return true
-}
-
-func (oai *OpenAIService) getCompletedResponse(ctx context.Context, threadId string, messageId string) (string, error) {
-	messages, err := oai.ThreadClient.Messages.List(ctx, threadId, openai.BetaThreadMessageListParams{Before: param.NewOpt(messageId)}, oai.Options...)
-
-	if err != nil {
-		return "", fmt.Errorf("failed to list messages %v", err)
-	}
-
-	if !validateResponse(messages) {
-		return "", fmt.Errorf("failed to validate response")
-	}
-	assistantMessage, err := oai.GetAssitantMessage(messages.Data)
-
-	if err != nil {
-		return "", err
-	}
-
-	return cleanResponse(assistantMessage.Content[0].Text.Value), nil
-}
-
-func (oai *OpenAIService) GetAssitantMessage(messages []openai.Message) (openai.Message, error) {
-	for i := len(messages) - 1; i >= 0; i-- {
-		if messages[i].Role == "assistant" {
-			return messages[i], nil
-		}
-	}
-	return openai.Message{}, fmt.Errorf("no assistant message found")
-}
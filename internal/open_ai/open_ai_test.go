@@ -2,24 +2,20 @@ package open_ai
 
 import (
 	"crowfather/internal/config"
-	"fmt"
-	"net/http"
-	"net/http/httptest"
 	"sync"
 	"testing"
 	"time"
 
 	"github.com/openai/openai-go"
 	"github.com/openai/openai-go/option"
-	"github.com/stretchr/testify/assert"
-	"github.com/stretchr/testify/require"
 )
 
 // Bug 1: GetThreadId must hold RLock when reading ThreadIds.
 // Run with: go test -race ./internal/open_ai
 func TestGetThreadIdRace(t *testing.T) {
 	svc := &OpenAIService{
-		ThreadIds: map[string]string{"group1": "thread_abc"},
+		ThreadIds:   map[string]string{"group1": "thread_abc"},
+		threadStore: &threadStore{},
 	}
 
 	var wg sync.WaitGroup
@@ -39,16 +35,6 @@ func TestGetThreadIdRace(t *testing.T) {
 	wg.Wait()
 }
 
-// Bug 2 helpers.
-
-func runStatusServer(t *testing.T, status string) *httptest.Server {
-	t.Helper()
-	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/json")
-		fmt.Fprintf(w, `{"id":"run_1","object":"thread.run","created_at":0,"thread_id":"thread_1","assistant_id":"asst_1","status":%q,"model":"gpt-4o","tools":[],"metadata":{},"parallel_tool_calls":true,"response_format":"auto","tool_choice":"auto"}`, status)
-	}))
-}
-
 func newTestService(t *testing.T, baseURL string) *OpenAIService {
 	t.Helper()
 	opts := []option.RequestOption{
@@ -61,32 +47,6 @@ func newTestService(t *testing.T, baseURL string) *OpenAIService {
 		Config:       &config.OpenAIConfig{Timeout: 500 * time.Millisecond},
 		Options:      opts,
 		ThreadIds:    make(map[string]string),
-		Repo:         nil,
+		threadStore:  &threadStore{},
 	}
 }
-
-// Bug 2a: cancelled run must return an error immediately, not loop until timeout.
-func TestGetResponseCancelledReturnsError(t *testing.T) {
-	server := runStatusServer(t, "cancelled")
-	defer server.Close()
-
-	svc := newTestService(t, server.URL)
-	run := openai.Run{ID: "run_1", ThreadID: "thread_1"}
-
-	_, err := svc.GetResponse(run, "msg_1")
-	require.Error(t, err)
-	assert.Contains(t, err.Error(), "cancelled")
-}
-
-// Bug 2b: requires_action run must return an error immediately, not loop until timeout.
-func TestGetResponseRequiresActionReturnsError(t *testing.T) {
-	server := runStatusServer(t, "requires_action")
-	defer server.Close()
-
-	svc := newTestService(t, server.URL)
-	run := openai.Run{ID: "run_1", ThreadID: "thread_1"}
-
-	_, err := svc.GetResponse(run, "msg_1")
-	require.Error(t, err)
-	assert.Contains(t, err.Error(), "requires action")
-}
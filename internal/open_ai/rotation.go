@@ -0,0 +1,355 @@
+package open_ai
+
+import (
+	"context"
+	"crowfather/internal/config"
+	"crowfather/internal/records"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/openai/openai-go"
+	"github.com/openai/openai-go/packages/param"
+)
+
+// Reasons a thread is rotated, as stored in thread history.
+const (
+	RotateMaxMessages = "max_messages"
+	RotateMaxAge      = "max_age"
+	RotateManual      = "manual"
+)
+
+// summaryMessageLimit is how many of the old thread's most recent messages
+// are summarized into the new one.
+const summaryMessageLimit = 100
+
+// summaryPrefix introduces the carried-over summary in the new thread.
+const summaryPrefix = "Summary of the conversation so far, carried over from the previous thread:\n\n"
+
+// ErrThreadBusy is returned when a context's thread can't be rotated because
+// a run or another rotation is in progress on it.
+var ErrThreadBusy = errors.New("thread is busy")
+
+const summaryPrompt = `You summarize a GroupMe group chat with a fantasy football assistant so the conversation can continue in a new thread.
+Write at most 200 words. Keep who said what when it matters: names, ongoing bets and trades, grudges, running jokes and anything the assistant promised.
+Leave out greetings and small talk.`
+
+// ThreadRecord is one thread a context has used, defined in records so the
+// database package can store it without importing open_ai.
+type ThreadRecord = records.ThreadRecord
+
+// ThreadHistoryRepository is implemented by thread repositories that keep
+// every thread a context has used. Without one, rotation still happens but
+// message counts and thread ages start over on each restart.
+type ThreadHistoryRepository interface {
+	ThreadRepository
+	GetActiveThread(ctx context.Context, contextID string) (*ThreadRecord, error) // nil if none
+	CountMessage(ctx context.Context, contextID string) error
+	// RotateThread retires the active thread with reason and summary and
+	// makes threadID the active thread.
+	RotateThread(ctx context.Context, contextID, threadID, reason, summary string) error
+}
+
+// threadStats tracks the active thread of a context against the rotation policy.
+type threadStats struct {
+	createdAt time.Time
+	messages  int
+}
+
+// SetThreadPolicy sets when threads are rotated. A nil policy disables rotation.
+func (oai *OpenAIService) SetThreadPolicy(policy *config.ThreadsConfig) {
+	oai.mu.Lock()
+	defer oai.mu.Unlock()
+	oai.policy = policy
+}
+
+// ThreadForMessage returns the thread a new message for contextID belongs in
+// and counts the message against it. If the active thread has reached the
+// policy's message or age limit it is rotated first; a failed rotation is
+// logged and the old thread kept.
+func (oai *OpenAIService) ThreadForMessage(contextID string) (string, error) {
+	oai.mu.Lock()
+	threadId, err := oai.getOrCreateThread(contextID)
+	if err != nil {
+		oai.mu.Unlock()
+		return "", err
+	}
+	reason := oai.rotationDue(contextID)
+	oai.mu.Unlock()
+
+	if reason != "" && !oai.threadBusy(threadId) {
+		newID, err := oai.rotate(contextID, threadId, reason)
+		switch {
+		case errors.Is(err, ErrThreadBusy):
+		case err != nil:
+			fmt.Printf("ThreadForMessage: failed to rotate thread for %s: %v\n", contextID, err)
+		default:
+			threadId = newID
+		}
+	}
+
+	oai.mu.Lock()
+	defer oai.mu.Unlock()
+	oai.statsFor(contextID).messages++
+	if repo, ok := oai.Repo.(ThreadHistoryRepository); ok {
+		if err := repo.CountMessage(context.Background(), contextID); err != nil {
+			fmt.Printf("ThreadForMessage: db count failed for %s: %v\n", contextID, err)
+		}
+	}
+	return threadId, nil
+}
+
+// ResetThread replaces the context's thread with a new one carrying a summary
+// of the old, and returns the new thread ID. A context without a thread gets
+// a fresh one. Like automatic rotation it won't strand an active run or
+// buffered messages, and returns ErrThreadBusy instead.
+func (oai *OpenAIService) ResetThread(contextID string) (string, error) {
+	oai.mu.Lock()
+	threadId, exists := oai.ThreadIds[contextID]
+	if !exists {
+		threadId = oai.loadThread(contextID)
+	}
+	if threadId == "" {
+		defer oai.mu.Unlock()
+		return oai.getOrCreateThread(contextID)
+	}
+	oai.mu.Unlock()
+
+	if oai.threadBusy(threadId) {
+		return "", ErrThreadBusy
+	}
+	return oai.rotate(contextID, threadId, RotateManual)
+}
+
+// ThreadInfo describes a context's active thread and the limits it rotates at.
+type ThreadInfo struct {
+	ThreadID    string
+	Messages    int
+	CreatedAt   time.Time
+	MaxMessages int           // 0 if unlimited
+	MaxAge      time.Duration // 0 if unlimited
+}
+
+// ThreadInfo returns the context's active thread, or false if it has none.
+// Counts for a thread loaded without thread history start at this process.
+func (oai *OpenAIService) ThreadInfo(contextID string) (ThreadInfo, bool) {
+	oai.mu.Lock()
+	defer oai.mu.Unlock()
+
+	threadId, exists := oai.ThreadIds[contextID]
+	if !exists {
+		if threadId = oai.loadThread(contextID); threadId == "" {
+			return ThreadInfo{}, false
+		}
+		oai.ThreadIds[contextID] = threadId
+	}
+
+	st := oai.statsFor(contextID)
+	info := ThreadInfo{ThreadID: threadId, Messages: st.messages, CreatedAt: st.createdAt}
+	if oai.policy != nil {
+		info.MaxMessages, info.MaxAge = oai.policy.MaxMessages, oai.policy.MaxAge
+	}
+	return info, true
+}
+
+// rotationDue reports why the context's thread should be rotated, or "" if
+// it shouldn't. Callers must hold oai.mu.
+func (oai *OpenAIService) rotationDue(contextID string) string {
+	if oai.policy == nil {
+		return ""
+	}
+	st := oai.statsFor(contextID)
+	if oai.policy.MaxMessages > 0 && st.messages >= oai.policy.MaxMessages {
+		return RotateMaxMessages
+	}
+	if oai.policy.MaxAge > 0 && time.Since(st.createdAt) >= oai.policy.MaxAge {
+		return RotateMaxAge
+	}
+	return ""
+}
+
+// threadBusy reports whether a run is active or messages are buffered or
+// being posted on the thread. Rotating then would strand the buffered messages in the old thread.
+func (oai *OpenAIService) threadBusy(threadId string) bool {
+	ts := oai.threadState(threadId)
+	defer oai.releaseThreadState(threadId, ts)
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	return ts.running || ts.posting || len(ts.pending) > 0
+}
+
+// rotate starts a new thread for contextID seeded with a summary of oldID.
+// A summary that can't be produced is logged and the new thread starts
+// empty. The OpenAI calls are made without oai.mu so other chats aren't held
+// up; messages for contextID that arrive meanwhile still go to oldID. Only
+// one rotation per context runs at a time; others get ErrThreadBusy.
+// Callers must not hold oai.mu.
+func (oai *OpenAIService) rotate(contextID, oldID, reason string) (string, error) {
+	oai.mu.Lock()
+	if oai.rotating[contextID] {
+		oai.mu.Unlock()
+		return "", ErrThreadBusy
+	}
+	if oai.rotating == nil {
+		oai.rotating = make(map[string]bool)
+	}
+	oai.rotating[contextID] = true
+	var model string
+	if oai.policy != nil {
+		model = oai.policy.SummaryModel
+	}
+	oai.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), oai.Config.Timeout)
+	defer cancel()
+
+	summary, err := oai.summarizeThread(ctx, oldID, model)
+	if err != nil {
+		fmt.Printf("rotate: failed to summarize thread %s: %v\n", oldID, err)
+	}
+
+	threadId, err := oai.CreateThread()
+	if err != nil {
+		oai.mu.Lock()
+		delete(oai.rotating, contextID)
+		oai.mu.Unlock()
+		return "", err
+	}
+	if summary != "" {
+		if _, err := oai.CreateMessage(summaryPrefix+summary, threadId); err != nil {
+			fmt.Printf("rotate: failed to carry summary into thread %s: %v\n", threadId, err)
+		}
+	}
+
+	oai.mu.Lock()
+	defer oai.mu.Unlock()
+	delete(oai.rotating, contextID)
+
+	saved := false
+	switch repo := oai.Repo.(type) {
+	case nil:
+	case ThreadHistoryRepository:
+		if err := repo.RotateThread(ctx, contextID, threadId, reason, summary); err != nil {
+			fmt.Printf("rotate: db save failed for %s: %v\n", contextID, err)
+		} else {
+			saved = true
+		}
+	default:
+		if err := repo.SaveThreadID(ctx, contextID, threadId); err != nil {
+			fmt.Printf("rotate: db save failed for %s: %v\n", contextID, err)
+		} else {
+			saved = true
+		}
+	}
+	if !saved {
+		if oai.unsaved == nil {
+			oai.unsaved = make(map[string]bool)
+		}
+		oai.unsaved[contextID] = true
+	}
+
+	oai.ThreadIds[contextID] = threadId
+	oai.resetStats(contextID)
+	fmt.Printf("Rotated thread for %s (%s): %s -> %s\n", contextID, reason, oldID, threadId)
+	return threadId, nil
+}
+
+// summarizeThread summarizes the most recent messages of a thread with the
+// given chat model. A thread without text messages, or an empty model, gives
+// an empty summary.
+func (oai *OpenAIService) summarizeThread(ctx context.Context, threadId, model string) (string, error) {
+	if model == "" {
+		return "", nil
+	}
+
+	page, err := oai.ThreadClient.Messages.List(ctx, threadId, openai.BetaThreadMessageListParams{
+		Limit: param.NewOpt(int64(summaryMessageLimit)),
+		Order: openai.BetaThreadMessageListParamsOrderDesc,
+	}, oai.Options...)
+	if err != nil {
+		return "", fmt.Errorf("failed to list messages %v", err)
+	}
+
+	var transcript strings.Builder
+	for i := len(page.Data) - 1; i >= 0; i-- {
+		msg := page.Data[i]
+		for _, content := range msg.Content {
+			if content.Type == "text" && content.Text.Value != "" {
+				fmt.Fprintf(&transcript, "%s: %s\n", msg.Role, content.Text.Value)
+			}
+		}
+	}
+	if transcript.Len() == 0 {
+		return "", nil
+	}
+
+	client := openai.NewClient(oai.Options...)
+	resp, err := client.Chat.Completions.New(ctx, openai.ChatCompletionNewParams{
+		Model: openai.ChatModel(model),
+		Messages: []openai.ChatCompletionMessageParamUnion{
+			openai.SystemMessage(summaryPrompt),
+			openai.UserMessage(transcript.String()),
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to create summary %v", err)
+	}
+	if len(resp.Choices) == 0 {
+		return "", fmt.Errorf("summary response had no choices")
+	}
+	return strings.TrimSpace(resp.Choices[0].Message.Content), nil
+}
+
+// loadThread returns the context's active thread from Repo, or "" if there is
+// none or the lookup fails, and loads its rotation counters. Callers must
+// hold oai.mu.
+func (oai *OpenAIService) loadThread(contextID string) string {
+	switch repo := oai.Repo.(type) {
+	case nil:
+		return ""
+	case ThreadHistoryRepository:
+		record, err := repo.GetActiveThread(context.Background(), contextID)
+		if err != nil {
+			fmt.Printf("GetOrCreateThread: db lookup failed for %s: %v\n", contextID, err)
+			return ""
+		}
+		if record == nil {
+			return ""
+		}
+		oai.setStats(contextID, &threadStats{createdAt: record.CreatedAt, messages: record.MessageCount})
+		return record.ThreadID
+	default:
+		threadId, err := repo.GetThreadID(context.Background(), contextID)
+		if err != nil {
+			fmt.Printf("GetOrCreateThread: db lookup failed for %s: %v\n", contextID, err)
+			return ""
+		}
+		if threadId != "" {
+			// The thread's age and size aren't stored; count from now.
+			oai.resetStats(contextID)
+		}
+		return threadId
+	}
+}
+
+// resetStats starts the rotation counters over for a new thread.
+func (oai *OpenAIService) resetStats(contextID string) {
+	oai.setStats(contextID, &threadStats{createdAt: time.Now()})
+}
+
+// statsFor returns the context's rotation counters, starting them now for a
+// thread cached before they were tracked.
+func (oai *OpenAIService) statsFor(contextID string) *threadStats {
+	if oai.stats[contextID] == nil {
+		oai.resetStats(contextID)
+	}
+	return oai.stats[contextID]
+}
+
+func (oai *OpenAIService) setStats(contextID string, st *threadStats) {
+	if oai.stats == nil {
+		oai.stats = make(map[string]*threadStats)
+	}
+	oai.stats[contextID] = st
+}
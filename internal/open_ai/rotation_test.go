@@ -0,0 +1,267 @@
+package open_ai
+
+import (
+	"context"
+	"crowfather/internal/config"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rotationServer fakes the thread, message and chat completion endpoints used
+// by rotation, recording the messages posted and the transcripts summarized.
+type rotationServer struct {
+	*httptest.Server
+	mu          sync.Mutex
+	threads     int
+	posted      map[string][]string // thread ID → message bodies
+	transcripts []string
+	failSummary bool
+	summarizing chan struct{} // if set, signalled when a summary is requested
+	release     chan struct{} // if set, summaries wait until it is closed
+}
+
+func newRotationServer(t *testing.T) *rotationServer {
+	t.Helper()
+	s := &rotationServer{posted: make(map[string][]string)}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *rotationServer) handle(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/chat/completions" && s.release != nil {
+		s.summarizing <- struct{}{}
+		<-s.release
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+	body, _ := io.ReadAll(r.Body)
+
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == "/threads":
+		s.threads++
+		fmt.Fprintf(w, `{"id":"thread_%d","object":"thread","created_at":0,"metadata":{},"tool_resources":{}}`, s.threads)
+	case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/messages"):
+		// Newest first, as requested by summarizeThread.
+		fmt.Fprint(w, `{"object":"list","has_more":false,"data":[
+			{"id":"msg_2","object":"thread.message","role":"assistant","content":[{"type":"text","text":{"value":"Bet accepted.","annotations":[]}}]},
+			{"id":"msg_1","object":"thread.message","role":"user","content":[{"type":"text","text":{"value":"Loser buys wings.","annotations":[]}}]}
+		]}`)
+	case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/messages"):
+		threadID := strings.Split(r.URL.Path, "/")[2]
+		s.posted[threadID] = append(s.posted[threadID], string(body))
+		fmt.Fprint(w, `{"id":"msg_new","object":"thread.message","role":"user","content":[]}`)
+	case r.Method == http.MethodPost && r.URL.Path == "/chat/completions":
+		if s.failSummary {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"error":{"message":"bad model"}}`)
+			return
+		}
+		s.transcripts = append(s.transcripts, string(body))
+		fmt.Fprint(w, `{"id":"c","object":"chat.completion","created":0,"model":"gpt-4o-mini","choices":[
+			{"index":0,"finish_reason":"stop","message":{"role":"assistant","content":"Wings bet is on."}}
+		]}`)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+type mockHistoryRepo struct {
+	mockThreadRepo
+	active    map[string]*ThreadRecord
+	counts    map[string]int
+	rotations []ThreadRecord // retired records, with the new thread as ThreadID
+}
+
+func newMockHistoryRepo() *mockHistoryRepo {
+	return &mockHistoryRepo{
+		mockThreadRepo: mockThreadRepo{data: make(map[string]string)},
+		active:         make(map[string]*ThreadRecord),
+		counts:         make(map[string]int),
+	}
+}
+
+func (m *mockHistoryRepo) GetActiveThread(_ context.Context, contextID string) (*ThreadRecord, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.active[contextID], nil
+}
+
+func (m *mockHistoryRepo) CountMessage(_ context.Context, contextID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.counts[contextID]++
+	return nil
+}
+
+func (m *mockHistoryRepo) RotateThread(_ context.Context, contextID, threadID, reason, summary string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.rotations = append(m.rotations, ThreadRecord{ContextID: contextID, ThreadID: threadID, RetireReason: reason, Summary: summary})
+	m.active[contextID] = &ThreadRecord{ContextID: contextID, ThreadID: threadID, CreatedAt: time.Now()}
+	return nil
+}
+
+func newRotationService(t *testing.T, policy *config.ThreadsConfig) (*OpenAIService, *rotationServer) {
+	t.Helper()
+	server := newRotationServer(t)
+	svc := newTestService(t, server.URL)
+	svc.SetThreadPolicy(policy)
+	return svc, server
+}
+
+// The thread is rotated once it holds MaxMessages messages, and the new
+// thread starts with a summary of the old one.
+func TestThreadForMessage_RotatesAtMaxMessages(t *testing.T) {
+	svc, server := newRotationService(t, &config.ThreadsConfig{MaxMessages: 2, SummaryModel: "gpt-4o-mini"})
+	svc.ThreadIds["group1"] = "thread_old"
+
+	for i := 0; i < 2; i++ {
+		id, err := svc.ThreadForMessage("group1")
+		require.NoError(t, err)
+		assert.Equal(t, "thread_old", id)
+	}
+
+	id, err := svc.ThreadForMessage("group1")
+	require.NoError(t, err)
+	assert.Equal(t, "thread_1", id)
+	assert.Equal(t, "thread_1", svc.GetThreadId("group1"))
+
+	require.Len(t, server.transcripts, 1)
+	assert.Contains(t, server.transcripts[0], `user: Loser buys wings.\nassistant: Bet accepted.`)
+	require.Len(t, server.posted["thread_1"], 1)
+	assert.Contains(t, server.posted["thread_1"][0], "Wings bet is on.")
+
+	// The rotated-in message counts against the new thread.
+	assert.Equal(t, "", svc.rotationDue("group1"))
+	assert.Equal(t, 1, svc.stats["group1"].messages)
+}
+
+// A thread older than MaxAge, as recorded in thread history, is rotated on
+// the next message and the rotation is recorded with its summary.
+func TestThreadForMessage_RotatesAtMaxAge(t *testing.T) {
+	svc, _ := newRotationService(t, &config.ThreadsConfig{MaxAge: 24 * time.Hour, SummaryModel: "gpt-4o-mini"})
+	repo := newMockHistoryRepo()
+	repo.active["group1"] = &ThreadRecord{ContextID: "group1", ThreadID: "thread_old", MessageCount: 3, CreatedAt: time.Now().Add(-48 * time.Hour)}
+	svc.Repo = repo
+
+	id, err := svc.ThreadForMessage("group1")
+	require.NoError(t, err)
+	assert.Equal(t, "thread_1", id)
+
+	require.Len(t, repo.rotations, 1)
+	assert.Equal(t, ThreadRecord{ContextID: "group1", ThreadID: "thread_1", RetireReason: RotateMaxAge, Summary: "Wings bet is on."}, repo.rotations[0])
+	assert.Equal(t, 1, repo.counts["group1"])
+}
+
+func TestThreadForMessage_NoPolicyNeverRotates(t *testing.T) {
+	svc, server := newRotationService(t, nil)
+	svc.ThreadIds["group1"] = "thread_old"
+
+	for i := 0; i < 5; i++ {
+		id, err := svc.ThreadForMessage("group1")
+		require.NoError(t, err)
+		assert.Equal(t, "thread_old", id)
+	}
+	assert.Zero(t, server.threads)
+}
+
+// Rotation waits while a run is active so buffered messages aren't stranded
+// in the old thread.
+func TestThreadForMessage_SkipsBusyThread(t *testing.T) {
+	svc, _ := newRotationService(t, &config.ThreadsConfig{MaxMessages: 1, SummaryModel: "gpt-4o-mini"})
+	svc.ThreadIds["group1"] = "thread_old"
+	svc.stats = map[string]*threadStats{"group1": {createdAt: time.Now(), messages: 1}}
+	svc.threadState("thread_old").running = true
+
+	id, err := svc.ThreadForMessage("group1")
+	require.NoError(t, err)
+	assert.Equal(t, "thread_old", id)
+
+	svc.threadState("thread_old").running = false
+	id, err = svc.ThreadForMessage("group1")
+	require.NoError(t, err)
+	assert.Equal(t, "thread_1", id)
+}
+
+// A manual reset still rotates when the summary can't be produced; the new
+// thread just starts empty.
+func TestResetThread_SummaryFailure(t *testing.T) {
+	svc, server := newRotationService(t, &config.ThreadsConfig{SummaryModel: "gpt-4o-mini"})
+	server.failSummary = true
+	repo := newMockHistoryRepo()
+	repo.active["group1"] = &ThreadRecord{ContextID: "group1", ThreadID: "thread_old", CreatedAt: time.Now()}
+	svc.Repo = repo
+
+	id, err := svc.ResetThread("group1")
+	require.NoError(t, err)
+	assert.Equal(t, "thread_1", id)
+	assert.Empty(t, server.posted["thread_1"])
+
+	require.Len(t, repo.rotations, 1)
+	assert.Equal(t, RotateManual, repo.rotations[0].RetireReason)
+	assert.Empty(t, repo.rotations[0].Summary)
+}
+
+// A reset waits for the active run, like automatic rotation.
+func TestResetThread_RefusesBusyThread(t *testing.T) {
+	svc, server := newRotationService(t, &config.ThreadsConfig{SummaryModel: "gpt-4o-mini"})
+	svc.ThreadIds["group1"] = "thread_old"
+	svc.threadState("thread_old").pending = []string{"queued"}
+
+	_, err := svc.ResetThread("group1")
+	assert.ErrorIs(t, err, ErrThreadBusy)
+	assert.Equal(t, "thread_old", svc.GetThreadId("group1"))
+	assert.Zero(t, server.threads)
+}
+
+// Resetting a context without a thread just starts one.
+func TestResetThread_NoThread(t *testing.T) {
+	svc, server := newRotationService(t, &config.ThreadsConfig{SummaryModel: "gpt-4o-mini"})
+
+	id, err := svc.ResetThread("group1")
+	require.NoError(t, err)
+	assert.Equal(t, "thread_1", id)
+	assert.Empty(t, server.transcripts)
+}
+
+// While a rotation waits on OpenAI, other chats can still use the service
+// and a second rotation of the same chat is refused.
+func TestResetThread_RotatesWithoutHoldingLock(t *testing.T) {
+	svc, server := newRotationService(t, &config.ThreadsConfig{SummaryModel: "gpt-4o-mini"})
+	server.summarizing = make(chan struct{}, 1)
+	server.release = make(chan struct{})
+	svc.ThreadIds["group1"] = "thread_old"
+	svc.ThreadIds["group2"] = "thread_other"
+
+	done := make(chan string, 1)
+	go func() {
+		id, err := svc.ResetThread("group1")
+		assert.NoError(t, err)
+		done <- id
+	}()
+	<-server.summarizing
+
+	assert.Equal(t, "thread_other", svc.GetThreadId("group2"))
+	id, err := svc.ThreadForMessage("group2")
+	require.NoError(t, err)
+	assert.Equal(t, "thread_other", id)
+
+	_, err = svc.ResetThread("group1")
+	assert.ErrorIs(t, err, ErrThreadBusy)
+	assert.Equal(t, "thread_old", svc.GetThreadId("group1"))
+
+	close(server.release)
+	assert.Equal(t, "thread_1", <-done)
+	assert.Equal(t, "thread_1", svc.GetThreadId("group1"))
+}
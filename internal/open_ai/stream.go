@@ -0,0 +1,88 @@
+package open_ai
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/openai/openai-go"
+	"github.com/openai/openai-go/packages/ssestream"
+)
+
+// StreamRun starts a run on the thread using the Assistants streaming API and
+// returns the assistant's reply as soon as the run completes, without polling.
+// Tool calls requested mid-run are executed and their outputs streamed back.
+// The whole exchange is bounded by Config.Timeout and ends early if ctx is
+// cancelled.
+func (oai *OpenAIService) StreamRun(ctx context.Context, threadId string, assistantID string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, oai.Config.Timeout)
+	defer cancel()
+
+	stream := oai.ThreadClient.Runs.NewStreaming(ctx, threadId, oai.runParams(assistantID), oai.Options...)
+
+	for {
+		reply, pending, err := consumeRunStream(stream)
+		if err != nil {
+			return "", err
+		}
+
+		if pending == nil {
+			return cleanResponse(reply), nil
+		}
+
+		if oai.Tools.Len() == 0 {
+			return "", fmt.Errorf("run requires action but no tools are registered")
+		}
+
+		params, err := oai.runToolCalls(ctx, pending)
+		if err != nil {
+			return "", err
+		}
+
+		stream = oai.ThreadClient.Runs.SubmitToolOutputsStreaming(ctx, pending.ThreadID, pending.ID, params, oai.Options...)
+	}
+}
+
+// consumeRunStream reads run events until the run reaches a terminal state or
+// stops for tool calls. It returns the last completed assistant message, or
+// the run awaiting tool outputs when the stream paused at requires_action.
+func consumeRunStream(stream *ssestream.Stream[openai.AssistantStreamEventUnion]) (string, *openai.Run, error) {
+	defer stream.Close()
+
+	var reply string
+	for stream.Next() {
+		event := stream.Current()
+
+		switch event.Event {
+		case "thread.message.completed":
+			msg := event.AsThreadMessageCompleted().Data
+			if msg.Role == "assistant" && len(msg.Content) > 0 && msg.Content[0].Text.Value != "" {
+				reply = msg.Content[0].Text.Value
+			}
+		case "thread.run.requires_action":
+			run := event.AsThreadRunRequiresAction().Data
+			return "", &run, nil
+		case "thread.run.completed":
+			if reply == "" {
+				return "", nil, fmt.Errorf("failed to validate response")
+			}
+			return reply, nil, nil
+		case "thread.run.failed":
+			run := event.AsThreadRunFailed().Data
+			return "", nil, fmt.Errorf("Run failed: %s", run.LastError.Message)
+		case "thread.run.cancelled":
+			return "", nil, fmt.Errorf("run was cancelled")
+		case "thread.run.expired":
+			return "", nil, fmt.Errorf("run expired")
+		case "thread.run.incomplete":
+			return "", nil, fmt.Errorf("run ended incomplete")
+		case "error":
+			return "", nil, fmt.Errorf("run stream error: %s", event.AsErrorEvent().Data.Message)
+		}
+	}
+
+	if err := stream.Err(); err != nil {
+		return "", nil, fmt.Errorf("failed to stream run %v", err)
+	}
+
+	return "", nil, fmt.Errorf("run stream ended before the run completed")
+}
@@ -0,0 +1,134 @@
+package open_ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const assistantMessageJSON = `{"id":"msg_2","object":"thread.message","created_at":0,"thread_id":"thread_1","role":"assistant","content":[{"type":"text","text":{"value":"Alice won\n\nby 12.","annotations":[]}}],"attachments":[],"metadata":{},"status":"completed"}`
+
+func writeEvents(w http.ResponseWriter, events ...[2]string) {
+	w.Header().Set("Content-Type", "text/event-stream")
+	for _, e := range events {
+		fmt.Fprintf(w, "event: %s\ndata: %s\n\n", e[0], e[1])
+	}
+	fmt.Fprint(w, "event: done\ndata: [DONE]\n\n")
+}
+
+func TestStreamRun_ReturnsFinalMessage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/threads/thread_1/runs", r.URL.Path)
+		writeEvents(w,
+			[2]string{"thread.run.created", runJSON("queued", "")},
+			[2]string{"thread.message.completed", assistantMessageJSON},
+			[2]string{"thread.run.completed", runJSON("completed", "")},
+		)
+	}))
+	defer server.Close()
+
+	svc := newTestService(t, server.URL)
+	resp, err := svc.StreamRun(context.Background(), "thread_1", "asst_1")
+	require.NoError(t, err)
+	assert.Equal(t, "Alice won by 12.", resp)
+}
+
+func TestStreamRun_FailedRunReturnsError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeEvents(w, [2]string{"thread.run.failed", runJSON("failed", "")})
+	}))
+	defer server.Close()
+
+	svc := newTestService(t, server.URL)
+	_, err := svc.StreamRun(context.Background(), "thread_1", "asst_1")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed")
+}
+
+// A cancelled run returns an error as soon as the event arrives rather than
+// waiting out the timeout.
+func TestStreamRun_CancelledRunReturnsError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeEvents(w, [2]string{"thread.run.cancelled", runJSON("cancelled", "")})
+	}))
+	defer server.Close()
+
+	svc := newTestService(t, server.URL)
+	_, err := svc.StreamRun(context.Background(), "thread_1", "asst_1")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cancelled")
+}
+
+func TestStreamRun_EndsWithoutCompletion(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeEvents(w, [2]string{"thread.run.created", runJSON("queued", "")})
+	}))
+	defer server.Close()
+
+	svc := newTestService(t, server.URL)
+	_, err := svc.StreamRun(context.Background(), "thread_1", "asst_1")
+	assert.Error(t, err)
+}
+
+func TestStreamRun_SubmitsToolOutputs(t *testing.T) {
+	var submitted atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/submit_tool_outputs") {
+			var req struct {
+				Stream      bool                `json:"stream"`
+				ToolOutputs []map[string]string `json:"tool_outputs"`
+			}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			assert.True(t, req.Stream)
+			require.Len(t, req.ToolOutputs, 1)
+			assert.Equal(t, "call_1", req.ToolOutputs[0]["tool_call_id"])
+			submitted.Store(true)
+			writeEvents(w,
+				[2]string{"thread.message.completed", assistantMessageJSON},
+				[2]string{"thread.run.completed", runJSON("completed", "")},
+			)
+			return
+		}
+		writeEvents(w, [2]string{"thread.run.requires_action", runJSON("requires_action",
+			`{"type":"submit_tool_outputs","submit_tool_outputs":{"tool_calls":[{"id":"call_1","type":"function","function":{"name":"echo","arguments":"{}"}}]}}`)})
+	}))
+	defer server.Close()
+
+	svc := newTestService(t, server.URL)
+	svc.Tools = NewToolRegistry()
+	tool := echoTool("echo")
+	var caller string
+	echo := tool.Handler
+	tool.Handler = func(ctx context.Context, args json.RawMessage) (string, error) {
+		caller = AssistantFromContext(ctx)
+		return echo(ctx, args)
+	}
+	require.NoError(t, svc.Tools.Register(tool))
+
+	resp, err := svc.StreamRun(context.Background(), "thread_1", "asst_1")
+	require.NoError(t, err)
+	assert.Equal(t, "Alice won by 12.", resp)
+	assert.True(t, submitted.Load())
+	assert.Equal(t, "asst_1", caller, "tools see the assistant whose run called them")
+}
+
+func TestStreamRun_RequiresActionWithoutTools(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeEvents(w, [2]string{"thread.run.requires_action", runJSON("requires_action",
+			`{"type":"submit_tool_outputs","submit_tool_outputs":{"tool_calls":[]}}`)})
+	}))
+	defer server.Close()
+
+	svc := newTestService(t, server.URL)
+	_, err := svc.StreamRun(context.Background(), "thread_1", "asst_1")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "requires action")
+}
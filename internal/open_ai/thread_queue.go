@@ -0,0 +1,151 @@
+package open_ai
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// threadState serializes runs on one OpenAI thread. OpenAI rejects new
+// messages while a run is active, so messages that arrive mid-run are
+// buffered in pending and posted once the run finishes. ts.mu is released
+// while messages are posted; posting keeps other callers behind them.
+type threadState struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	running bool
+	posting bool
+	pending []string
+
+	refs int // callers using the state; guarded by OpenAIService.statesMu
+}
+
+// threadState returns the thread's state, creating it if needed. Each call
+// must be paired with releaseThreadState.
+func (oai *OpenAIService) threadState(threadId string) *threadState {
+	oai.statesMu.Lock()
+	defer oai.statesMu.Unlock()
+
+	if oai.states == nil {
+		oai.states = make(map[string]*threadState)
+	}
+
+	ts, ok := oai.states[threadId]
+	if !ok {
+		ts = &threadState{}
+		ts.cond = sync.NewCond(&ts.mu)
+		oai.states[threadId] = ts
+	}
+	ts.refs++
+	return ts
+}
+
+// releaseThreadState drops a reference taken by threadState and forgets the
+// state once nobody uses it and it has nothing in flight, so threads that
+// were rotated away don't accumulate. Callers must not hold ts.mu.
+func (oai *OpenAIService) releaseThreadState(threadId string, ts *threadState) {
+	oai.statesMu.Lock()
+	defer oai.statesMu.Unlock()
+
+	ts.refs--
+	if ts.refs > 0 {
+		return
+	}
+	ts.mu.Lock()
+	idle := !ts.running && !ts.posting && len(ts.pending) == 0
+	ts.mu.Unlock()
+	if idle {
+		delete(oai.states, threadId)
+	}
+}
+
+// QueueMessage adds a user message to the thread. If a run is in progress the
+// message is buffered and posted as soon as the run finishes. If older
+// buffered messages can't be posted first, the message is not buffered and
+// the error is returned so the caller can retry it.
+func (oai *OpenAIService) QueueMessage(threadId string, message string) error {
+	ts := oai.threadState(threadId)
+	defer oai.releaseThreadState(threadId, ts)
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	if ts.running || ts.posting {
+		ts.pending = append(ts.pending, message)
+		return nil
+	}
+
+	// Keep ordering: older buffered messages go first.
+	if err := oai.flushPending(threadId, ts); err != nil {
+		return err
+	}
+	if err := oai.post(threadId, ts, message); err != nil {
+		return err
+	}
+
+	// Messages buffered while this one was posted follow it. They are the
+	// other callers' messages, so a failure leaves them for the next flush.
+	if err := oai.flushPending(threadId, ts); err != nil {
+		fmt.Printf("QueueMessage: %v\n", err)
+	}
+	return nil
+}
+
+// RunThread waits for any active run on the thread to finish, posts buffered
+// messages, then streams a new run and returns the assistant's reply. A run
+// is not started once ctx is done.
+func (oai *OpenAIService) RunThread(ctx context.Context, threadId string, assistantID string) (string, error) {
+	ts := oai.threadState(threadId)
+	defer oai.releaseThreadState(threadId, ts)
+
+	ts.mu.Lock()
+	for ts.running || ts.posting {
+		ts.cond.Wait()
+	}
+	if err := ctx.Err(); err != nil {
+		ts.mu.Unlock()
+		return "", err
+	}
+	if err := oai.flushPending(threadId, ts); err != nil {
+		ts.mu.Unlock()
+		return "", err
+	}
+	ts.running = true
+	ts.mu.Unlock()
+
+	resp, err := oai.StreamRun(ctx, threadId, assistantID)
+
+	ts.mu.Lock()
+	ts.running = false
+	if flushErr := oai.flushPending(threadId, ts); flushErr != nil {
+		fmt.Printf("RunThread: %v\n", flushErr)
+	}
+	ts.cond.Broadcast()
+	ts.mu.Unlock()
+
+	return resp, err
+}
+
+// flushPending posts buffered messages in arrival order. Messages that fail to
+// post stay buffered for the next flush. Callers must hold ts.mu.
+func (oai *OpenAIService) flushPending(threadId string, ts *threadState) error {
+	for len(ts.pending) > 0 {
+		if err := oai.post(threadId, ts, ts.pending[0]); err != nil {
+			return fmt.Errorf("failed to flush %d buffered message(s) to thread %s: %v", len(ts.pending), threadId, err)
+		}
+		ts.pending = ts.pending[1:]
+	}
+	return nil
+}
+
+// post creates one message with ts.mu released, so buffering other messages
+// and checking the thread don't wait on OpenAI. Callers must hold ts.mu and
+// must not be posting already.
+func (oai *OpenAIService) post(threadId string, ts *threadState, message string) error {
+	ts.posting = true
+	ts.mu.Unlock()
+	_, err := oai.CreateMessage(message, threadId)
+	ts.mu.Lock()
+	ts.posting = false
+	ts.cond.Broadcast()
+	return err
+}
@@ -0,0 +1,240 @@
+package open_ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// runGateServer holds each streamed run open until release is closed, and
+// records the order of message posts and run starts on the thread.
+type runGateServer struct {
+	mu         sync.Mutex
+	events     []string
+	activeRuns int
+	peakRuns   int
+	runStarted chan struct{}
+	release    chan struct{}
+}
+
+func newRunGateServer() *runGateServer {
+	return &runGateServer{runStarted: make(chan struct{}, 10), release: make(chan struct{})}
+}
+
+func (s *runGateServer) handler(t *testing.T) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/messages"):
+			var body struct {
+				Content string `json:"content"`
+			}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			s.mu.Lock()
+			if s.activeRuns > 0 {
+				s.mu.Unlock()
+				w.WriteHeader(http.StatusBadRequest)
+				fmt.Fprint(w, `{"error":{"message":"thread has an active run"}}`)
+				return
+			}
+			s.events = append(s.events, "msg:"+body.Content)
+			s.mu.Unlock()
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, `{"id":"msg_1","object":"thread.message","created_at":0,"thread_id":"thread_1","role":"user","content":[],"attachments":[],"metadata":{},"status":"completed"}`)
+		case strings.HasSuffix(r.URL.Path, "/runs"):
+			s.mu.Lock()
+			s.activeRuns++
+			if s.activeRuns > s.peakRuns {
+				s.peakRuns = s.activeRuns
+			}
+			s.events = append(s.events, "run")
+			s.mu.Unlock()
+			s.runStarted <- struct{}{}
+			<-s.release
+			s.mu.Lock()
+			s.activeRuns--
+			s.mu.Unlock()
+			writeEvents(w,
+				[2]string{"thread.message.completed", assistantMessageJSON},
+				[2]string{"thread.run.completed", runJSON("completed", "")},
+			)
+		}
+	}
+}
+
+func TestQueueMessage_BuffersDuringRun(t *testing.T) {
+	gate := newRunGateServer()
+	server := httptest.NewServer(gate.handler(t))
+	defer server.Close()
+	svc := newTestService(t, server.URL)
+	svc.Config.Timeout = 5 * time.Second
+
+	require.NoError(t, svc.QueueMessage("thread_1", "first"))
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := svc.RunThread(context.Background(), "thread_1", "asst_1")
+		done <- err
+	}()
+	<-gate.runStarted
+
+	// Posting now would be rejected by OpenAI; it must be buffered instead.
+	require.NoError(t, svc.QueueMessage("thread_1", "second"))
+
+	close(gate.release)
+	require.NoError(t, <-done)
+
+	gate.mu.Lock()
+	defer gate.mu.Unlock()
+	assert.Equal(t, []string{"msg:first", "run", "msg:second"}, gate.events)
+}
+
+func TestRunThread_SkipsRunOnceContextIsDone(t *testing.T) {
+	gate := newRunGateServer()
+	server := httptest.NewServer(gate.handler(t))
+	defer server.Close()
+	svc := newTestService(t, server.URL)
+	svc.Config.Timeout = 5 * time.Second
+
+	first := make(chan error, 1)
+	go func() {
+		_, err := svc.RunThread(context.Background(), "thread_1", "asst_1")
+		first <- err
+	}()
+	<-gate.runStarted
+
+	// The second caller gives up while waiting behind the first run.
+	ctx, cancel := context.WithCancel(context.Background())
+	second := make(chan error, 1)
+	go func() {
+		_, err := svc.RunThread(ctx, "thread_1", "asst_1")
+		second <- err
+	}()
+	cancel()
+
+	close(gate.release)
+	require.NoError(t, <-first)
+	assert.ErrorIs(t, <-second, context.Canceled)
+
+	gate.mu.Lock()
+	defer gate.mu.Unlock()
+	assert.Equal(t, []string{"run"}, gate.events)
+}
+
+func TestRunThread_SerializesRuns(t *testing.T) {
+	gate := newRunGateServer()
+	server := httptest.NewServer(gate.handler(t))
+	defer server.Close()
+	svc := newTestService(t, server.URL)
+	svc.Config.Timeout = 5 * time.Second
+
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := svc.RunThread(context.Background(), "thread_1", "asst_1")
+			assert.NoError(t, err)
+		}()
+	}
+
+	for i := 0; i < 3; i++ {
+		<-gate.runStarted
+		if i == 0 {
+			close(gate.release)
+		}
+	}
+	wg.Wait()
+
+	gate.mu.Lock()
+	defer gate.mu.Unlock()
+	assert.Equal(t, 1, gate.peakRuns, "only one run may be active on a thread at a time")
+}
+
+// messageGateServer holds each message post until release is closed, or
+// fails it while fail is set, and records the messages it accepted.
+type messageGateServer struct {
+	mu       sync.Mutex
+	accepted []string
+	fail     bool
+	posting  chan struct{}
+	release  chan struct{}
+}
+
+func (s *messageGateServer) handler(t *testing.T) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Content string `json:"content"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		if s.posting != nil {
+			s.posting <- struct{}{}
+			<-s.release
+		}
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if s.fail {
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprint(w, `{"error":{"message":"unavailable"}}`)
+			return
+		}
+		s.accepted = append(s.accepted, body.Content)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"id":"msg_1","object":"thread.message","created_at":0,"thread_id":"thread_1","role":"user","content":[],"attachments":[],"metadata":{},"status":"completed"}`)
+	}
+}
+
+// A message that arrives while another is being posted is buffered behind it
+// instead of waiting on OpenAI, and the thread's state is dropped once idle.
+func TestQueueMessage_BuffersWhilePosting(t *testing.T) {
+	gate := &messageGateServer{posting: make(chan struct{}, 2), release: make(chan struct{})}
+	server := httptest.NewServer(gate.handler(t))
+	defer server.Close()
+	svc := newTestService(t, server.URL)
+
+	done := make(chan error, 1)
+	go func() { done <- svc.QueueMessage("thread_1", "first") }()
+	<-gate.posting
+
+	require.NoError(t, svc.QueueMessage("thread_1", "second"))
+	assert.True(t, svc.threadBusy("thread_1"))
+
+	close(gate.release)
+	require.NoError(t, <-done)
+
+	gate.mu.Lock()
+	assert.Equal(t, []string{"first", "second"}, gate.accepted)
+	gate.mu.Unlock()
+	svc.statesMu.Lock()
+	assert.Empty(t, svc.states)
+	svc.statesMu.Unlock()
+}
+
+// If older buffered messages can't be posted, the new message is refused
+// rather than buffered, so the caller's retry doesn't post it twice.
+func TestQueueMessage_ReturnsFlushError(t *testing.T) {
+	gate := &messageGateServer{fail: true}
+	server := httptest.NewServer(gate.handler(t))
+	defer server.Close()
+	svc := newTestService(t, server.URL)
+
+	ts := svc.threadState("thread_1")
+	ts.pending = []string{"older"}
+
+	assert.ErrorContains(t, svc.QueueMessage("thread_1", "newer"), "failed to flush 1 buffered message(s)")
+	assert.Equal(t, []string{"older"}, ts.pending)
+
+	gate.mu.Lock()
+	gate.fail = false
+	gate.mu.Unlock()
+	require.NoError(t, svc.QueueMessage("thread_1", "newer"))
+	assert.Equal(t, []string{"older", "newer"}, gate.accepted)
+}
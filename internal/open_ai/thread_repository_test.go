@@ -55,8 +55,8 @@ func newThreadServer(t *testing.T) *httptest.Server {
 func TestGetOrCreateThread_CacheHit(t *testing.T) {
 	repo := &mockThreadRepo{data: make(map[string]string)}
 	svc := &OpenAIService{
-		ThreadIds: map[string]string{"group1": "thread_cached"},
-		Repo:      repo,
+		ThreadIds:   map[string]string{"group1": "thread_cached"},
+		threadStore: &threadStore{Repo: repo},
 	}
 
 	id, err := svc.GetOrCreateThread("group1")
@@ -70,8 +70,8 @@ func TestGetOrCreateThread_CacheHit(t *testing.T) {
 func TestGetOrCreateThread_DBHit(t *testing.T) {
 	repo := &mockThreadRepo{data: map[string]string{"group1": "thread_from_db"}}
 	svc := &OpenAIService{
-		ThreadIds: make(map[string]string),
-		Repo:      repo,
+		ThreadIds:   make(map[string]string),
+		threadStore: &threadStore{Repo: repo},
 	}
 
 	id, err := svc.GetOrCreateThread("group1")
@@ -143,3 +143,27 @@ func TestGetOrCreateThread_Race(t *testing.T) {
 	}
 	wg.Wait()
 }
+
+// SetRepo writes back threads created while the DB was unavailable, keeping
+// failed saves for the next attempt.
+func TestSetRepo_WritesBackUnsavedThreads(t *testing.T) {
+	server := newThreadServer(t)
+	defer server.Close()
+
+	svc := newTestService(t, server.URL)
+	svc.Repo = nil
+	id, err := svc.GetOrCreateThread("group_offline")
+	require.NoError(t, err)
+
+	failing := &mockThreadRepo{data: make(map[string]string), saveErr: errors.New("still down")}
+	svc.SetRepo(failing)
+	assert.Equal(t, 1, failing.saveCalls)
+
+	repo := &mockThreadRepo{data: make(map[string]string)}
+	svc.SetRepo(repo)
+	assert.Equal(t, id, repo.data["group_offline"])
+
+	// Written back once only.
+	svc.SetRepo(repo)
+	assert.Equal(t, 1, repo.saveCalls)
+}
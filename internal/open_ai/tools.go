@@ -0,0 +1,165 @@
+package open_ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/openai/openai-go"
+	"github.com/openai/openai-go/packages/param"
+)
+
+// ToolHandler executes a function tool call. arguments is the raw JSON object
+// the model supplied; the returned string is submitted back to the run as the
+// tool output.
+type ToolHandler func(ctx context.Context, arguments json.RawMessage) (string, error)
+
+// Tool is a Go function exposed to assistants as an OpenAI function tool.
+type Tool struct {
+	Name        string
+	Description string
+	Parameters  map[string]interface{} // JSON Schema object; nil means no parameters
+	Handler     ToolHandler
+}
+
+// ToolRegistry holds the function tools available to assistant runs.
+// It is safe for concurrent use.
+type ToolRegistry struct {
+	mu    sync.RWMutex
+	tools map[string]Tool
+}
+
+func NewToolRegistry() *ToolRegistry {
+	return &ToolRegistry{tools: make(map[string]Tool)}
+}
+
+// Register adds a tool to the registry, replacing any tool with the same name.
+func (tr *ToolRegistry) Register(tool Tool) error {
+	if tool.Name == "" {
+		return fmt.Errorf("tool name must not be empty")
+	}
+	if tool.Handler == nil {
+		return fmt.Errorf("tool %s has no handler", tool.Name)
+	}
+
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	tr.tools[tool.Name] = tool
+	return nil
+}
+
+// Len returns the number of registered tools.
+func (tr *ToolRegistry) Len() int {
+	if tr == nil {
+		return 0
+	}
+	tr.mu.RLock()
+	defer tr.mu.RUnlock()
+	return len(tr.tools)
+}
+
+// Definitions returns the OpenAI function tool definitions for every
+// registered tool, sorted by name so assistant updates are deterministic.
+func (tr *ToolRegistry) Definitions() []openai.AssistantToolUnionParam {
+	if tr == nil {
+		return nil
+	}
+	tr.mu.RLock()
+	defer tr.mu.RUnlock()
+
+	names := make([]string, 0, len(tr.tools))
+	for name := range tr.tools {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	defs := make([]openai.AssistantToolUnionParam, 0, len(names))
+	for _, name := range names {
+		tool := tr.tools[name]
+		fn := openai.FunctionDefinitionParam{Name: tool.Name}
+		if tool.Description != "" {
+			fn.Description = param.NewOpt(tool.Description)
+		}
+		if tool.Parameters != nil {
+			fn.Parameters = openai.FunctionParameters(tool.Parameters)
+		}
+		defs = append(defs, openai.AssistantToolUnionParam{
+			OfFunction: &openai.FunctionToolParam{Function: fn},
+		})
+	}
+	return defs
+}
+
+// Call runs the named tool. Unknown tools and handler failures are returned
+// as errors; callers decide how to surface them to the model.
+func (tr *ToolRegistry) Call(ctx context.Context, name string, arguments string) (string, error) {
+	tr.mu.RLock()
+	tool, ok := tr.tools[name]
+	tr.mu.RUnlock()
+	if !ok {
+		return "", fmt.Errorf("unknown tool %s", name)
+	}
+
+	args := json.RawMessage(arguments)
+	if len(args) == 0 {
+		args = json.RawMessage("{}")
+	}
+	return tool.Handler(ctx, args)
+}
+
+type assistantKey struct{}
+
+// WithAssistant records the assistant whose run is calling a tool, so handlers
+// can limit what they return to that assistant's data.
+func WithAssistant(ctx context.Context, assistantID string) context.Context {
+	return context.WithValue(ctx, assistantKey{}, assistantID)
+}
+
+// AssistantFromContext returns the assistant recorded by WithAssistant, or ""
+// outside a run.
+func AssistantFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(assistantKey{}).(string)
+	return id
+}
+
+// assistantTools returns the tool list sent with runs and assistant updates:
+// file_search plus every registered function tool.
+func (oai *OpenAIService) assistantTools() []openai.AssistantToolUnionParam {
+	tools := []openai.AssistantToolUnionParam{
+		{OfFileSearch: &openai.FileSearchToolParam{}},
+	}
+	return append(tools, oai.Tools.Definitions()...)
+}
+
+// runToolCalls executes every function call requested by the run, with the
+// run's assistant recorded in ctx. A failing tool does not abort the run; its
+// error is reported to the model as the tool output.
+func (oai *OpenAIService) runToolCalls(ctx context.Context, run *openai.Run) (openai.BetaThreadRunSubmitToolOutputsParams, error) {
+	calls := run.RequiredAction.SubmitToolOutputs.ToolCalls
+	if len(calls) == 0 {
+		return openai.BetaThreadRunSubmitToolOutputsParams{}, fmt.Errorf("run requires action but requested no tool calls")
+	}
+	ctx = WithAssistant(ctx, run.AssistantID)
+
+	outputs := make([]openai.BetaThreadRunSubmitToolOutputsParamsToolOutput, 0, len(calls))
+	for _, call := range calls {
+		output, err := oai.Tools.Call(ctx, call.Function.Name, call.Function.Arguments)
+		if err != nil {
+			fmt.Printf("runToolCalls: tool %s failed: %v\n", call.Function.Name, err)
+			output = toolErrorOutput(err)
+		}
+		outputs = append(outputs, openai.BetaThreadRunSubmitToolOutputsParamsToolOutput{
+			ToolCallID: param.NewOpt(call.ID),
+			Output:     param.NewOpt(output),
+		})
+	}
+
+	return openai.BetaThreadRunSubmitToolOutputsParams{ToolOutputs: outputs}, nil
+}
+
+func toolErrorOutput(err error) string {
+	b, _ := json.Marshal(map[string]string{"error": err.Error()})
+	return string(b)
+}
@@ -0,0 +1,158 @@
+package open_ai
+
+import (
+	"context"
+	"crowfather/internal/config"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func echoTool(name string) Tool {
+	return Tool{
+		Name:        name,
+		Description: "echoes its arguments",
+		Parameters: map[string]interface{}{
+			"type":       "object",
+			"properties": map[string]interface{}{"q": map[string]interface{}{"type": "string"}},
+		},
+		Handler: func(_ context.Context, args json.RawMessage) (string, error) {
+			return string(args), nil
+		},
+	}
+}
+
+func TestToolRegistry_RegisterValidates(t *testing.T) {
+	tr := NewToolRegistry()
+	assert.Error(t, tr.Register(Tool{Handler: echoTool("x").Handler}))
+	assert.Error(t, tr.Register(Tool{Name: "no_handler"}))
+	require.NoError(t, tr.Register(echoTool("echo")))
+	assert.Equal(t, 1, tr.Len())
+}
+
+func TestToolRegistry_Call(t *testing.T) {
+	tr := NewToolRegistry()
+	require.NoError(t, tr.Register(echoTool("echo")))
+
+	out, err := tr.Call(context.Background(), "echo", `{"q":"bijan"}`)
+	require.NoError(t, err)
+	assert.Equal(t, `{"q":"bijan"}`, out)
+
+	out, err = tr.Call(context.Background(), "echo", "")
+	require.NoError(t, err)
+	assert.Equal(t, "{}", out)
+
+	_, err = tr.Call(context.Background(), "missing", "{}")
+	assert.Error(t, err)
+}
+
+func TestToolRegistry_DefinitionsSorted(t *testing.T) {
+	tr := NewToolRegistry()
+	require.NoError(t, tr.Register(echoTool("zeta")))
+	require.NoError(t, tr.Register(echoTool("alpha")))
+
+	defs := tr.Definitions()
+	require.Len(t, defs, 2)
+	assert.Equal(t, "alpha", defs[0].OfFunction.Function.Name)
+	assert.Equal(t, "zeta", defs[1].OfFunction.Function.Name)
+}
+
+func TestToolRegistry_NilIsEmpty(t *testing.T) {
+	var tr *ToolRegistry
+	assert.Equal(t, 0, tr.Len())
+	assert.Nil(t, tr.Definitions())
+}
+
+// Only the assistants given the tools have their tools replaced on a run.
+func TestRunParams_ToolsOnlyForToolAssistants(t *testing.T) {
+	tools := NewToolRegistry()
+	require.NoError(t, tools.Register(echoTool("echo")))
+	svc := NewOpenAIService(&config.OpenAIConfig{}, nil).WithTools(tools, []string{"asst_league"})
+
+	assert.Len(t, svc.runParams("asst_league").Tools, 2, "file_search and the echo function")
+	assert.Nil(t, svc.runParams("asst_meltdown").Tools)
+}
+
+// A service derived for a reload shares threads with the original, which
+// keeps its own tools and policy.
+func TestWithTools_SharesThreadsOnly(t *testing.T) {
+	svc := NewOpenAIService(&config.OpenAIConfig{}, nil)
+	svc.ThreadIds["g1"] = "thread_1"
+
+	tools := NewToolRegistry()
+	require.NoError(t, tools.Register(echoTool("echo")))
+	next := svc.WithThreadPolicy(&config.ThreadsConfig{MaxMessages: 5}).WithTools(tools, []string{"asst_league"})
+
+	assert.Equal(t, "thread_1", next.GetThreadId("g1"))
+	next.ThreadIds["g2"] = "thread_2"
+	assert.Equal(t, "thread_2", svc.GetThreadId("g2"))
+
+	assert.Len(t, next.runParams("asst_league").Tools, 2)
+	assert.Nil(t, svc.runParams("asst_league").Tools)
+	info, _ := next.ThreadInfo("g1")
+	assert.Equal(t, 5, info.MaxMessages)
+	info, _ = svc.ThreadInfo("g1")
+	assert.Zero(t, info.MaxMessages)
+}
+
+func runJSON(status, requiredAction string) string {
+	if requiredAction == "" {
+		requiredAction = "null"
+	}
+	return fmt.Sprintf(`{"id":"run_1","object":"thread.run","created_at":0,"thread_id":"thread_1","assistant_id":"asst_1","status":%q,"required_action":%s,"model":"gpt-4o","tools":[],"metadata":{},"parallel_tool_calls":true,"response_format":"auto","tool_choice":"auto"}`, status, requiredAction)
+}
+
+// Every requested tool runs; a failing one reports its error to the model
+// as its output instead of failing the run.
+func TestStreamRun_ToolErrorsBecomeOutputs(t *testing.T) {
+	var mu sync.Mutex
+	var outputs []map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/submit_tool_outputs") {
+			var req struct {
+				ToolOutputs []map[string]string `json:"tool_outputs"`
+			}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			mu.Lock()
+			outputs = req.ToolOutputs
+			mu.Unlock()
+			writeEvents(w,
+				[2]string{"thread.message.completed", assistantMessageJSON},
+				[2]string{"thread.run.completed", runJSON("completed", "")},
+			)
+			return
+		}
+		writeEvents(w, [2]string{"thread.run.requires_action", runJSON("requires_action", `{"type":"submit_tool_outputs","submit_tool_outputs":{"tool_calls":[{"id":"call_1","type":"function","function":{"name":"echo","arguments":"{\"q\":\"bijan\"}"}},{"id":"call_2","type":"function","function":{"name":"broken","arguments":"{}"}}]}}`)})
+	}))
+	defer server.Close()
+
+	svc := newTestService(t, server.URL)
+	svc.Tools = NewToolRegistry()
+	require.NoError(t, svc.Tools.Register(echoTool("echo")))
+	require.NoError(t, svc.Tools.Register(Tool{
+		Name: "broken",
+		Handler: func(context.Context, json.RawMessage) (string, error) {
+			return "", errors.New("sleeper down")
+		},
+	}))
+
+	resp, err := svc.StreamRun(context.Background(), "thread_1", "asst_1")
+	require.NoError(t, err)
+	assert.Equal(t, "Alice won by 12.", resp)
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, outputs, 2)
+	assert.Equal(t, "call_1", outputs[0]["tool_call_id"])
+	assert.Equal(t, `{"q":"bijan"}`, outputs[0]["output"])
+	assert.Equal(t, "call_2", outputs[1]["tool_call_id"])
+	assert.Contains(t, outputs[1]["output"], "sleeper down")
+}
@@ -4,10 +4,21 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/openai/openai-go"
 )
 
+// VectorStoreInfo summarizes a vector store in the account.
+type VectorStoreInfo struct {
+	ID         string
+	Name       string
+	CreatedAt  time.Time
+	Files      int64
+	UsageBytes int64
+}
+
 // CreateVectorStore creates a new OpenAI vector store and returns its ID.
 func (oai *OpenAIService) CreateVectorStore(ctx context.Context, name string) (string, error) {
 	client := openai.NewClient(oai.Options...)
@@ -30,46 +41,115 @@ type namedReader struct {
 
 func (n namedReader) Name() string { return n.name }
 
-// UploadFilesToVectorStore uploads the provided documents to a vector store in a
-// single batch and polls until all files are processed.
-// docs is a map of filename → file content (Markdown bytes).
-func (oai *OpenAIService) UploadFilesToVectorStore(ctx context.Context, vsID string, docs map[string][]byte) error {
+// AddFilesToVectorStore uploads each document as its own file, adds them to
+// the vector store in one batch and waits until they are processed. It returns
+// filename → file ID so individual documents can be replaced later. Files are
+// deleted again if the batch does not complete.
+func (oai *OpenAIService) AddFilesToVectorStore(ctx context.Context, vsID string, docs map[string][]byte) (map[string]string, error) {
 	if len(docs) == 0 {
-		return nil
+		return map[string]string{}, nil
 	}
 
 	client := openai.NewClient(oai.Options...)
 
-	fileParams := make([]openai.FileNewParams, 0, len(docs))
+	fileIDs := make(map[string]string, len(docs))
+	ids := make([]string, 0, len(docs))
+	cleanup := func() {
+		for _, id := range ids {
+			if _, err := client.Files.Delete(context.Background(), id, oai.Options...); err != nil {
+				fmt.Printf("failed to delete uploaded file %s: %v\n", id, err)
+			}
+		}
+	}
+
 	for name, content := range docs {
-		fileParams = append(fileParams, openai.FileNewParams{
+		file, err := client.Files.New(ctx, openai.FileNewParams{
 			File:    namedReader{bytes.NewReader(content), name},
 			Purpose: openai.FilePurposeAssistants,
-		})
+		}, oai.Options...)
+		if err != nil {
+			cleanup()
+			return nil, fmt.Errorf("failed to upload %s: %w", name, err)
+		}
+		fileIDs[name] = file.ID
+		ids = append(ids, file.ID)
 	}
 
-	batch, err := client.VectorStores.FileBatches.UploadAndPoll(
-		ctx, vsID, fileParams, nil, 5000, oai.Options...,
-	)
+	batch, err := client.VectorStores.FileBatches.NewAndPoll(ctx, vsID, openai.VectorStoreFileBatchNewParams{
+		FileIDs: ids,
+	}, 5000, oai.Options...)
 	if err != nil {
-		return fmt.Errorf("failed to upload files to vector store: %w", err)
+		cleanup()
+		return nil, fmt.Errorf("failed to add files to vector store: %w", err)
+	}
+	if batch.Status != openai.VectorStoreFileBatchStatusCompleted || batch.FileCounts.Failed > 0 {
+		cleanup()
+		return nil, fmt.Errorf("vector store file batch %s: %d file(s) errored", batch.Status, batch.FileCounts.Failed)
 	}
 
-	if batch.Status == "failed" {
-		return fmt.Errorf("vector store file batch failed: %d file(s) errored", batch.FileCounts.Failed)
+	return fileIDs, nil
+}
+
+// RemoveFilesFromVectorStore detaches files from the vector store and deletes
+// them. It attempts every file and reports the failures together.
+func (oai *OpenAIService) RemoveFilesFromVectorStore(ctx context.Context, vsID string, fileIDs []string) error {
+	client := openai.NewClient(oai.Options...)
+
+	var failed []string
+	for _, id := range fileIDs {
+		if _, err := client.VectorStores.Files.Delete(ctx, vsID, id, oai.Options...); err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", id, err))
+			continue
+		}
+		if _, err := client.Files.Delete(ctx, id, oai.Options...); err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", id, err))
+		}
 	}
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to remove files from vector store %s: %s", vsID, strings.Join(failed, "; "))
+	}
+	return nil
+}
+
+// VectorStoreFileIDs returns the IDs of the files in a vector store.
+func (oai *OpenAIService) VectorStoreFileIDs(ctx context.Context, vsID string) ([]string, error) {
+	client := openai.NewClient(oai.Options...)
+	iter := client.VectorStores.Files.ListAutoPaging(ctx, vsID, openai.VectorStoreFileListParams{}, oai.Options...)
 
+	var ids []string
+	for iter.Next() {
+		ids = append(ids, iter.Current().ID)
+	}
+	if err := iter.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list files of vector store %s: %w", vsID, err)
+	}
+	return ids, nil
+}
+
+// DeleteFiles deletes uploaded files, such as those left behind by a deleted
+// vector store. It attempts every file and reports the failures together.
+func (oai *OpenAIService) DeleteFiles(ctx context.Context, fileIDs []string) error {
+	client := openai.NewClient(oai.Options...)
+
+	var failed []string
+	for _, id := range fileIDs {
+		if _, err := client.Files.Delete(ctx, id, oai.Options...); err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", id, err))
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to delete files: %s", strings.Join(failed, "; "))
+	}
 	return nil
 }
 
 // AttachVectorStoreToAssistant updates the assistant to use the given vector store
-// for file_search. It also ensures the file_search tool is enabled.
+// for file_search. It also ensures the file_search tool is enabled alongside any
+// registered function tools.
 func (oai *OpenAIService) AttachVectorStoreToAssistant(ctx context.Context, assistantID, vsID string) error {
 	client := openai.NewClient(oai.Options...)
 	_, err := client.Beta.Assistants.Update(ctx, assistantID, openai.BetaAssistantUpdateParams{
-		Tools: []openai.AssistantToolUnionParam{
-			{OfFileSearch: &openai.FileSearchToolParam{}},
-		},
+		Tools: oai.assistantTools(),
 		ToolResources: openai.BetaAssistantUpdateParamsToolResources{
 			FileSearch: openai.BetaAssistantUpdateParamsToolResourcesFileSearch{
 				VectorStoreIDs: []string{vsID},
@@ -91,3 +171,25 @@ func (oai *OpenAIService) DeleteVectorStore(ctx context.Context, vsID string) er
 	}
 	return nil
 }
+
+// ListVectorStores returns every vector store in the account, newest first.
+func (oai *OpenAIService) ListVectorStores(ctx context.Context) ([]VectorStoreInfo, error) {
+	client := openai.NewClient(oai.Options...)
+	iter := client.VectorStores.ListAutoPaging(ctx, openai.VectorStoreListParams{}, oai.Options...)
+
+	var stores []VectorStoreInfo
+	for iter.Next() {
+		vs := iter.Current()
+		stores = append(stores, VectorStoreInfo{
+			ID:         vs.ID,
+			Name:       vs.Name,
+			CreatedAt:  time.Unix(vs.CreatedAt, 0).UTC(),
+			Files:      vs.FileCounts.Total,
+			UsageBytes: vs.UsageBytes,
+		})
+	}
+	if err := iter.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list vector stores: %w", err)
+	}
+	return stores, nil
+}
@@ -0,0 +1,133 @@
+package reconciler
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// docIndexKey prefixes the metadata key holding an assistant's document index.
+const docIndexKey = "vector_store_docs"
+
+// docIndex records, per document name, the hash of the content last uploaded
+// and the OpenAI file holding it.
+type docIndex map[string]indexedDoc
+
+type indexedDoc struct {
+	Hash   string `json:"hash"`
+	FileID string `json:"file_id"`
+}
+
+func hashDoc(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+func newDocIndex(docs map[string][]byte, fileIDs map[string]string) docIndex {
+	index := make(docIndex, len(docs))
+	for name, content := range docs {
+		index[name] = indexedDoc{Hash: hashDoc(content), FileID: fileIDs[name]}
+	}
+	return index
+}
+
+// fileIDs returns the OpenAI file behind each indexed document, sorted.
+func (index docIndex) fileIDs() []string {
+	ids := make([]string, 0, len(index))
+	for _, doc := range index {
+		if doc.FileID != "" {
+			ids = append(ids, doc.FileID)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// docIndexMetadataKey is the metadata key holding an assistant's document index.
+func docIndexMetadataKey(assistantID string) string {
+	return fmt.Sprintf("%s:%s", docIndexKey, assistantID)
+}
+
+// loadDocIndex returns the assistant's document index, or nil if none has
+// been recorded yet.
+func (r *Reconciler) loadDocIndex(ctx context.Context, assistantID string) (docIndex, error) {
+	raw, err := r.metadata().GetMetadata(ctx, docIndexMetadataKey(assistantID))
+	if err != nil || raw == "" {
+		return nil, err
+	}
+	var index docIndex
+	if err := json.Unmarshal([]byte(raw), &index); err != nil {
+		return nil, fmt.Errorf("failed to decode document index for %s: %w", assistantID, err)
+	}
+	return index, nil
+}
+
+func (r *Reconciler) saveDocIndex(ctx context.Context, assistantID string, index docIndex) error {
+	raw, err := json.Marshal(index)
+	if err != nil {
+		return fmt.Errorf("failed to encode document index for %s: %w", assistantID, err)
+	}
+	if err := r.metadata().SetMetadata(ctx, docIndexMetadataKey(assistantID), string(raw)); err != nil {
+		return fmt.Errorf("failed to persist document index for %s: %w", assistantID, err)
+	}
+	return nil
+}
+
+// diffDocs splits docs into those whose content differs from index (new or
+// changed) and the files in index that the new set no longer needs.
+func diffDocs(index docIndex, docs map[string][]byte) (changed map[string][]byte, unchanged docIndex, removed []string) {
+	changed = make(map[string][]byte)
+	unchanged = make(docIndex)
+	for name, content := range docs {
+		if old, ok := index[name]; ok && old.Hash == hashDoc(content) && old.FileID != "" {
+			unchanged[name] = old
+			continue
+		}
+		changed[name] = content
+	}
+	for name, old := range index {
+		if _, ok := unchanged[name]; !ok && old.FileID != "" {
+			removed = append(removed, old.FileID)
+		}
+	}
+	sort.Strings(removed)
+	return changed, unchanged, removed
+}
+
+// publishChanges updates the existing vector store in place: changed
+// documents are uploaded first and their old versions removed afterwards, so
+// the assistant never sees the store without a document.
+func (r *Reconciler) publishChanges(ctx context.Context, assistantID, vsID string, index docIndex, docs map[string][]byte) error {
+	changed, next, removed := diffDocs(index, docs)
+	if len(changed) == 0 && len(removed) == 0 {
+		fmt.Printf("reconciler: vector store %s is up to date\n", vsID)
+		return nil
+	}
+
+	fileIDs, err := r.oai.AddFilesToVectorStore(ctx, vsID, changed)
+	if err != nil {
+		return err
+	}
+	for name, content := range changed {
+		next[name] = indexedDoc{Hash: hashDoc(content), FileID: fileIDs[name]}
+	}
+
+	// Record the new files before removing the old ones, so a failure below
+	// leaves only stray old files rather than an index pointing at deleted ones.
+	if err := r.saveDocIndex(ctx, assistantID, next); err != nil {
+		return err
+	}
+
+	if len(removed) > 0 {
+		if err := r.oai.RemoveFilesFromVectorStore(ctx, vsID, removed); err != nil {
+			fmt.Printf("reconciler: %v\n", err)
+		}
+	}
+
+	fmt.Printf("reconciler: updated vector store %s: %d document(s) replaced, %d file(s) removed\n",
+		vsID, len(changed), len(removed))
+	return nil
+}
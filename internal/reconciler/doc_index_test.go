@@ -0,0 +1,236 @@
+package reconciler
+
+import (
+	"context"
+	"crowfather/internal/config"
+	"crowfather/internal/open_ai"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memMetadata struct {
+	mu     sync.Mutex
+	values map[string]string
+}
+
+func (m *memMetadata) GetMetadata(_ context.Context, key string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.values[key], nil
+}
+
+func (m *memMetadata) SetMetadata(_ context.Context, key, value string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.values == nil {
+		m.values = make(map[string]string)
+	}
+	m.values[key] = value
+	return nil
+}
+
+// fakeVectorStoreAPI answers the OpenAI file and vector store endpoints the
+// reconciler uses and records each call as "METHOD /path".
+type fakeVectorStoreAPI struct {
+	mu         sync.Mutex
+	calls      []string
+	files      int
+	failAttach bool
+	orphanAt   int64 // created_at of vs_orphan
+}
+
+func (f *fakeVectorStoreAPI) handler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		f.calls = append(f.calls, r.Method+" "+r.URL.Path)
+		f.mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/files":
+			f.mu.Lock()
+			f.files++
+			id := fmt.Sprintf("file-%d", f.files)
+			f.mu.Unlock()
+			fmt.Fprintf(w, `{"id":%q,"object":"file","bytes":1,"created_at":0,"filename":"doc.md","purpose":"assistants","status":"processed"}`, id)
+		case r.Method == http.MethodGet && r.URL.Path == "/vector_stores":
+			fmt.Fprint(w, `{"object":"list","has_more":false,"data":[`+
+				`{"id":"vs_current","object":"vector_store","created_at":0,"name":"crowfather-sports-data-asst_1","status":"completed","usage_bytes":0,"file_counts":{}},`+
+				fmt.Sprintf(`{"id":"vs_orphan","object":"vector_store","created_at":%d,"name":"crowfather-sports-data-asst_old","status":"completed","usage_bytes":0,"file_counts":{}},`, f.orphanAt)+
+				`{"id":"vs_legacy","object":"vector_store","created_at":0,"name":"crowfather-sports-data","status":"completed","usage_bytes":0,"file_counts":{}},`+
+				`{"id":"vs_unrelated","object":"vector_store","created_at":0,"name":"someone-elses-store","status":"completed","usage_bytes":0,"file_counts":{}}]}`)
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/files"):
+			fmt.Fprint(w, `{"object":"list","has_more":false,"data":[`+
+				`{"id":"file-a","object":"vector_store.file","created_at":0,"vector_store_id":"vs","status":"completed","usage_bytes":0,"last_error":null}]}`)
+		case r.Method == http.MethodPost && r.URL.Path == "/vector_stores":
+			fmt.Fprint(w, `{"id":"vs_new","object":"vector_store","created_at":0,"name":"x","status":"completed","usage_bytes":0,"file_counts":{}}`)
+		case strings.Contains(r.URL.Path, "/file_batches"):
+			fmt.Fprint(w, `{"id":"vsfb_1","object":"vector_store.files_batch","created_at":0,"vector_store_id":"vs","status":"completed","file_counts":{"completed":1,"failed":0,"in_progress":0,"cancelled":0,"total":1}}`)
+		case r.Method == http.MethodDelete && strings.HasPrefix(r.URL.Path, "/vector_stores/") && strings.Contains(r.URL.Path, "/files/"):
+			fmt.Fprint(w, `{"id":"file","object":"vector_store.file.deleted","deleted":true}`)
+		case r.Method == http.MethodDelete && strings.HasPrefix(r.URL.Path, "/files/"):
+			fmt.Fprint(w, `{"id":"file","object":"file","deleted":true}`)
+		case r.Method == http.MethodDelete && strings.HasPrefix(r.URL.Path, "/vector_stores/"):
+			fmt.Fprint(w, `{"id":"vs","object":"vector_store.deleted","deleted":true}`)
+		case r.Method == http.MethodPost && strings.HasPrefix(r.URL.Path, "/assistants/") && f.failAttach:
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"error":{"message":"no such assistant"}}`)
+		case r.Method == http.MethodPost && strings.HasPrefix(r.URL.Path, "/assistants/"):
+			fmt.Fprint(w, `{"id":"asst_1","object":"assistant","created_at":0,"model":"gpt-4o","tools":[]}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"error":{"message":"not found"}}`)
+		}
+	}
+}
+
+func (f *fakeVectorStoreAPI) count(prefix string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	n := 0
+	for _, c := range f.calls {
+		if strings.HasPrefix(c, prefix) {
+			n++
+		}
+	}
+	return n
+}
+
+func newPublishTestReconciler(t *testing.T) (*Reconciler, *fakeVectorStoreAPI, *memMetadata) {
+	api := &fakeVectorStoreAPI{}
+	server := httptest.NewServer(api.handler())
+	t.Cleanup(server.Close)
+
+	oai := open_ai.NewOpenAIService(&config.OpenAIConfig{APIKey: "test", BaseURL: server.URL + "/"}, nil)
+	meta := &memMetadata{}
+	return &Reconciler{oai: oai, runState: &runState{db: meta}}, api, meta
+}
+
+func TestDiffDocs(t *testing.T) {
+	index := docIndex{
+		"same.md":    {Hash: hashDoc([]byte("same")), FileID: "file-same"},
+		"changed.md": {Hash: hashDoc([]byte("old")), FileID: "file-changed"},
+		"gone.md":    {Hash: hashDoc([]byte("gone")), FileID: "file-gone"},
+	}
+	docs := map[string][]byte{
+		"same.md":    []byte("same"),
+		"changed.md": []byte("new"),
+		"added.md":   []byte("added"),
+	}
+
+	changed, unchanged, removed := diffDocs(index, docs)
+	assert.Len(t, changed, 2)
+	assert.Contains(t, changed, "changed.md")
+	assert.Contains(t, changed, "added.md")
+	assert.Equal(t, docIndex{"same.md": index["same.md"]}, unchanged)
+	assert.Equal(t, []string{"file-changed", "file-gone"}, removed)
+}
+
+func TestPublish_FirstRunRebuildsAndRecordsIndex(t *testing.T) {
+	r, api, meta := newPublishTestReconciler(t)
+
+	vsID, err := r.publish(context.Background(), "asst_1", map[string][]byte{"a.md": []byte("a"), "b.md": []byte("b")})
+	require.NoError(t, err)
+	assert.Equal(t, "vs_new", vsID)
+	assert.Equal(t, 1, countExact(api, "POST /vector_stores"))
+	assert.Equal(t, 2, api.count("POST /files"))
+
+	index, err := r.loadDocIndex(context.Background(), "asst_1")
+	require.NoError(t, err)
+	assert.Len(t, index, 2)
+	assert.Equal(t, "vs_new", meta.values[vectorStoreKey("asst_1")])
+}
+
+func TestPublish_UpdatesOnlyChangedDocs(t *testing.T) {
+	r, api, _ := newPublishTestReconciler(t)
+	ctx := context.Background()
+
+	_, err := r.publish(ctx, "asst_1", map[string][]byte{"a.md": []byte("a"), "b.md": []byte("b")})
+	require.NoError(t, err)
+	before, err := r.loadDocIndex(ctx, "asst_1")
+	require.NoError(t, err)
+
+	vsID, err := r.publish(ctx, "asst_1", map[string][]byte{"a.md": []byte("a"), "b.md": []byte("b2")})
+	require.NoError(t, err)
+	assert.Equal(t, "vs_new", vsID, "the existing store must be kept")
+	assert.Equal(t, 1, countExact(api, "POST /vector_stores"), "no new store should be created")
+	assert.Equal(t, 3, api.count("POST /files"), "only the changed document is uploaded")
+	assert.Equal(t, 1, api.count("DELETE /vector_stores/vs_new/files/"+before["b.md"].FileID))
+	assert.Equal(t, 1, api.count("POST /assistants/"), "the assistant is only attached on rebuild")
+
+	after, err := r.loadDocIndex(ctx, "asst_1")
+	require.NoError(t, err)
+	assert.Equal(t, before["a.md"], after["a.md"])
+	assert.NotEqual(t, before["b.md"].FileID, after["b.md"].FileID)
+}
+
+func TestPublish_NoChangesIsNoop(t *testing.T) {
+	r, api, _ := newPublishTestReconciler(t)
+	ctx := context.Background()
+	docs := map[string][]byte{"a.md": []byte("a")}
+
+	_, err := r.publish(ctx, "asst_1", docs)
+	require.NoError(t, err)
+	calls := len(api.calls)
+
+	_, err = r.publish(ctx, "asst_1", docs)
+	require.NoError(t, err)
+	assert.Equal(t, calls, len(api.calls), "an unchanged document set makes no API calls")
+}
+
+// Rebuilding deletes the old store and the files its index recorded.
+func TestRebuild_DeletesOldStoreFiles(t *testing.T) {
+	r, api, meta := newPublishTestReconciler(t)
+	ctx := context.Background()
+	meta.values = map[string]string{vectorStoreKey("asst_1"): "vs_old"}
+	require.NoError(t, r.saveDocIndex(ctx, "asst_1", docIndex{
+		"a.md": {Hash: "h", FileID: "file-old-a"},
+		"b.md": {Hash: "h", FileID: "file-old-b"},
+	}))
+
+	vsID, err := r.rebuild(ctx, "asst_1", map[string][]byte{"a.md": []byte("a")})
+	require.NoError(t, err)
+	assert.Equal(t, "vs_new", vsID)
+	assert.Equal(t, 1, countExact(api, "DELETE /vector_stores/vs_old"))
+	assert.Equal(t, 1, countExact(api, "DELETE /files/file-old-a"))
+	assert.Equal(t, 1, countExact(api, "DELETE /files/file-old-b"))
+
+	index, err := r.loadDocIndex(ctx, "asst_1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"file-1"}, index.fileIDs())
+}
+
+// A store that can't be attached is deleted with its files, and the old
+// store stays recorded.
+func TestRebuild_AttachFailureCleansUp(t *testing.T) {
+	r, api, meta := newPublishTestReconciler(t)
+	api.failAttach = true
+	meta.values = map[string]string{vectorStoreKey("asst_1"): "vs_old"}
+
+	_, err := r.rebuild(context.Background(), "asst_1", map[string][]byte{"a.md": []byte("a"), "b.md": []byte("b")})
+	assert.ErrorContains(t, err, "vector store attachment failed")
+	assert.Equal(t, 1, countExact(api, "DELETE /vector_stores/vs_new"))
+	assert.Equal(t, 1, countExact(api, "DELETE /files/file-1"))
+	assert.Equal(t, 1, countExact(api, "DELETE /files/file-2"))
+	assert.Zero(t, countExact(api, "DELETE /vector_stores/vs_old"))
+	assert.Equal(t, "vs_old", meta.values[vectorStoreKey("asst_1")])
+}
+
+func countExact(api *fakeVectorStoreAPI, call string) int {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	n := 0
+	for _, c := range api.calls {
+		if c == call {
+			n++
+		}
+	}
+	return n
+}
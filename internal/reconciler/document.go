@@ -4,10 +4,19 @@ import (
 	"crowfather/internal/espn"
 	"crowfather/internal/sleeper"
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 )
 
+// summaryMoveLimit caps the moves listed per league in the refresh summary;
+// the league document has all of them.
+const summaryMoveLimit = 10
+
+// scoreDetailWeeks is how many of the most recent weeks list each team's
+// starters in the league document; older weeks only show the final score.
+const scoreDetailWeeks = 2
+
 // buildNFLTeamDoc generates a Markdown document for a single NFL team.
 func buildNFLTeamDoc(team espn.TeamWithRoster) []byte {
 	var sb strings.Builder
@@ -34,14 +43,24 @@ func buildNFLTeamDoc(team espn.TeamWithRoster) []byte {
 
 // leagueData holds resolved league information for document generation.
 type leagueData struct {
-	leagueID   string
-	leagueName string
-	rosters    []resolvedRoster
-	trades     []resolvedTrade
+	leagueID    string
+	leagueName  string
+	groupID     string           // GroupMe group bound to the league, if any
+	assistantID string           // assistant whose vector store holds the league doc
+	faabBudget  int              // FAAB each team starts with; 0 if the league doesn't bid
+	state       sleeper.NFLState // current season and week; zero if unknown
+	standings   []standing
+	rosters     []resolvedRoster
+	trades      []resolvedTrade
+	moves       []resolvedMove    // waiver claims and free-agent moves, newest first
+	matchups    []resolvedMatchup // games of the season so far, newest week first
+	drafts      []resolvedDraft   // draft boards, newest season first
+	futurePicks []pickHolding     // upcoming draft picks by current owner
 }
 
 type resolvedRoster struct {
 	ownerName string
+	faabUsed  int
 	players   []resolvedPlayer
 }
 
@@ -59,7 +78,113 @@ type resolvedTrade struct {
 
 type tradeSide struct {
 	ownerName string
-	receives  []string // player names + draft picks
+	receives  []string // player names, draft picks and FAAB
+}
+
+// resolvedMove is a completed waiver claim or free-agent add/drop.
+type resolvedMove struct {
+	timestamp time.Time
+	kind      string // sleeper.TransactionWaiver or sleeper.TransactionFreeAgent
+	ownerName string
+	adds      []string
+	drops     []string
+	bid       int           // FAAB bid on a waiver claim
+	outbid    []losingClaim // failed claims on the same players that week, highest bid first
+}
+
+type losingClaim struct {
+	ownerName string
+	bid       int
+}
+
+type resolvedMatchup struct {
+	week  int
+	teams []matchupTeam // highest score first
+}
+
+type matchupTeam struct {
+	ownerName string
+	points    float64
+	starters  []starterScore
+}
+
+type starterScore struct {
+	name   string
+	points float64
+}
+
+// describe renders the game as one line, e.g. "Alice 123.45 def. Bob 98.10",
+// or "Alice 87.20 vs Bob 64.10" while it is still being played.
+func (m resolvedMatchup) describe(final bool) string {
+	if len(m.teams) < 2 {
+		return fmt.Sprintf("%s %.2f (no opponent)", m.teams[0].ownerName, m.teams[0].points)
+	}
+	a, b := m.teams[0], m.teams[1]
+	verb := "vs"
+	if final && a.points == b.points {
+		verb = "tied"
+	} else if final {
+		verb = "def."
+	}
+	return fmt.Sprintf("%s %.2f %s %s %.2f", a.ownerName, a.points, verb, b.ownerName, b.points)
+}
+
+// weekFinal reports whether a week's games are over. Only the current
+// regular season week is still being played.
+func (ld leagueData) weekFinal(week int) bool {
+	return ld.state.SeasonType != "regular" || week < ld.state.Week
+}
+
+// describe renders the move as one line, e.g.
+// "Alice claimed Jaylen Warren for $23 over Bob ($20), dropped Zach Charbonnet".
+func (m resolvedMove) describe() string {
+	var sb strings.Builder
+	sb.WriteString(m.ownerName)
+	switch {
+	case len(m.adds) == 0:
+		fmt.Fprintf(&sb, " dropped %s", strings.Join(m.drops, ", "))
+		return sb.String()
+	case m.kind == sleeper.TransactionWaiver:
+		fmt.Fprintf(&sb, " claimed %s", strings.Join(m.adds, ", "))
+		if m.bid > 0 {
+			fmt.Fprintf(&sb, " for $%d", m.bid)
+		}
+	default:
+		fmt.Fprintf(&sb, " picked up %s", strings.Join(m.adds, ", "))
+	}
+
+	if len(m.outbid) > 0 {
+		losers := make([]string, len(m.outbid))
+		for i, c := range m.outbid {
+			losers[i] = c.ownerName
+			if c.bid > 0 {
+				losers[i] += fmt.Sprintf(" ($%d)", c.bid)
+			}
+		}
+		fmt.Fprintf(&sb, " over %s", strings.Join(losers, ", "))
+	}
+	if len(m.drops) > 0 {
+		fmt.Fprintf(&sb, ", dropped %s", strings.Join(m.drops, ", "))
+	}
+	return sb.String()
+}
+
+// describeWeek renders the NFL state, e.g. "week 10 of the 2025 regular
+// season", or "" if it is unknown.
+func describeWeek(state sleeper.NFLState) string {
+	if state.Season == "" {
+		return ""
+	}
+	switch state.SeasonType {
+	case "pre":
+		return fmt.Sprintf("the %s preseason", state.Season)
+	case "off":
+		return fmt.Sprintf("the offseason after the %s season", state.Season)
+	case "post":
+		return fmt.Sprintf("week %d of the %s postseason", state.Week, state.Season)
+	default:
+		return fmt.Sprintf("week %d of the %s regular season", state.Week, state.Season)
+	}
 }
 
 // buildFantasyLeagueDoc generates a Markdown document for a single Sleeper league.
@@ -67,6 +192,13 @@ func buildFantasyLeagueDoc(ld leagueData) []byte {
 	var sb strings.Builder
 
 	fmt.Fprintf(&sb, "# Fantasy League: %s\n\n", ld.leagueName)
+	if week := describeWeek(ld.state); week != "" {
+		fmt.Fprintf(&sb, "As of %s.\n\n", week)
+	}
+
+	writeStandings(&sb, ld.standings)
+
+	writeScores(&sb, ld)
 
 	if len(ld.trades) > 0 {
 		sb.WriteString("## Recent Trades\n\n")
@@ -79,8 +211,21 @@ func buildFantasyLeagueDoc(ld leagueData) []byte {
 		}
 	}
 
+	if len(ld.moves) > 0 {
+		sb.WriteString("## Recent Moves\n\n")
+		for _, m := range ld.moves {
+			fmt.Fprintf(&sb, "- %s: %s\n", m.timestamp.Format("Jan 2, 2006"), m.describe())
+		}
+		sb.WriteString("\n")
+	}
+
+	writeDrafts(&sb, ld)
+
 	for _, r := range ld.rosters {
 		fmt.Fprintf(&sb, "## Team: %s\n\n", r.ownerName)
+		if ld.faabBudget > 0 {
+			fmt.Fprintf(&sb, "FAAB remaining: $%d of $%d\n\n", ld.faabBudget-r.faabUsed, ld.faabBudget)
+		}
 		sb.WriteString("| Player | Position | NFL Team | NFL Record |\n")
 		sb.WriteString("|--------|----------|----------|------------|\n")
 		for _, p := range r.players {
@@ -92,17 +237,67 @@ func buildFantasyLeagueDoc(ld leagueData) []byte {
 	return []byte(sb.String())
 }
 
-// buildTradeSummary generates the GroupMe notification string for recent trades.
-func buildTradeSummary(leagues []leagueData) string {
+// writeScores writes the league's games, newest week first. The most recent
+// scoreDetailWeeks weeks also list each team's starters.
+func writeScores(sb *strings.Builder, ld leagueData) {
+	if len(ld.matchups) == 0 {
+		return
+	}
+	sb.WriteString("## Scores\n\n")
+
+	weeks := 0
+	for i, m := range ld.matchups {
+		if i == 0 || m.week != ld.matchups[i-1].week {
+			weeks++
+			if i > 0 {
+				sb.WriteString("\n")
+			}
+			fmt.Fprintf(sb, "### Week %d", m.week)
+			if !ld.weekFinal(m.week) {
+				sb.WriteString(" (in progress)")
+			}
+			sb.WriteString("\n\n")
+		}
+
+		fmt.Fprintf(sb, "- %s\n", m.describe(ld.weekFinal(m.week)))
+		if weeks > scoreDetailWeeks {
+			continue
+		}
+		for _, t := range m.teams {
+			starters := make([]string, len(t.starters))
+			for j, s := range t.starters {
+				starters[j] = fmt.Sprintf("%s %.2f", s.name, s.points)
+			}
+			fmt.Fprintf(sb, "  - %s starters: %s\n", t.ownerName, strings.Join(starters, ", "))
+		}
+	}
+	sb.WriteString("\n")
+}
+
+// buildRefreshSummary generates the GroupMe notification string for recent
+// trades and moves.
+func buildRefreshSummary(leagues []leagueData) string {
 	var sb strings.Builder
 	sb.WriteString("Rosters refreshed!\n\n")
 
-	anyTrades := false
+	anyActivity := false
 	for _, ld := range leagues {
+		if len(ld.moves) > 0 {
+			anyActivity = true
+			fmt.Fprintf(&sb, "Recent Moves - %s:\n", ld.leagueName)
+			for _, m := range ld.moves[:min(len(ld.moves), summaryMoveLimit)] {
+				fmt.Fprintf(&sb, "  - %s\n", m.describe())
+			}
+			if extra := len(ld.moves) - summaryMoveLimit; extra > 0 {
+				fmt.Fprintf(&sb, "  ...and %d more\n", extra)
+			}
+			sb.WriteString("\n")
+		}
+
 		if len(ld.trades) == 0 {
 			continue
 		}
-		anyTrades = true
+		anyActivity = true
 		fmt.Fprintf(&sb, "Recent Trades - %s:\n", ld.leagueName)
 		for _, t := range ld.trades {
 			for i, side := range t.sides {
@@ -120,37 +315,29 @@ func buildTradeSummary(leagues []leagueData) string {
 		sb.WriteString("\n")
 	}
 
-	if !anyTrades {
-		sb.WriteString("No recent trades found.")
+	if !anyActivity {
+		sb.WriteString("No recent trades or moves found.")
 	}
 
 	return sb.String()
 }
 
+// sleeperLeague is the raw Sleeper data fetched for one league.
+type sleeperLeague struct {
+	leagueID     string
+	leagueName   string
+	rosters      []sleeper.Roster
+	users        []sleeper.User
+	transactions []sleeper.Transaction
+	matchups     []sleeper.WeekMatchup
+	drafts       leagueDrafts
+}
+
 // resolveLeague converts raw Sleeper data into a leagueData struct, cross-referencing
 // ESPN player data where possible.
-func resolveLeague(
-	leagueID string,
-	leagueName string,
-	rosters []sleeper.Roster,
-	users []sleeper.User,
-	players map[string]sleeper.SleeperPlayer,
-	transactions []sleeper.Transaction,
-	espnByName map[string]espn.TeamWithRoster,
-) leagueData {
-	// Build roster_id → owner name lookup
-	ownerByRosterID := make(map[int]string)
-	userByID := make(map[string]sleeper.User)
-	for _, u := range users {
-		userByID[u.UserID] = u
-	}
-	for _, r := range rosters {
-		if u, ok := userByID[r.OwnerID]; ok {
-			ownerByRosterID[r.RosterID] = u.DisplayName
-		} else {
-			ownerByRosterID[r.RosterID] = fmt.Sprintf("Team %d", r.RosterID)
-		}
-	}
+func resolveLeague(data sleeperLeague, players map[string]sleeper.SleeperPlayer, espnByName map[string]espn.TeamWithRoster) leagueData {
+	rosters, transactions, drafts := data.rosters, data.transactions, data.drafts
+	ownerByRosterID := ownerNames(rosters, data.users)
 
 	// Resolve rosters
 	var resolved []resolvedRoster
@@ -174,30 +361,37 @@ func resolveLeague(
 			}
 			rPlayers = append(rPlayers, rp)
 		}
-		resolved = append(resolved, resolvedRoster{ownerName: owner, players: rPlayers})
+		resolved = append(resolved, resolvedRoster{ownerName: owner, faabUsed: r.Settings.WaiverBudgetUsed, players: rPlayers})
+	}
+
+	playerName := func(pid string) string {
+		if sp, ok := players[pid]; ok {
+			return sp.FullName
+		}
+		return pid
 	}
 
 	// Resolve trades
 	var trades []resolvedTrade
 	for _, t := range transactions {
-		if t.Type != "trade" || t.Status != "complete" {
+		if t.Type != sleeper.TransactionTrade || t.Status != sleeper.StatusComplete {
 			continue
 		}
 
 		// Group adds by the receiving roster
 		receives := make(map[int][]string)
 		for pid, rosterID := range t.Adds {
-			name := pid
-			if sp, ok := players[pid]; ok {
-				name = sp.FullName
-			}
-			receives[rosterID] = append(receives[rosterID], name)
+			receives[rosterID] = append(receives[rosterID], playerName(pid))
 		}
-		// Add draft picks to the receiving owner
+		sortValues(receives)
+		// Add draft picks and FAAB to the receiving owner
 		for _, pick := range t.DraftPicks {
 			pickStr := fmt.Sprintf("%s %s Round Pick", pick.Season, ordinal(pick.Round))
 			receives[pick.OwnerID] = append(receives[pick.OwnerID], pickStr)
 		}
+		for _, wb := range t.WaiverBudget {
+			receives[wb.Receiver] = append(receives[wb.Receiver], fmt.Sprintf("$%d FAAB", wb.Amount))
+		}
 
 		var sides []tradeSide
 		for rosterID, items := range receives {
@@ -206,6 +400,7 @@ func resolveLeague(
 				receives:  items,
 			})
 		}
+		sort.Slice(sides, func(i, j int) bool { return sides[i].ownerName < sides[j].ownerName })
 
 		trades = append(trades, resolvedTrade{
 			timestamp: time.Unix(t.Created/1000, 0),
@@ -214,10 +409,128 @@ func resolveLeague(
 	}
 
 	return leagueData{
-		leagueID:   leagueID,
-		leagueName: leagueName,
-		rosters:    resolved,
-		trades:     trades,
+		leagueID:    data.leagueID,
+		leagueName:  data.leagueName,
+		standings:   resolveStandings(rosters, ownerByRosterID),
+		rosters:     resolved,
+		trades:      trades,
+		moves:       resolveMoves(transactions, ownerByRosterID, playerName),
+		matchups:    resolveMatchups(data.matchups, ownerByRosterID, playerName),
+		drafts:      resolveDrafts(drafts, ownerByRosterID, playerName),
+		futurePicks: resolveFuturePicks(drafts, rosters, ownerByRosterID),
+	}
+}
+
+// resolveMatchups names the owners and starters of each game, newest week
+// first, with the higher score first in each game.
+func resolveMatchups(matchups []sleeper.WeekMatchup, ownerByRosterID map[int]string, playerName func(string) string) []resolvedMatchup {
+	var resolved []resolvedMatchup
+	for _, m := range matchups {
+		if len(m.Teams) == 0 {
+			continue
+		}
+		rm := resolvedMatchup{week: m.Week}
+		for _, t := range m.Teams {
+			team := matchupTeam{ownerName: ownerByRosterID[t.RosterID], points: t.Points}
+			for i, pid := range t.Starters {
+				s := starterScore{name: playerName(pid)}
+				if pid == sleeper.EmptySlot {
+					s.name = "(empty)"
+				}
+				if i < len(t.StartersPoints) {
+					s.points = t.StartersPoints[i]
+				}
+				team.starters = append(team.starters, s)
+			}
+			rm.teams = append(rm.teams, team)
+		}
+		sort.SliceStable(rm.teams, func(i, j int) bool { return rm.teams[i].points > rm.teams[j].points })
+		resolved = append(resolved, rm)
+	}
+
+	sort.SliceStable(resolved, func(i, j int) bool { return resolved[i].week > resolved[j].week })
+	return resolved
+}
+
+// ownerNames maps each roster ID to its owner's display name, or "Team N" for
+// rosters without an owner.
+func ownerNames(rosters []sleeper.Roster, users []sleeper.User) map[int]string {
+	ownerByRosterID := make(map[int]string)
+	userByID := make(map[string]sleeper.User)
+	for _, u := range users {
+		userByID[u.UserID] = u
+	}
+	for _, r := range rosters {
+		if u, ok := userByID[r.OwnerID]; ok {
+			ownerByRosterID[r.RosterID] = u.DisplayName
+		} else {
+			ownerByRosterID[r.RosterID] = fmt.Sprintf("Team %d", r.RosterID)
+		}
+	}
+	return ownerByRosterID
+}
+
+// resolveMoves converts completed waiver and free-agent transactions into
+// moves, newest first. Each waiver claim lists the failed claims on the same
+// player in the same week, so the summary shows who was outbid.
+func resolveMoves(transactions []sleeper.Transaction, ownerByRosterID map[int]string, playerName func(string) string) []resolvedMove {
+	type claimKey struct {
+		leg      int
+		playerID string
+	}
+	failed := make(map[claimKey][]losingClaim)
+	for _, t := range transactions {
+		if t.Type != sleeper.TransactionWaiver || t.Status != sleeper.StatusFailed {
+			continue
+		}
+		for pid, rosterID := range t.Adds {
+			k := claimKey{t.Leg, pid}
+			failed[k] = append(failed[k], losingClaim{ownerName: ownerByRosterID[rosterID], bid: t.Bid()})
+		}
+	}
+
+	var moves []resolvedMove
+	for _, t := range transactions {
+		if t.Status != sleeper.StatusComplete || (t.Type != sleeper.TransactionWaiver && t.Type != sleeper.TransactionFreeAgent) {
+			continue
+		}
+
+		m := resolvedMove{timestamp: time.Unix(t.Created/1000, 0), kind: t.Type, bid: t.Bid()}
+		for pid, rosterID := range t.Adds {
+			m.ownerName = ownerByRosterID[rosterID]
+			m.adds = append(m.adds, playerName(pid))
+			if t.Type == sleeper.TransactionWaiver {
+				m.outbid = append(m.outbid, failed[claimKey{t.Leg, pid}]...)
+			}
+		}
+		for pid, rosterID := range t.Drops {
+			if m.ownerName == "" {
+				m.ownerName = ownerByRosterID[rosterID]
+			}
+			m.drops = append(m.drops, playerName(pid))
+		}
+		if len(m.adds) == 0 && len(m.drops) == 0 {
+			continue
+		}
+		sort.Strings(m.adds)
+		sort.Strings(m.drops)
+		sort.SliceStable(m.outbid, func(i, j int) bool {
+			if m.outbid[i].bid != m.outbid[j].bid {
+				return m.outbid[i].bid > m.outbid[j].bid
+			}
+			return m.outbid[i].ownerName < m.outbid[j].ownerName
+		})
+		moves = append(moves, m)
+	}
+
+	sort.SliceStable(moves, func(i, j int) bool { return moves[i].timestamp.After(moves[j].timestamp) })
+	return moves
+}
+
+// sortValues sorts each list in m.
+func sortValues(m map[int][]string) {
+	for _, items := range m {
+		sort.Strings(items)
 	}
 }
 
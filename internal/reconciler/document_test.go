@@ -1,6 +1,7 @@
 package reconciler
 
 import (
+	"fmt"
 	"strings"
 	"testing"
 	"time"
@@ -93,16 +94,16 @@ func TestBuildFantasyLeagueDoc_IncludesTrades(t *testing.T) {
 	assert.Contains(t, doc, "Kelce")
 }
 
-func TestBuildTradeSummary_NoTrades(t *testing.T) {
+func TestBuildRefreshSummary_NoActivity(t *testing.T) {
 	leagues := []leagueData{
 		{leagueName: "League A", trades: nil},
 	}
-	summary := buildTradeSummary(leagues)
+	summary := buildRefreshSummary(leagues)
 	assert.Contains(t, summary, "Rosters refreshed!")
-	assert.Contains(t, summary, "No recent trades found.")
+	assert.Contains(t, summary, "No recent trades or moves found.")
 }
 
-func TestBuildTradeSummary_WithTrades(t *testing.T) {
+func TestBuildRefreshSummary_WithTrades(t *testing.T) {
 	leagues := []leagueData{
 		{
 			leagueName: "Dynasty League",
@@ -116,12 +117,187 @@ func TestBuildTradeSummary_WithTrades(t *testing.T) {
 			},
 		},
 	}
-	summary := buildTradeSummary(leagues)
+	summary := buildRefreshSummary(leagues)
 	assert.Contains(t, summary, "Rosters refreshed!")
 	assert.Contains(t, summary, "Dynasty League")
 	assert.Contains(t, summary, "Alice")
 	assert.Contains(t, summary, "Bob")
-	assert.NotContains(t, summary, "No recent trades found.")
+	assert.NotContains(t, summary, "No recent trades or moves found.")
+}
+
+func TestBuildRefreshSummary_CapsMoves(t *testing.T) {
+	moves := make([]resolvedMove, summaryMoveLimit+3)
+	for i := range moves {
+		moves[i] = resolvedMove{kind: sleeper.TransactionFreeAgent, ownerName: "Alice", adds: []string{fmt.Sprintf("Player %d", i)}}
+	}
+	summary := buildRefreshSummary([]leagueData{{leagueName: "Dynasty League", moves: moves}})
+	assert.Contains(t, summary, "Recent Moves - Dynasty League:\n  - Alice picked up Player 0\n")
+	assert.NotContains(t, summary, fmt.Sprintf("Player %d\n", summaryMoveLimit))
+	assert.Contains(t, summary, "...and 3 more")
+	assert.NotContains(t, summary, "No recent trades or moves found.")
+}
+
+func TestResolvedMove_Describe(t *testing.T) {
+	cases := []struct {
+		move resolvedMove
+		want string
+	}{
+		{
+			resolvedMove{kind: sleeper.TransactionWaiver, ownerName: "Alice", adds: []string{"Jaylen Warren"}, drops: []string{"Zach Charbonnet"},
+				bid: 23, outbid: []losingClaim{{"Bob", 20}, {"Carol", 5}}},
+			"Alice claimed Jaylen Warren for $23 over Bob ($20), Carol ($5), dropped Zach Charbonnet",
+		},
+		{
+			resolvedMove{kind: sleeper.TransactionWaiver, ownerName: "Alice", adds: []string{"Jaylen Warren"}, outbid: []losingClaim{{"Bob", 0}}},
+			"Alice claimed Jaylen Warren over Bob",
+		},
+		{
+			resolvedMove{kind: sleeper.TransactionFreeAgent, ownerName: "Dave", adds: []string{"Tyler Boyd"}},
+			"Dave picked up Tyler Boyd",
+		},
+		{
+			resolvedMove{kind: sleeper.TransactionFreeAgent, ownerName: "Dave", drops: []string{"Josh Reynolds"}},
+			"Dave dropped Josh Reynolds",
+		},
+	}
+	for _, tc := range cases {
+		assert.Equal(t, tc.want, tc.move.describe())
+	}
+}
+
+func TestBuildFantasyLeagueDoc_IncludesMovesAndFAAB(t *testing.T) {
+	ld := leagueData{
+		leagueName: "Test League",
+		faabBudget: 100,
+		rosters:    []resolvedRoster{{ownerName: "Alice", faabUsed: 23}},
+		moves: []resolvedMove{{
+			timestamp: time.Date(2025, 10, 8, 12, 0, 0, 0, time.UTC),
+			kind:      sleeper.TransactionWaiver,
+			ownerName: "Alice",
+			adds:      []string{"Jaylen Warren"},
+			bid:       23,
+		}},
+	}
+
+	doc := string(buildFantasyLeagueDoc(ld))
+	assert.Contains(t, doc, "## Recent Moves\n\n- Oct 8, 2025: Alice claimed Jaylen Warren for $23\n")
+	assert.Contains(t, doc, "FAAB remaining: $77 of $100")
+}
+
+func TestBuildFantasyLeagueDoc_IncludesWeek(t *testing.T) {
+	ld := leagueData{
+		leagueName: "Test League",
+		state:      sleeper.NFLState{Week: 10, Leg: 10, Season: "2025", SeasonType: "regular"},
+	}
+	assert.Contains(t, string(buildFantasyLeagueDoc(ld)), "# Fantasy League: Test League\n\nAs of week 10 of the 2025 regular season.\n")
+
+	ld.state = sleeper.NFLState{}
+	assert.NotContains(t, string(buildFantasyLeagueDoc(ld)), "As of")
+}
+
+func TestDescribeWeek(t *testing.T) {
+	assert.Equal(t, "the 2025 preseason", describeWeek(sleeper.NFLState{Season: "2025", SeasonType: "pre"}))
+	assert.Equal(t, "week 2 of the 2025 postseason", describeWeek(sleeper.NFLState{Week: 2, Season: "2025", SeasonType: "post"}))
+	assert.Equal(t, "the offseason after the 2025 season", describeWeek(sleeper.NFLState{Season: "2025", SeasonType: "off"}))
+}
+
+func TestResolveLeague_ResolvesMatchups(t *testing.T) {
+	rosters := []sleeper.Roster{{RosterID: 1, OwnerID: "u1"}, {RosterID: 2, OwnerID: "u2"}}
+	users := []sleeper.User{{UserID: "u1", DisplayName: "Alice"}, {UserID: "u2", DisplayName: "Bob"}}
+	players := map[string]sleeper.SleeperPlayer{"p1": {FullName: "Patrick Mahomes"}}
+	matchups := []sleeper.WeekMatchup{
+		{Week: 1, MatchupID: 1, Teams: []sleeper.Matchup{
+			{RosterID: 1, Points: 90, Starters: []string{"p1", sleeper.EmptySlot}, StartersPoints: []float64{25.5}},
+			{RosterID: 2, Points: 110},
+		}},
+		{Week: 2, MatchupID: 1, Teams: []sleeper.Matchup{{RosterID: 1, Points: 70}, {RosterID: 2, Points: 60}}},
+	}
+
+	ld := resolveLeague(sleeperLeague{leagueID: "l1", leagueName: "L", rosters: rosters, users: users, matchups: matchups}, players, nil)
+	require.Len(t, ld.matchups, 2)
+	assert.Equal(t, 2, ld.matchups[0].week, "newest week first")
+
+	week1 := ld.matchups[1]
+	require.Len(t, week1.teams, 2)
+	assert.Equal(t, "Bob", week1.teams[0].ownerName, "higher score first")
+	assert.Equal(t, []starterScore{{name: "Patrick Mahomes", points: 25.5}, {name: "(empty)"}}, week1.teams[1].starters)
+}
+
+func TestBuildFantasyLeagueDoc_IncludesScores(t *testing.T) {
+	ld := leagueData{
+		leagueName: "Test League",
+		state:      sleeper.NFLState{Week: 3, Season: "2025", SeasonType: "regular"},
+		rosters:    []resolvedRoster{{ownerName: "Alice"}, {ownerName: "Bob"}},
+		matchups: []resolvedMatchup{
+			{week: 3, teams: []matchupTeam{
+				{ownerName: "Bob", points: 40.5, starters: []starterScore{{name: "Josh Allen", points: 20.25}}},
+				{ownerName: "Alice", points: 12},
+			}},
+			{week: 2, teams: []matchupTeam{{ownerName: "Alice", points: 100}, {ownerName: "Bob", points: 100}}},
+			{week: 1, teams: []matchupTeam{
+				{ownerName: "Alice", points: 123.45, starters: []starterScore{{name: "Patrick Mahomes", points: 30}}},
+				{ownerName: "Bob", points: 98.1},
+			}},
+		},
+	}
+
+	doc := string(buildFantasyLeagueDoc(ld))
+	assert.Contains(t, doc, "## Scores\n\n### Week 3 (in progress)\n\n- Bob 40.50 vs Alice 12.00\n  - Bob starters: Josh Allen 20.25\n")
+	assert.Contains(t, doc, "### Week 2\n\n- Alice 100.00 tied Bob 100.00\n")
+	assert.Contains(t, doc, "### Week 1\n\n- Alice 123.45 def. Bob 98.10\n\n")
+	assert.NotContains(t, doc, "Patrick Mahomes 30.00", "only the latest weeks list starters")
+}
+
+func TestResolveLeague_ResolvesMoves(t *testing.T) {
+	rosters := []sleeper.Roster{
+		{RosterID: 1, OwnerID: "u1"},
+		{RosterID: 2, OwnerID: "u2"},
+		{RosterID: 3, OwnerID: "u3"},
+	}
+	users := []sleeper.User{
+		{UserID: "u1", DisplayName: "Alice"},
+		{UserID: "u2", DisplayName: "Bob"},
+		{UserID: "u3", DisplayName: "Carol"},
+	}
+	players := map[string]sleeper.SleeperPlayer{
+		"p1": {FullName: "Jaylen Warren"},
+		"p2": {FullName: "Zach Charbonnet"},
+		"p3": {FullName: "Tyler Boyd"},
+	}
+	bid := func(n int) *sleeper.TransactionSettings { return &sleeper.TransactionSettings{WaiverBid: n} }
+	transactions := []sleeper.Transaction{
+		{Type: "waiver", Status: "complete", Leg: 5, Adds: map[string]int{"p1": 1}, Drops: map[string]int{"p2": 1}, Settings: bid(23), Created: 2000_000},
+		{Type: "waiver", Status: "failed", Leg: 5, Adds: map[string]int{"p1": 3}, Settings: bid(5), Created: 2000_000},
+		{Type: "waiver", Status: "failed", Leg: 5, Adds: map[string]int{"p1": 2}, Settings: bid(20), Created: 2000_000},
+		{Type: "waiver", Status: "failed", Leg: 4, Adds: map[string]int{"p1": 3}, Settings: bid(50), Created: 1000_000}, // earlier week
+		{Type: "free_agent", Status: "complete", Adds: map[string]int{"p3": 2}, Created: 3000_000},
+		{Type: "trade", Status: "complete", Adds: map[string]int{"p2": 3}, Created: 3000_000},
+	}
+
+	ld := resolveLeague(sleeperLeague{leagueID: "l1", leagueName: "L", rosters: rosters, users: users, transactions: transactions}, players, nil)
+	require.Len(t, ld.moves, 2)
+	assert.Equal(t, "Bob picked up Tyler Boyd", ld.moves[0].describe(), "newest first")
+	assert.Equal(t, "Alice claimed Jaylen Warren for $23 over Bob ($20), Carol ($5), dropped Zach Charbonnet", ld.moves[1].describe())
+	assert.Len(t, ld.trades, 1)
+}
+
+func TestResolveLeague_TradedFAAB(t *testing.T) {
+	rosters := []sleeper.Roster{{RosterID: 1, OwnerID: "u1"}, {RosterID: 2, OwnerID: "u2"}}
+	users := []sleeper.User{{UserID: "u1", DisplayName: "Alice"}, {UserID: "u2", DisplayName: "Bob"}}
+	transactions := []sleeper.Transaction{{
+		Type:         "trade",
+		Status:       "complete",
+		Adds:         map[string]int{"p1": 2},
+		WaiverBudget: []sleeper.WaiverBudgetChange{{Sender: 2, Receiver: 1, Amount: 15}},
+	}}
+	players := map[string]sleeper.SleeperPlayer{"p1": {FullName: "Patrick Mahomes"}}
+
+	ld := resolveLeague(sleeperLeague{leagueID: "l1", leagueName: "L", rosters: rosters, users: users, transactions: transactions}, players, nil)
+	require.Len(t, ld.trades, 1)
+	assert.Equal(t, []tradeSide{
+		{ownerName: "Alice", receives: []string{"$15 FAAB"}},
+		{ownerName: "Bob", receives: []string{"Patrick Mahomes"}},
+	}, ld.trades[0].sides)
 }
 
 func TestResolveLeague_MapsOwnerNames(t *testing.T) {
@@ -135,7 +311,7 @@ func TestResolveLeague_MapsOwnerNames(t *testing.T) {
 		"p1": {PlayerID: "p1", FullName: "Patrick Mahomes", Position: "QB", Team: "KC"},
 	}
 
-	ld := resolveLeague("l1", "Test League", rosters, users, players, nil, nil)
+	ld := resolveLeague(sleeperLeague{leagueID: "l1", leagueName: "Test League", rosters: rosters, users: users}, players, nil)
 	require.Len(t, ld.rosters, 1)
 	assert.Equal(t, "JohnFantasy", ld.rosters[0].ownerName)
 	require.Len(t, ld.rosters[0].players, 1)
@@ -147,7 +323,7 @@ func TestResolveLeague_FallbackOwnerName(t *testing.T) {
 	rosters := []sleeper.Roster{
 		{RosterID: 7, OwnerID: "unknown", Players: nil},
 	}
-	ld := resolveLeague("l1", "L", rosters, nil, nil, nil, nil)
+	ld := resolveLeague(sleeperLeague{leagueID: "l1", leagueName: "L", rosters: rosters}, nil, nil)
 	require.Len(t, ld.rosters, 1)
 	assert.Equal(t, "Team 7", ld.rosters[0].ownerName)
 }
@@ -167,7 +343,7 @@ func TestResolveLeague_CrossReferencesESPN(t *testing.T) {
 		},
 	}
 
-	ld := resolveLeague("l1", "L", rosters, users, players, nil, espnByName)
+	ld := resolveLeague(sleeperLeague{leagueID: "l1", leagueName: "L", rosters: rosters, users: users}, players, espnByName)
 	require.Len(t, ld.rosters[0].players, 1)
 	p := ld.rosters[0].players[0]
 	assert.Equal(t, "Kansas City Chiefs", p.nflTeam)
@@ -197,7 +373,7 @@ func TestResolveLeague_ResolvesCompletedTrades(t *testing.T) {
 		"p1": {FullName: "Patrick Mahomes"},
 	}
 
-	ld := resolveLeague("l1", "L", rosters, users, players, transactions, nil)
+	ld := resolveLeague(sleeperLeague{leagueID: "l1", leagueName: "L", rosters: rosters, users: users, transactions: transactions}, players, nil)
 	require.Len(t, ld.trades, 1)
 
 	// Collect all items received across sides
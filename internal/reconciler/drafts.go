@@ -0,0 +1,195 @@
+package reconciler
+
+import (
+	"crowfather/internal/sleeper"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// futureSeasons is how many upcoming drafts the future pick table covers,
+// matching the seasons Sleeper lets dynasty leagues trade.
+const futureSeasons = 3
+
+// leagueDrafts is the draft data fetched for a league.
+type leagueDrafts struct {
+	season      string // the league's season, e.g. "2025"
+	rounds      int    // rounds in each future draft; 0 if unknown
+	drafts      []sleeper.Draft
+	picks       map[string][]sleeper.DraftPick // draft_id → picks made so far
+	tradedPicks []sleeper.TradedPick
+}
+
+type resolvedDraft struct {
+	season string
+	kind   string // "snake", "linear" or "auction"
+	status string
+	picks  []resolvedDraftPick // in pick order
+}
+
+type resolvedDraftPick struct {
+	label     string // round and pick within the round, e.g. "1.03"
+	ownerName string
+	player    string
+	position  string
+	nflTeam   string
+	amount    string // auction price, if any
+}
+
+// pickHolding lists the future picks one team owns.
+type pickHolding struct {
+	ownerName string
+	picks     []string // e.g. "2026 1st", "2026 2nd (from Bob)"
+}
+
+// resolveDrafts names the owners and players of each draft's picks, newest
+// season first. Drafts that haven't started are left out.
+func resolveDrafts(ld leagueDrafts, ownerByRosterID map[int]string, playerName func(string) string) []resolvedDraft {
+	var resolved []resolvedDraft
+	for _, d := range ld.drafts {
+		picks := ld.picks[d.DraftID]
+		if d.Status == sleeper.DraftPreDraft || len(picks) == 0 {
+			continue
+		}
+		picks = append([]sleeper.DraftPick(nil), picks...)
+		sort.Slice(picks, func(i, j int) bool { return picks[i].PickNo < picks[j].PickNo })
+
+		rd := resolvedDraft{season: d.Season, kind: d.Type, status: d.Status}
+		for _, p := range picks {
+			player := playerName(p.PlayerID)
+			if player == p.PlayerID && p.Metadata.LastName != "" {
+				player = strings.TrimSpace(p.Metadata.FirstName + " " + p.Metadata.LastName)
+			}
+			rd.picks = append(rd.picks, resolvedDraftPick{
+				label:     pickLabel(p, d.Settings.Teams),
+				ownerName: ownerByRosterID[p.RosterID],
+				player:    player,
+				position:  p.Metadata.Position,
+				nflTeam:   p.Metadata.Team,
+				amount:    p.Metadata.Amount,
+			})
+		}
+		resolved = append(resolved, rd)
+	}
+
+	sort.SliceStable(resolved, func(i, j int) bool { return resolved[i].season > resolved[j].season })
+	return resolved
+}
+
+// pickLabel renders a pick as round and position within the round, e.g.
+// "2.11". The position comes from the overall pick so snake rounds read in
+// the order they were made.
+func pickLabel(p sleeper.DraftPick, teams int) string {
+	pick := p.DraftSlot
+	if teams > 0 && p.PickNo > 0 {
+		pick = (p.PickNo-1)%teams + 1
+	}
+	return fmt.Sprintf("%d.%02d", p.Round, pick)
+}
+
+// resolveFuturePicks works out who owns each upcoming draft pick: every
+// roster starts with its own pick in each round and season, and traded
+// picks move to their current owner. The upcoming drafts start with the
+// league's season unless its draft is already done.
+func resolveFuturePicks(ld leagueDrafts, rosters []sleeper.Roster, ownerByRosterID map[int]string) []pickHolding {
+	first, err := strconv.Atoi(ld.season)
+	if err != nil {
+		return nil
+	}
+	for _, d := range ld.drafts {
+		if d.Season == ld.season && d.Status == sleeper.DraftComplete {
+			first++
+			break
+		}
+	}
+
+	rounds := ld.rounds
+	seasons := make(map[int]bool)
+	for s := first; s < first+futureSeasons; s++ {
+		seasons[s] = true
+	}
+	type pickKey struct {
+		season, round, rosterID int
+	}
+	owners := make(map[pickKey]int)
+	for _, tp := range ld.tradedPicks {
+		season, err := strconv.Atoi(tp.Season)
+		if err != nil || season < first {
+			continue
+		}
+		seasons[season] = true
+		rounds = max(rounds, tp.Round)
+		owners[pickKey{season, tp.Round, tp.RosterID}] = tp.OwnerID
+	}
+	if rounds == 0 || len(rosters) == 0 {
+		return nil
+	}
+
+	ordered := make([]int, 0, len(seasons))
+	for s := range seasons {
+		ordered = append(ordered, s)
+	}
+	sort.Ints(ordered)
+
+	holdings := make([]pickHolding, len(rosters))
+	index := make(map[int]int, len(rosters))
+	for i, r := range rosters {
+		holdings[i].ownerName = ownerByRosterID[r.RosterID]
+		index[r.RosterID] = i
+	}
+	for _, season := range ordered {
+		for round := 1; round <= rounds; round++ {
+			for _, r := range rosters {
+				owner, traded := owners[pickKey{season, round, r.RosterID}]
+				if !traded {
+					owner = r.RosterID
+				}
+				i, ok := index[owner]
+				if !ok {
+					continue
+				}
+				pick := fmt.Sprintf("%d %s", season, ordinal(round))
+				if owner != r.RosterID {
+					pick += fmt.Sprintf(" (from %s)", ownerByRosterID[r.RosterID])
+				}
+				holdings[i].picks = append(holdings[i].picks, pick)
+			}
+		}
+	}
+	return holdings
+}
+
+// writeDrafts writes a board for each draft and the future pick table.
+func writeDrafts(sb *strings.Builder, ld leagueData) {
+	for _, d := range ld.drafts {
+		status := d.status
+		if status != sleeper.DraftComplete {
+			status = "in progress"
+		}
+		fmt.Fprintf(sb, "## Draft Board: %s (%s, %s)\n\n", d.season, d.kind, status)
+		sb.WriteString("| Pick | Team | Player | Position | NFL Team |\n")
+		sb.WriteString("|------|------|--------|----------|----------|\n")
+		for _, p := range d.picks {
+			player := p.player
+			if p.amount != "" {
+				player += fmt.Sprintf(" ($%s)", p.amount)
+			}
+			fmt.Fprintf(sb, "| %s | %s | %s | %s | %s |\n", p.label, p.ownerName, player, p.position, p.nflTeam)
+		}
+		sb.WriteString("\n")
+	}
+
+	if len(ld.futurePicks) == 0 {
+		return
+	}
+	sb.WriteString("## Future Draft Picks\n\n")
+	for _, h := range ld.futurePicks {
+		picks := "none"
+		if len(h.picks) > 0 {
+			picks = strings.Join(h.picks, ", ")
+		}
+		fmt.Fprintf(sb, "- %s: %s\n", h.ownerName, picks)
+	}
+	sb.WriteString("\n")
+}
@@ -0,0 +1,131 @@
+package reconciler
+
+import (
+	"context"
+	"crowfather/internal/sleeper"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveDrafts_SnakeBoard(t *testing.T) {
+	ld := leagueDrafts{
+		drafts: []sleeper.Draft{
+			{DraftID: "d1", Season: "2025", Type: "snake", Status: sleeper.DraftComplete, Settings: sleeper.DraftSettings{Teams: 2, Rounds: 2}},
+			{DraftID: "d2", Season: "2026", Status: sleeper.DraftPreDraft},
+		},
+		picks: map[string][]sleeper.DraftPick{"d1": {
+			{PlayerID: "p3", RosterID: 1, Round: 2, DraftSlot: 1, PickNo: 4},
+			{PlayerID: "p1", RosterID: 1, Round: 1, DraftSlot: 1, PickNo: 1},
+			{PlayerID: "p2", RosterID: 2, Round: 1, DraftSlot: 2, PickNo: 2},
+			{PlayerID: "p9", RosterID: 2, Round: 2, DraftSlot: 2, PickNo: 3,
+				Metadata: sleeper.DraftPickMetadata{FirstName: "Tetairoa", LastName: "McMillan", Position: "WR", Team: "CAR"}},
+		}},
+	}
+	owners := map[int]string{1: "Alice", 2: "Bob"}
+	names := map[string]string{"p1": "Ashton Jeanty", "p2": "Travis Hunter", "p3": "Omarion Hampton"}
+	playerName := func(pid string) string {
+		if name, ok := names[pid]; ok {
+			return name
+		}
+		return pid
+	}
+
+	drafts := resolveDrafts(ld, owners, playerName)
+	require.Len(t, drafts, 1, "drafts that haven't started are left out")
+	assert.Equal(t, []resolvedDraftPick{
+		{label: "1.01", ownerName: "Alice", player: "Ashton Jeanty"},
+		{label: "1.02", ownerName: "Bob", player: "Travis Hunter"},
+		{label: "2.01", ownerName: "Bob", player: "Tetairoa McMillan", position: "WR", nflTeam: "CAR"},
+		{label: "2.02", ownerName: "Alice", player: "Omarion Hampton"},
+	}, drafts[0].picks)
+}
+
+// Once this season's draft is done the table starts next season; traded
+// picks move to their owner and note whose they were.
+func TestResolveFuturePicks(t *testing.T) {
+	ld := leagueDrafts{
+		season: "2025",
+		rounds: 2,
+		drafts: []sleeper.Draft{{Season: "2025", Status: sleeper.DraftComplete}},
+		tradedPicks: []sleeper.TradedPick{
+			{Season: "2026", Round: 1, RosterID: 2, OwnerID: 1, PreviousOwnerID: 2},
+			{Season: "2025", Round: 1, RosterID: 1, OwnerID: 2}, // already drafted
+			{Season: "2029", Round: 2, RosterID: 1, OwnerID: 2},
+		},
+	}
+	rosters := []sleeper.Roster{{RosterID: 1}, {RosterID: 2}}
+	owners := map[int]string{1: "Alice", 2: "Bob"}
+
+	holdings := resolveFuturePicks(ld, rosters, owners)
+	require.Len(t, holdings, 2)
+	assert.Equal(t, "Alice", holdings[0].ownerName)
+	assert.Equal(t, []string{
+		"2026 1st", "2026 1st (from Bob)", "2026 2nd",
+		"2027 1st", "2027 2nd",
+		"2028 1st", "2028 2nd",
+		"2029 1st",
+	}, holdings[0].picks)
+	assert.Contains(t, holdings[1].picks, "2029 2nd (from Alice)")
+	assert.NotContains(t, holdings[1].picks, "2026 1st")
+}
+
+func TestResolveFuturePicks_BeforeDraft(t *testing.T) {
+	ld := leagueDrafts{season: "2025", rounds: 1, drafts: []sleeper.Draft{{Season: "2025", Status: sleeper.DraftPreDraft}}}
+	holdings := resolveFuturePicks(ld, []sleeper.Roster{{RosterID: 1}}, map[int]string{1: "Alice"})
+	require.Len(t, holdings, 1)
+	assert.Equal(t, []string{"2025 1st", "2026 1st", "2027 1st"}, holdings[0].picks)
+}
+
+// Earlier seasons' drafts are read from the leagues the league was renewed
+// from; traded picks only from the current one.
+func TestFetchDrafts_FollowsPreviousSeasons(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/league/l2024":
+			w.Write([]byte(`{"league_id": "l2024", "season": "2024", "previous_league_id": "0"}`))
+		case "/league/l2025/drafts":
+			w.Write([]byte(`[{"draft_id": "d2025", "season": "2025", "status": "complete"}]`))
+		case "/league/l2024/drafts":
+			w.Write([]byte(`[{"draft_id": "d2024", "season": "2024", "status": "complete"}]`))
+		case "/draft/d2025/picks", "/draft/d2024/picks":
+			w.Write([]byte(`[{"player_id": "p1", "roster_id": 1, "round": 1, "pick_no": 1}]`))
+		case "/league/l2025/traded_picks":
+			w.Write([]byte(`[]`))
+		default:
+			t.Errorf("unexpected request %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	r := NewReconciler(nil, sleeper.NewSleeperServiceWithBaseURL(server.URL), nil, nil, nil, nil, 2, 0, nil)
+	league := &sleeper.League{LeagueID: "l2025", Season: "2025", PreviousLeagueID: "l2024"}
+
+	ld := r.fetchDrafts(context.Background(), "l2025", league)
+	require.Len(t, ld.drafts, 2)
+	assert.Equal(t, "d2025", ld.drafts[0].DraftID)
+	assert.Equal(t, "d2024", ld.drafts[1].DraftID)
+	assert.Len(t, ld.picks["d2024"], 1)
+}
+
+func TestBuildFantasyLeagueDoc_IncludesDrafts(t *testing.T) {
+	ld := leagueData{
+		leagueName: "Test League",
+		drafts: []resolvedDraft{{season: "2025", kind: "auction", status: "drafting", picks: []resolvedDraftPick{
+			{label: "1.01", ownerName: "Alice", player: "Ashton Jeanty", position: "RB", nflTeam: "LV", amount: "45"},
+		}}},
+		futurePicks: []pickHolding{
+			{ownerName: "Alice", picks: []string{"2026 1st", "2026 1st (from Bob)"}},
+			{ownerName: "Bob"},
+		},
+	}
+
+	doc := string(buildFantasyLeagueDoc(ld))
+	assert.Contains(t, doc, "## Draft Board: 2025 (auction, in progress)\n\n")
+	assert.Contains(t, doc, "| 1.01 | Alice | Ashton Jeanty ($45) | RB | LV |\n")
+	assert.Contains(t, doc, "## Future Draft Picks\n\n- Alice: 2026 1st, 2026 1st (from Bob)\n- Bob: none\n")
+}
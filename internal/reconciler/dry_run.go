@@ -0,0 +1,269 @@
+package reconciler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ErrNotAuthorized is returned when a chat user outside the approved list
+// asks for a reconciler operation.
+var ErrNotAuthorized = errors.New("not authorized to run the reconciler")
+
+// ErrShuttingDown is returned once Shutdown has been called.
+var ErrShuttingDown = errors.New("the service is shutting down")
+
+// Document change kinds reported by a dry run.
+const (
+	DocAdded     = "added"
+	DocRemoved   = "removed"
+	DocChanged   = "changed"
+	DocUnchanged = "unchanged"
+)
+
+// DryRunResult holds the documents a run would publish and how they differ
+// from the last successful run.
+type DryRunResult struct {
+	Documents   []RunDocument  `json:"documents"`
+	Changes     []DocumentDiff `json:"changes"`
+	HasBaseline bool           `json:"has_baseline"`              // false if there was no previous run to compare against
+	BaselineRun int64          `json:"baseline_run_id,omitempty"` // run history ID of the baseline, when known
+}
+
+// DocumentDiff describes how one document differs from the baseline. Diff
+// lists removed lines prefixed "- " and added lines prefixed "+ ".
+type DocumentDiff struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Diff   string `json:"diff,omitempty"`
+}
+
+// nflDocPrefix starts the name of every NFL team document, which all
+// assistants share.
+const nflDocPrefix = "nfl_team_"
+
+// leagueDocName is the document name of a Sleeper league.
+func leagueDocName(leagueID string) string {
+	return fmt.Sprintf("fantasy_league_%s.md", leagueID)
+}
+
+// DryRun fetches league data and renders every document without creating,
+// uploading to or attaching a vector store, and without touching run history.
+// senderUserID and groupID follow TriggerForGroup: chat users must be
+// approved, and a non-empty groupID limits the run, and the comparison with
+// the last run, to that group's leagues. A dry run is refused while a run is
+// in flight and within the cooldown of the last run or dry run; it doesn't
+// hold off real runs.
+func (r *Reconciler) DryRun(ctx context.Context, senderUserID, groupID string) (*DryRunResult, error) {
+	recorded := r.historyLastRun()
+
+	r.mu.Lock()
+	lastAt := r.lastFinishedAt(recorded)
+	if r.lastDryRunAt.After(lastAt) {
+		lastAt = r.lastDryRunAt
+	}
+	if err := r.admit(senderUserID, lastAt); err != nil {
+		r.mu.Unlock()
+		return nil, err
+	}
+	r.running = true
+	r.runs.Add(1)
+	targets, transRounds := targetsForGroup(r.leagues, groupID), r.transRounds
+	r.mu.Unlock()
+
+	defer func() {
+		r.mu.Lock()
+		r.running = false
+		r.lastDryRunAt = time.Now()
+		r.mu.Unlock()
+		r.runs.Done()
+	}()
+
+	_, docsByAssistant, err := r.generate(ctx, nil, targets, transRounds)
+	if err != nil {
+		return nil, err
+	}
+
+	docs := make(map[string][]byte)
+	for _, assistantDocs := range docsByAssistant {
+		for name, content := range assistantDocs {
+			docs[name] = content
+		}
+	}
+
+	baseline, baselineRun, err := r.previousDocs(ctx)
+	if err != nil {
+		fmt.Printf("reconciler: dry run has no baseline: %v\n", err)
+	}
+
+	if baseline != nil && groupID != "" {
+		baseline = scopeDocs(baseline, targets)
+	}
+
+	result := &DryRunResult{HasBaseline: baseline != nil, BaselineRun: baselineRun}
+	for _, name := range sortedNames(docs) {
+		result.Documents = append(result.Documents, RunDocument{Name: name, Content: string(docs[name])})
+	}
+	if baseline != nil {
+		result.Changes = diffDocuments(baseline, docs)
+	}
+	return result, nil
+}
+
+// Summary is a short chat-sized description of the dry run.
+func (d *DryRunResult) Summary() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Dry run: %d documents generated, nothing uploaded.\n", len(d.Documents))
+	if !d.HasBaseline {
+		sb.WriteString("No previous run to compare against.")
+		return sb.String()
+	}
+
+	counts := make(map[string][]string)
+	for _, c := range d.Changes {
+		counts[c.Status] = append(counts[c.Status], c.Name)
+	}
+	if len(counts[DocAdded])+len(counts[DocRemoved])+len(counts[DocChanged]) == 0 {
+		sb.WriteString("No changes since the last refresh.")
+		return sb.String()
+	}
+	for _, status := range []string{DocChanged, DocAdded, DocRemoved} {
+		if names := counts[status]; len(names) > 0 {
+			fmt.Fprintf(&sb, "%s (%d): %s\n", strings.ToUpper(status[:1])+status[1:], len(names), strings.Join(names, ", "))
+		}
+	}
+	return strings.TrimSpace(sb.String())
+}
+
+// previousDocs returns the documents of the last successful run, from memory
+// when this process has published, otherwise from run history.
+func (r *Reconciler) previousDocs(ctx context.Context) (map[string][]byte, int64, error) {
+	r.mu.Lock()
+	docs, runID := r.lastDocs, r.lastDocsRun
+	r.mu.Unlock()
+	history := r.runHistory()
+	if docs != nil || history == nil {
+		return docs, runID, nil
+	}
+
+	runs, err := history.ListRuns(ctx, 20)
+	if err != nil {
+		return nil, 0, err
+	}
+	for _, run := range runs {
+		if run.Status != StatusSucceeded {
+			continue
+		}
+		stored, err := history.ListRunDocuments(ctx, run.ID)
+		if err != nil {
+			return nil, 0, err
+		}
+		docs := make(map[string][]byte, len(stored))
+		for _, d := range stored {
+			docs[d.Name] = []byte(d.Content)
+		}
+		return docs, run.ID, nil
+	}
+	return nil, 0, nil
+}
+
+// scopeDocs keeps the shared NFL documents and the documents of targets'
+// leagues, so a group's dry run isn't compared against other leagues.
+func scopeDocs(docs map[string][]byte, targets []LeagueTarget) map[string][]byte {
+	keep := make(map[string]bool, len(targets))
+	for _, t := range targets {
+		keep[leagueDocName(t.LeagueID)] = true
+	}
+
+	scoped := make(map[string][]byte)
+	for name, content := range docs {
+		if keep[name] || strings.HasPrefix(name, nflDocPrefix) {
+			scoped[name] = content
+		}
+	}
+	return scoped
+}
+
+// diffDocuments compares two document sets by name.
+func diffDocuments(old, new map[string][]byte) []DocumentDiff {
+	names := make(map[string]bool, len(old)+len(new))
+	for name := range old {
+		names[name] = true
+	}
+	for name := range new {
+		names[name] = true
+	}
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+
+	diffs := make([]DocumentDiff, 0, len(sorted))
+	for _, name := range sorted {
+		before, hadOld := old[name]
+		after, hasNew := new[name]
+		switch {
+		case !hadOld:
+			diffs = append(diffs, DocumentDiff{Name: name, Status: DocAdded})
+		case !hasNew:
+			diffs = append(diffs, DocumentDiff{Name: name, Status: DocRemoved})
+		case string(before) == string(after):
+			diffs = append(diffs, DocumentDiff{Name: name, Status: DocUnchanged})
+		default:
+			diffs = append(diffs, DocumentDiff{Name: name, Status: DocChanged, Diff: lineDiff(string(before), string(after))})
+		}
+	}
+	return diffs
+}
+
+// lineDiff lists the lines removed from and added to old to produce new,
+// in document order, using a longest-common-subsequence alignment.
+func lineDiff(old, new string) string {
+	a := strings.Split(old, "\n")
+	b := strings.Split(new, "\n")
+
+	// lcs[i][j] is the LCS length of a[i:] and b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var sb strings.Builder
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			i++
+			j++
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			fmt.Fprintf(&sb, "- %s\n", a[i])
+			i++
+		default:
+			fmt.Fprintf(&sb, "+ %s\n", b[j])
+			j++
+		}
+	}
+	return sb.String()
+}
+
+func sortedNames(docs map[string][]byte) []string {
+	names := make([]string, 0, len(docs))
+	for name := range docs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,126 @@
+package reconciler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLineDiff(t *testing.T) {
+	old := "# Roster\nAllen\nDiggs\nKnox"
+	new := "# Roster\nAllen\nCooper\nKnox\nKincaid"
+	assert.Equal(t, "- Diggs\n+ Cooper\n+ Kincaid\n", lineDiff(old, new))
+	assert.Empty(t, lineDiff(old, old))
+}
+
+func TestDiffDocuments(t *testing.T) {
+	old := map[string][]byte{
+		"a.md": []byte("same"),
+		"b.md": []byte("before"),
+		"c.md": []byte("gone"),
+	}
+	new := map[string][]byte{
+		"a.md": []byte("same"),
+		"b.md": []byte("after"),
+		"d.md": []byte("fresh"),
+	}
+
+	diffs := diffDocuments(old, new)
+	require.Len(t, diffs, 4)
+	assert.Equal(t, DocumentDiff{Name: "a.md", Status: DocUnchanged}, diffs[0])
+	assert.Equal(t, DocumentDiff{Name: "b.md", Status: DocChanged, Diff: "- before\n+ after\n"}, diffs[1])
+	assert.Equal(t, DocumentDiff{Name: "c.md", Status: DocRemoved}, diffs[2])
+	assert.Equal(t, DocumentDiff{Name: "d.md", Status: DocAdded}, diffs[3])
+}
+
+func TestPreviousDocs_UsesLastSucceededRunFromHistory(t *testing.T) {
+	repo := &memRunRepo{}
+	ctx := context.Background()
+	okID, _ := repo.CreateRun(ctx, RunRecord{Status: StatusSucceeded})
+	_, _ = repo.CreateRun(ctx, RunRecord{Status: StatusFailed})
+	_ = repo.SaveRunDocuments(ctx, okID, []RunDocument{{Name: "a.md", Content: "hello"}})
+
+	r := NewReconciler(nil, nil, nil, nil, repo, nil, 2, 0, nil)
+	docs, runID, err := r.previousDocs(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, okID, runID)
+	assert.Equal(t, map[string][]byte{"a.md": []byte("hello")}, docs)
+
+	// A run finished by this process takes precedence over history.
+	tracker := r.startRun(SourceManual)
+	tracker.addDocuments(map[string][]byte{"b.md": []byte("newer")})
+	tracker.finish("done", nil)
+
+	docs, runID, err = r.previousDocs(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), runID)
+	assert.Equal(t, map[string][]byte{"b.md": []byte("newer")}, docs)
+}
+
+func TestDryRun_RejectsUnapprovedUser(t *testing.T) {
+	r := NewReconciler(nil, nil, nil, nil, nil, nil, 2, 0, []string{"approved"})
+	_, err := r.DryRun(context.Background(), "someone-else", "")
+	assert.ErrorIs(t, err, ErrNotAuthorized)
+}
+
+// Dry runs share the trigger's in-flight guard and have a cooldown of their own.
+func TestDryRun_GuardAndCooldown(t *testing.T) {
+	r := NewReconciler(nil, nil, nil, nil, nil, nil, 2, time.Hour, nil)
+
+	r.running = true
+	_, err := r.DryRun(context.Background(), "", "")
+	assert.ErrorIs(t, err, ErrRunning)
+
+	r.running = false
+	r.lastDryRunAt = time.Now()
+	_, err = r.DryRun(context.Background(), "", "")
+	var cooldown *CooldownError
+	require.ErrorAs(t, err, &cooldown)
+	assert.Equal(t, time.Hour, cooldown.Remaining)
+
+	// A dry run doesn't hold off a real refresh.
+	assert.True(t, r.lastFinishedAt(nil).IsZero())
+}
+
+// A group's dry run is only compared with its own league documents and the
+// shared NFL documents.
+func TestScopeDocs(t *testing.T) {
+	baseline := map[string][]byte{
+		"nfl_team_1.md":       []byte("team"),
+		"fantasy_league_1.md": []byte("mine"),
+		"fantasy_league_2.md": []byte("theirs"),
+	}
+	scoped := scopeDocs(baseline, []LeagueTarget{{LeagueID: "1", GroupID: "g1"}})
+	assert.Equal(t, []string{"fantasy_league_1.md", "nfl_team_1.md"}, sortedNames(scoped))
+}
+
+func TestTargetsForGroup(t *testing.T) {
+	targets := []LeagueTarget{
+		{LeagueID: "1", GroupID: "g1"},
+		{LeagueID: "2"},
+	}
+	assert.Equal(t, targets, targetsForGroup(targets, ""))
+	assert.Equal(t, []LeagueTarget{{LeagueID: "1", GroupID: "g1"}}, targetsForGroup(targets, "g1"))
+	assert.Equal(t, []LeagueTarget{{LeagueID: "2"}}, targetsForGroup(targets, "g2"))
+}
+
+func TestDryRunResult_Summary(t *testing.T) {
+	result := &DryRunResult{
+		Documents:   []RunDocument{{Name: "a.md"}, {Name: "b.md"}},
+		HasBaseline: true,
+		Changes: []DocumentDiff{
+			{Name: "a.md", Status: DocChanged},
+			{Name: "b.md", Status: DocAdded},
+		},
+	}
+	assert.Equal(t, "Dry run: 2 documents generated, nothing uploaded.\nChanged (1): a.md\nAdded (1): b.md", result.Summary())
+
+	result.Changes = []DocumentDiff{{Name: "a.md", Status: DocUnchanged}}
+	assert.Contains(t, result.Summary(), "No changes since the last refresh.")
+
+	result.HasBaseline = false
+	assert.Contains(t, result.Summary(), "No previous run to compare against.")
+}
@@ -0,0 +1,306 @@
+package reconciler
+
+import (
+	"context"
+	"crowfather/internal/records"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Trigger sources recorded in run history for runs not started from chat.
+// Chat-triggered runs are recorded as "groupme:<user_id>".
+const (
+	SourceStartup = "startup"
+	SourceCron    = "cron"
+	SourceHTTP    = "http"
+	SourceManual  = "manual"
+	SourceCLI     = "cli"
+)
+
+// Run and step statuses.
+const (
+	StatusRunning   = "running"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+)
+
+// historyTimeout bounds each run history read or write so a slow database
+// never holds up a trigger.
+const historyTimeout = 5 * time.Second
+
+// Run history rows, defined in records so the database package can store
+// them without importing the reconciler.
+type (
+	RunRecord   = records.RunRecord
+	StepRecord  = records.StepRecord
+	RunDocument = records.RunDocument
+)
+
+// RunRepository is the persistence contract for reconciliation run history.
+// The concrete implementation lives in the database package.
+type RunRepository interface {
+	CreateRun(ctx context.Context, run RunRecord) (int64, error)
+	UpdateRun(ctx context.Context, run RunRecord) error
+	LastRun(ctx context.Context) (*RunRecord, error)              // nil if there are no runs
+	ListRuns(ctx context.Context, limit int) ([]RunRecord, error) // newest first
+	GetRun(ctx context.Context, id int64) (*RunRecord, error)     // nil if not found
+	SaveRunDocuments(ctx context.Context, runID int64, docs []RunDocument) error
+	ListRunDocuments(ctx context.Context, runID int64) ([]RunDocument, error)
+}
+
+// ErrNoHistory is returned when run history is requested but no RunRepository
+// is configured.
+var ErrNoHistory = errors.New("run history requires a database")
+
+// ErrRunNotFound is returned by Run for an unknown run ID.
+var ErrRunNotFound = errors.New("reconcile run not found")
+
+// Status is a snapshot of the reconciler for operators.
+type Status struct {
+	Running           bool
+	CurrentStep       string // empty when idle
+	CooldownRemaining time.Duration
+	LastRun           *RunRecord // nil if no run has been recorded
+}
+
+// runTracker records the progress of a single run, mirroring it to the
+// reconciler's in-memory state and to run history when configured.
+type runTracker struct {
+	r       *Reconciler
+	history RunRepository // as of the start of the run
+	record  RunRecord
+	docs    map[string][]byte // every generated document by name
+}
+
+// startRun creates the history entry for a run triggered by source.
+func (r *Reconciler) startRun(source string) *runTracker {
+	t := &runTracker{r: r, history: r.runHistory(), docs: make(map[string][]byte), record: RunRecord{
+		Trigger:        source,
+		Status:         StatusRunning,
+		StartedAt:      time.Now().UTC(),
+		VectorStoreIDs: make(map[string]string),
+	}}
+
+	if t.history != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), historyTimeout)
+		defer cancel()
+		id, err := t.history.CreateRun(ctx, t.record)
+		if err != nil {
+			fmt.Printf("reconciler: failed to record run start: %v\n", err)
+		}
+		t.record.ID = id
+	}
+	t.publish()
+	return t
+}
+
+// step marks the start of a named stage and returns the func that ends it.
+// A nil tracker records nothing.
+func (t *runTracker) step(name string) func(error) {
+	if t == nil {
+		return func(error) {}
+	}
+	t.record.Steps = append(t.record.Steps, StepRecord{
+		Name:      name,
+		Status:    StatusRunning,
+		StartedAt: time.Now().UTC(),
+	})
+	i := len(t.record.Steps) - 1
+	t.save()
+
+	return func(err error) {
+		s := &t.record.Steps[i]
+		s.FinishedAt = time.Now().UTC()
+		s.Status = StatusSucceeded
+		if err != nil {
+			s.Status = StatusFailed
+			s.Error = err.Error()
+		}
+		t.save()
+	}
+}
+
+// addDocuments records documents generated for the run.
+func (t *runTracker) addDocuments(docs map[string][]byte) {
+	if t == nil {
+		return
+	}
+	names := make([]string, 0, len(docs))
+	for name, content := range docs {
+		names = append(names, name)
+		t.docs[name] = content
+	}
+	sort.Strings(names)
+	t.record.Documents = append(t.record.Documents, names...)
+}
+
+// finish records the run's outcome.
+func (t *runTracker) finish(summary string, err error) {
+	t.record.FinishedAt = time.Now().UTC()
+	t.record.Summary = summary
+	t.record.Status = StatusSucceeded
+	if err != nil {
+		t.record.Status = StatusFailed
+		t.record.Error = err.Error()
+	}
+	// A step still open here was interrupted, e.g. by a panic.
+	for i := range t.record.Steps {
+		if t.record.Steps[i].Status == StatusRunning {
+			t.record.Steps[i].Status = StatusFailed
+			t.record.Steps[i].FinishedAt = t.record.FinishedAt
+		}
+	}
+	t.saveDocuments()
+	t.save()
+
+	if err == nil {
+		t.r.mu.Lock()
+		t.r.lastDocs, t.r.lastDocsRun = t.docs, t.record.ID
+		t.r.mu.Unlock()
+	}
+}
+
+// saveDocuments stores the generated documents alongside the run.
+func (t *runTracker) saveDocuments() {
+	if t.history == nil || t.record.ID == 0 || len(t.docs) == 0 {
+		return
+	}
+	docs := make([]RunDocument, 0, len(t.docs))
+	for _, name := range t.record.Documents {
+		docs = append(docs, RunDocument{Name: name, Content: string(t.docs[name])})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), historyTimeout)
+	defer cancel()
+	if err := t.history.SaveRunDocuments(ctx, t.record.ID, docs); err != nil {
+		fmt.Printf("reconciler: failed to record documents for run %d: %v\n", t.record.ID, err)
+	}
+}
+
+func (t *runTracker) save() {
+	t.publish()
+	if t.history == nil || t.record.ID == 0 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), historyTimeout)
+	defer cancel()
+	if err := t.history.UpdateRun(ctx, t.record); err != nil {
+		fmt.Printf("reconciler: failed to record run %d: %v\n", t.record.ID, err)
+	}
+}
+
+// publish copies the record into the reconciler so the latest state is
+// available without a database.
+func (t *runTracker) publish() {
+	record := t.record
+	record.Steps = append([]StepRecord(nil), t.record.Steps...)
+	record.Documents = append([]string(nil), t.record.Documents...)
+	record.VectorStoreIDs = make(map[string]string, len(t.record.VectorStoreIDs))
+	for k, v := range t.record.VectorStoreIDs {
+		record.VectorStoreIDs[k] = v
+	}
+
+	t.r.mu.Lock()
+	defer t.r.mu.Unlock()
+	t.r.lastRun = &record
+}
+
+// historyLastRun returns the most recent run in history, or nil without
+// history or if it can't be read. It queries the database, so call it
+// before taking r.mu.
+func (r *Reconciler) historyLastRun() *RunRecord {
+	history := r.runHistory()
+	if history == nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), historyTimeout)
+	defer cancel()
+	last, err := history.LastRun(ctx)
+	if err != nil {
+		fmt.Printf("reconciler: failed to read run history, using in-memory cooldown: %v\n", err)
+		return nil
+	}
+	return last
+}
+
+// lastFinishedAt returns when the most recent run ended, preferring last,
+// the run read from history, so cooldowns survive restarts and count runs
+// from the CLI. Must be called with r.mu held.
+func (r *Reconciler) lastFinishedAt(last *RunRecord) time.Time {
+	if last == nil {
+		return r.lastRunAt
+	}
+
+	finished := last.FinishedAt
+	if finished.IsZero() {
+		// A run left unfinished by a restart still counts from its start.
+		finished = last.StartedAt
+	}
+	if finished.After(r.lastRunAt) {
+		return finished
+	}
+	return r.lastRunAt
+}
+
+// Status reports whether a run is in progress, its current step, how long
+// until the cooldown allows another run, and the most recent run.
+func (r *Reconciler) Status() Status {
+	recorded := r.historyLastRun()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	status := Status{Running: r.running}
+	if last := r.lastFinishedAt(recorded); !last.IsZero() {
+		if remaining := r.cooldown - time.Since(last); remaining > 0 {
+			status.CooldownRemaining = remaining
+		}
+	}
+
+	if r.lastRun != nil {
+		run := *r.lastRun
+		status.LastRun = &run
+		if r.running {
+			for _, s := range run.Steps {
+				if s.Status == StatusRunning {
+					status.CurrentStep = s.Name
+				}
+			}
+		}
+	} else {
+		status.LastRun = recorded
+	}
+	return status
+}
+
+// Runs returns up to limit runs from history, newest first.
+func (r *Reconciler) Runs(ctx context.Context, limit int) ([]RunRecord, error) {
+	history := r.runHistory()
+	if history == nil {
+		return nil, ErrNoHistory
+	}
+	return history.ListRuns(ctx, limit)
+}
+
+// Run returns one run from history with the documents it generated.
+func (r *Reconciler) Run(ctx context.Context, id int64) (*RunRecord, []RunDocument, error) {
+	history := r.runHistory()
+	if history == nil {
+		return nil, nil, ErrNoHistory
+	}
+	run, err := history.GetRun(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if run == nil {
+		return nil, nil, ErrRunNotFound
+	}
+	docs, err := history.ListRunDocuments(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	return run, docs, nil
+}
@@ -0,0 +1,247 @@
+package reconciler
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memRunRepo struct {
+	mu   sync.Mutex
+	runs []RunRecord
+	docs map[int64][]RunDocument
+}
+
+func (m *memRunRepo) CreateRun(_ context.Context, run RunRecord) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	run.ID = int64(len(m.runs) + 1)
+	m.runs = append(m.runs, run)
+	return run.ID, nil
+}
+
+func (m *memRunRepo) UpdateRun(_ context.Context, run RunRecord) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.runs[run.ID-1] = run
+	return nil
+}
+
+func (m *memRunRepo) LastRun(context.Context) (*RunRecord, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.runs) == 0 {
+		return nil, nil
+	}
+	run := m.runs[len(m.runs)-1]
+	return &run, nil
+}
+
+func (m *memRunRepo) ListRuns(_ context.Context, limit int) ([]RunRecord, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var runs []RunRecord
+	for i := len(m.runs) - 1; i >= 0 && len(runs) < limit; i-- {
+		runs = append(runs, m.runs[i])
+	}
+	return runs, nil
+}
+
+func (m *memRunRepo) GetRun(_ context.Context, id int64) (*RunRecord, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if id < 1 || int(id) > len(m.runs) {
+		return nil, nil
+	}
+	run := m.runs[id-1]
+	return &run, nil
+}
+
+func (m *memRunRepo) SaveRunDocuments(_ context.Context, runID int64, docs []RunDocument) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.docs == nil {
+		m.docs = make(map[int64][]RunDocument)
+	}
+	m.docs[runID] = docs
+	return nil
+}
+
+func (m *memRunRepo) ListRunDocuments(_ context.Context, runID int64) ([]RunDocument, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.docs[runID], nil
+}
+
+func TestRunHistory_RecordsFailedRun(t *testing.T) {
+	repo := &memRunRepo{}
+	r := &Reconciler{runState: &runState{history: repo}}
+
+	done := make(chan string, 1)
+	triggered, _ := r.TriggerFrom(SourceHTTP, func(s string) { done <- s })
+	require.True(t, triggered)
+	<-done
+
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	require.Len(t, repo.runs, 1)
+	run := repo.runs[0]
+	assert.Equal(t, SourceHTTP, run.Trigger)
+	assert.Equal(t, StatusFailed, run.Status)
+	assert.NotEmpty(t, run.Error)
+	assert.False(t, run.FinishedAt.IsZero())
+	require.NotEmpty(t, run.Steps)
+	assert.Equal(t, "espn_rosters", run.Steps[0].Name)
+	assert.Equal(t, StatusFailed, run.Steps[0].Status)
+}
+
+func TestRunHistory_ChatTriggerRecordsUser(t *testing.T) {
+	repo := &memRunRepo{}
+	r := &Reconciler{runState: &runState{history: repo}}
+
+	done := make(chan string, 1)
+	triggered, _ := r.TriggerForGroup("user42", "g1", func(s string) { done <- s })
+	require.True(t, triggered)
+	<-done
+
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	assert.Equal(t, "groupme:user42", repo.runs[0].Trigger)
+}
+
+func TestTrigger_CooldownFromHistory(t *testing.T) {
+	repo := &memRunRepo{runs: []RunRecord{{
+		ID:         1,
+		Status:     StatusSucceeded,
+		StartedAt:  time.Now().Add(-2 * time.Minute),
+		FinishedAt: time.Now().Add(-time.Minute),
+	}}}
+	// A fresh process: nothing in memory, but history says a run just ended.
+	r := &Reconciler{cooldown: time.Hour, runState: &runState{history: repo}}
+
+	triggered, reason := r.TriggerFrom(SourceCron, nil)
+	assert.False(t, triggered)
+	assert.Contains(t, reason, "just refreshed")
+}
+
+// lockCheckRepo fails the test if run history is read with r.mu held.
+type lockCheckRepo struct {
+	*memRunRepo
+	t *testing.T
+	r *Reconciler
+}
+
+func (m *lockCheckRepo) LastRun(ctx context.Context) (*RunRecord, error) {
+	if !m.r.mu.TryLock() {
+		m.t.Error("run history read while holding r.mu")
+	} else {
+		m.r.mu.Unlock()
+	}
+	return m.memRunRepo.LastRun(ctx)
+}
+
+func TestTrigger_ReadsHistoryWithoutLock(t *testing.T) {
+	r := &Reconciler{cooldown: time.Hour, runState: &runState{}}
+	r.history = &lockCheckRepo{memRunRepo: &memRunRepo{}, t: t, r: r}
+
+	done := make(chan string, 1)
+	triggered, _ := r.TriggerFrom(SourceCron, func(s string) { done <- s })
+	require.True(t, triggered)
+	<-done
+
+	assert.Greater(t, r.Status().CooldownRemaining, time.Duration(0))
+	triggered, reason := r.TriggerFrom(SourceCron, nil)
+	assert.False(t, triggered)
+	assert.Contains(t, reason, "just refreshed")
+}
+
+func TestRunTracker_PublishesLastRun(t *testing.T) {
+	r := &Reconciler{runState: &runState{}}
+	tracker := r.startRun(SourceManual)
+	end := tracker.step("espn_rosters")
+
+	r.mu.Lock()
+	assert.Equal(t, StatusRunning, r.lastRun.Status)
+	assert.Equal(t, "espn_rosters", r.lastRun.Steps[0].Name)
+	r.mu.Unlock()
+
+	end(nil)
+	tracker.addDocuments(map[string][]byte{"b.md": nil, "a.md": nil})
+	tracker.finish("done", nil)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	assert.Equal(t, StatusSucceeded, r.lastRun.Status)
+	assert.Equal(t, StatusSucceeded, r.lastRun.Steps[0].Status)
+	assert.Equal(t, []string{"a.md", "b.md"}, r.lastRun.Documents)
+	assert.Equal(t, "done", r.lastRun.Summary)
+}
+
+func TestRunTracker_SavesDocuments(t *testing.T) {
+	repo := &memRunRepo{}
+	r := &Reconciler{runState: &runState{history: repo}}
+	tracker := r.startRun(SourceManual)
+	tracker.addDocuments(map[string][]byte{"nfl_team_1.md": []byte("# Team")})
+	tracker.finish("done", nil)
+
+	run, docs, err := r.Run(context.Background(), tracker.record.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusSucceeded, run.Status)
+	assert.Equal(t, []RunDocument{{Name: "nfl_team_1.md", Content: "# Team"}}, docs)
+
+	_, _, err = r.Run(context.Background(), 99)
+	assert.ErrorIs(t, err, ErrRunNotFound)
+}
+
+func TestRuns_WithoutHistory(t *testing.T) {
+	r := &Reconciler{runState: &runState{}}
+	_, err := r.Runs(context.Background(), 10)
+	assert.ErrorIs(t, err, ErrNoHistory)
+}
+
+func TestStatus_ReportsCurrentStepAndCooldown(t *testing.T) {
+	r := &Reconciler{cooldown: time.Hour, runState: &runState{}}
+	assert.Nil(t, r.Status().LastRun)
+
+	tracker := r.startRun(SourceManual)
+	tracker.step("sleeper_players")
+	r.mu.Lock()
+	r.running = true
+	r.mu.Unlock()
+
+	status := r.Status()
+	assert.True(t, status.Running)
+	assert.Equal(t, "sleeper_players", status.CurrentStep)
+
+	tracker.finish("", nil)
+	r.mu.Lock()
+	r.running = false
+	r.lastRunAt = time.Now()
+	r.mu.Unlock()
+
+	status = r.Status()
+	assert.False(t, status.Running)
+	assert.Empty(t, status.CurrentStep)
+	assert.Greater(t, status.CooldownRemaining, 59*time.Minute)
+	assert.Equal(t, StatusSucceeded, status.LastRun.Status)
+}
+
+func TestSetRepositories_EnablesHistory(t *testing.T) {
+	r := NewReconciler(nil, nil, nil, nil, nil, nil, 2, 0, nil)
+	_, err := r.Runs(context.Background(), 10)
+	assert.ErrorIs(t, err, ErrNoHistory)
+
+	repo := &memRunRepo{}
+	r.SetRepositories(&memMetadata{}, repo)
+	tracker := r.startRun(SourceManual)
+	tracker.finish("done", nil)
+
+	runs, err := r.Runs(context.Background(), 10)
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+	assert.Equal(t, StatusSucceeded, runs[0].Status)
+}
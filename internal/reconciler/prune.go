@@ -0,0 +1,125 @@
+package reconciler
+
+import (
+	"context"
+	"crowfather/internal/open_ai"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrNoMetadata is returned when an operation needs the vector store IDs
+// recorded in metadata but no MetadataRepository is configured.
+var ErrNoMetadata = errors.New("vector store metadata requires a database")
+
+// VectorStoreIDs returns the vector store recorded for each configured
+// assistant. Assistants without a store yet are omitted.
+func (r *Reconciler) VectorStoreIDs(ctx context.Context) (map[string]string, error) {
+	db := r.metadata()
+	if db == nil {
+		return nil, ErrNoMetadata
+	}
+
+	r.mu.Lock()
+	assistants := assistantIDs(r.leagues)
+	r.mu.Unlock()
+
+	ids := make(map[string]string, len(assistants))
+	for _, assistantID := range assistants {
+		vsID, err := db.GetMetadata(ctx, vectorStoreKey(assistantID))
+		if err != nil {
+			return nil, err
+		}
+		if vsID != "" {
+			ids[assistantID] = vsID
+		}
+	}
+	return ids, nil
+}
+
+// pruneRunScan is how many recent runs PruneVectorStores searches for the
+// newest finished one.
+const pruneRunScan = 50
+
+// PruneVectorStores deletes vector stores created by the reconciler that no
+// configured assistant uses any more, such as stores left behind by a failed
+// run or a removed league binding, and the files in them. Stores not named by
+// the reconciler are never touched, nor are stores created after the newest
+// finished run in run history: a run still in progress, here or in a running
+// server, has not attached its stores yet. With dryRun set nothing is
+// deleted. It returns the stores that were, or would be, deleted.
+func (r *Reconciler) PruneVectorStores(ctx context.Context, dryRun bool) ([]open_ai.VectorStoreInfo, error) {
+	inUse, err := r.VectorStoreIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	keep := make(map[string]bool, len(inUse)+1)
+	for _, vsID := range inUse {
+		keep[vsID] = true
+	}
+	// A store from before per-assistant stores may still be attached.
+	if legacy, err := r.metadata().GetMetadata(ctx, vectorStoreIDKey); err == nil && legacy != "" {
+		keep[legacy] = true
+	}
+	cutoff, err := r.lastFinishedRunAt(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	stores, err := r.oai.ListVectorStores(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var pruned []open_ai.VectorStoreInfo
+	for _, vs := range stores {
+		if keep[vs.ID] || !isReconcilerStore(vs.Name) || !vs.CreatedAt.Before(cutoff) {
+			continue
+		}
+		if !dryRun {
+			if err := r.pruneStore(ctx, vs.ID); err != nil {
+				return pruned, err
+			}
+			fmt.Printf("reconciler: pruned vector store %s (%s)\n", vs.ID, vs.Name)
+		}
+		pruned = append(pruned, vs)
+	}
+	return pruned, nil
+}
+
+// lastFinishedRunAt returns when the newest finished run in run history
+// ended, or the zero time if none has.
+func (r *Reconciler) lastFinishedRunAt(ctx context.Context) (time.Time, error) {
+	history := r.runHistory()
+	if history == nil {
+		return time.Time{}, ErrNoHistory
+	}
+	runs, err := history.ListRuns(ctx, pruneRunScan)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to read run history: %w", err)
+	}
+	for _, run := range runs {
+		if !run.FinishedAt.IsZero() {
+			return run.FinishedAt, nil
+		}
+	}
+	return time.Time{}, nil
+}
+
+// pruneStore deletes a vector store and then the files that were in it,
+// which deleting the store leaves behind.
+func (r *Reconciler) pruneStore(ctx context.Context, vsID string) error {
+	fileIDs, err := r.oai.VectorStoreFileIDs(ctx, vsID)
+	if err != nil {
+		return err
+	}
+	if err := r.oai.DeleteVectorStore(ctx, vsID); err != nil {
+		return err
+	}
+	return r.oai.DeleteFiles(ctx, fileIDs)
+}
+
+func isReconcilerStore(name string) bool {
+	return name == vectorStoreName || strings.HasPrefix(name, vectorStoreName+"-")
+}
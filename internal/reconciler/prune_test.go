@@ -0,0 +1,58 @@
+package reconciler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newPruneTestReconciler(t *testing.T, finishedAt time.Time) (*Reconciler, *fakeVectorStoreAPI) {
+	r, api, meta := newPublishTestReconciler(t)
+	r.leagues = []LeagueTarget{{LeagueID: "1", AssistantID: "asst_1"}}
+	r.history = &memRunRepo{runs: []RunRecord{{ID: 1, Status: StatusSucceeded, FinishedAt: finishedAt}}}
+	ctx := context.Background()
+	require.NoError(t, meta.SetMetadata(ctx, vectorStoreKey("asst_1"), "vs_current"))
+	require.NoError(t, meta.SetMetadata(ctx, vectorStoreIDKey, "vs_legacy"))
+	return r, api
+}
+
+func TestPruneVectorStores(t *testing.T) {
+	r, api := newPruneTestReconciler(t, time.Now())
+	ctx := context.Background()
+
+	pruned, err := r.PruneVectorStores(ctx, true)
+	require.NoError(t, err)
+	require.Len(t, pruned, 1)
+	assert.Equal(t, "vs_orphan", pruned[0].ID)
+	assert.Zero(t, api.count("DELETE"), "a dry run deletes nothing")
+
+	pruned, err = r.PruneVectorStores(ctx, false)
+	require.NoError(t, err)
+	require.Len(t, pruned, 1)
+	assert.Equal(t, 1, api.count("DELETE /vector_stores/vs_orphan"))
+	assert.Equal(t, 1, api.count("DELETE /files/file-a"), "the store's files are deleted too")
+	assert.Equal(t, 2, api.count("DELETE"))
+}
+
+// A store newer than the last finished run may belong to a run still in
+// progress, which attaches it only at the end.
+func TestPruneVectorStores_SkipsStoresOfUnfinishedRuns(t *testing.T) {
+	finished := time.Now().Add(-time.Hour)
+	r, api := newPruneTestReconciler(t, finished)
+	api.orphanAt = time.Now().Unix()
+	r.history.(*memRunRepo).runs = append(r.history.(*memRunRepo).runs, RunRecord{ID: 2, Status: StatusRunning, StartedAt: finished.Add(time.Minute)})
+
+	pruned, err := r.PruneVectorStores(context.Background(), false)
+	require.NoError(t, err)
+	assert.Empty(t, pruned)
+	assert.Zero(t, api.count("DELETE"))
+}
+
+func TestPruneVectorStores_RequiresMetadata(t *testing.T) {
+	r := NewReconciler(nil, nil, nil, nil, nil, nil, 2, 0, nil)
+	_, err := r.PruneVectorStores(context.Background(), true)
+	assert.ErrorIs(t, err, ErrNoMetadata)
+}
@@ -5,7 +5,9 @@ import (
 	"crowfather/internal/espn"
 	"crowfather/internal/open_ai"
 	"crowfather/internal/sleeper"
+	"errors"
 	"fmt"
+	"strings"
 	"sync"
 	"time"
 )
@@ -20,22 +22,82 @@ type MetadataRepository interface {
 	SetMetadata(ctx context.Context, key, value string) error
 }
 
+// LeagueTarget binds a Sleeper league to the GroupMe group that follows it and
+// the assistant whose vector store receives its documents. Leagues sharing an
+// assistant share one vector store; GroupID is empty for unbound leagues.
+type LeagueTarget struct {
+	LeagueID    string
+	GroupID     string
+	AssistantID string
+}
+
+// ValidateTargets rejects targets that would let one group read another's
+// leagues. An assistant has a single vector store holding all its leagues,
+// so leagues sharing an assistant must follow the same group, or all be
+// unbound.
+func ValidateTargets(targets []LeagueTarget) error {
+	first := make(map[string]LeagueTarget)
+	var conflicts []string
+	for _, t := range targets {
+		prev, seen := first[t.AssistantID]
+		if !seen {
+			first[t.AssistantID] = t
+			continue
+		}
+		if prev.GroupID != t.GroupID {
+			conflicts = append(conflicts, fmt.Sprintf("league %s (%s) and league %s (%s) both use assistant %q",
+				prev.LeagueID, groupLabel(prev.GroupID), t.LeagueID, groupLabel(t.GroupID), t.AssistantID))
+		}
+	}
+	if len(conflicts) > 0 {
+		return fmt.Errorf("leagues followed by different GroupMe groups need their own assistants: %s", strings.Join(conflicts, "; "))
+	}
+	return nil
+}
+
+func groupLabel(groupID string) string {
+	if groupID == "" {
+		return "unbound"
+	}
+	return "group " + groupID
+}
+
 // Reconciler orchestrates fetching ESPN + Sleeper data, generating documents,
-// and uploading them to an OpenAI vector store.
+// and uploading them to an OpenAI vector store per assistant.
 type Reconciler struct {
 	espn          *espn.ESPNService
 	sleeper       *sleeper.SleeperService
 	oai           *open_ai.OpenAIService
-	db            MetadataRepository
-	leagueIDs     []string
-	assistantID   string
+	leagues       []LeagueTarget
 	transRounds   int
 	approvedUsers map[string]bool
+	cooldown      time.Duration
+
+	notifyGroup func(groupID, summary string) // posts scheduled run summaries; nil to skip
 
-	mu        sync.Mutex
-	running   bool
-	lastRunAt time.Time
-	cooldown  time.Duration
+	*runState
+}
+
+// runState is the run state a Reconciler shares with the reconcilers
+// WithSettings derives from it, so one run lock and cooldown cover them all.
+type runState struct {
+	repoMu  sync.RWMutex
+	db      MetadataRepository // nil until a database is available
+	history RunRepository      // nil keeps run history in memory only
+
+	mu           sync.Mutex
+	running      bool
+	closed       bool
+	lastRunAt    time.Time
+	lastDryRunAt time.Time  // dry runs have their own cooldown
+	lastRun      *RunRecord // most recent run, updated as it progresses
+
+	lastDocs    map[string][]byte // documents of the last successful run
+	lastDocsRun int64             // run history ID of lastDocs, 0 without history
+
+	runs       sync.WaitGroup
+	runCtx     context.Context // cancelled when Shutdown gives up waiting
+	cancelRuns context.CancelFunc
 }
 
 func NewReconciler(
@@ -43,63 +105,135 @@ func NewReconciler(
 	sleeperSvc *sleeper.SleeperService,
 	oai *open_ai.OpenAIService,
 	db MetadataRepository,
-	leagueIDs []string,
-	assistantID string,
+	history RunRepository,
+	leagues []LeagueTarget,
 	transRounds int,
 	cooldown time.Duration,
 	approvedUsers []string,
 ) *Reconciler {
-	approved := make(map[string]bool, len(approvedUsers))
-	for _, u := range approvedUsers {
-		approved[u] = true
-	}
-
 	return &Reconciler{
 		espn:          espnSvc,
 		sleeper:       sleeperSvc,
 		oai:           oai,
-		db:            db,
-		leagueIDs:     leagueIDs,
-		assistantID:   assistantID,
+		leagues:       leagues,
 		transRounds:   transRounds,
 		cooldown:      cooldown,
-		approvedUsers: approved,
+		approvedUsers: approvedSet(approvedUsers),
+		runState:      &runState{db: db, history: history},
 	}
 }
 
+// WithSettings returns a reconciler for reloaded settings. It shares r's
+// run state, so a run of either blocks the other, but not r's group
+// notifier. Runs started through r keep r's settings.
+func (r *Reconciler) WithSettings(leagues []LeagueTarget, transRounds int, cooldown time.Duration, approvedUsers []string) *Reconciler {
+	return &Reconciler{
+		espn:          r.espn,
+		sleeper:       r.sleeper,
+		oai:           r.oai,
+		leagues:       leagues,
+		transRounds:   transRounds,
+		cooldown:      cooldown,
+		approvedUsers: approvedSet(approvedUsers),
+		runState:      r.runState,
+	}
+}
+
+func approvedSet(approvedUsers []string) map[string]bool {
+	approved := make(map[string]bool, len(approvedUsers))
+	for _, u := range approvedUsers {
+		approved[u] = true
+	}
+	return approved
+}
+
+// SetRepositories swaps in the metadata and run history repositories, for a
+// database that became available after startup. Steps already past their
+// repository lookups finish without them.
+func (r *Reconciler) SetRepositories(db MetadataRepository, history RunRepository) {
+	r.repoMu.Lock()
+	defer r.repoMu.Unlock()
+	r.db = db
+	r.history = history
+}
+
+func (r *Reconciler) metadata() MetadataRepository {
+	r.repoMu.RLock()
+	defer r.repoMu.RUnlock()
+	return r.db
+}
+
+func (r *Reconciler) runHistory() RunRepository {
+	r.repoMu.RLock()
+	defer r.repoMu.RUnlock()
+	return r.history
+}
+
+// SetGroupNotifier sets how the summary of a startup or cron run reaches each
+// bound group. Each group only sees its own leagues. Set it before r is in
+// use.
+func (r *Reconciler) SetGroupNotifier(notify func(groupID, summary string)) {
+	r.notifyGroup = notify
+}
+
 // Trigger attempts to start a reconciliation run. senderUserID is the GroupMe
 // user_id of the person triggering via chat, or "" for HTTP/cron/startup triggers.
 // notify is an optional callback called with the trade summary when the run completes.
 // Returns (true, "") if the run was started, or (false, reason) if blocked.
 func (r *Reconciler) Trigger(senderUserID string, notify func(string)) (triggered bool, reason string) {
-	r.mu.Lock()
-	defer r.mu.Unlock()
+	return r.TriggerForGroup(senderUserID, "", notify)
+}
 
-	// Access control: only applies to GroupMe triggers with a non-empty user ID.
-	if senderUserID != "" && len(r.approvedUsers) > 0 {
-		if !r.approvedUsers[senderUserID] {
-			return false, "You're not authorized to trigger a roster refresh."
-		}
+// TriggerFrom is Trigger for runs not requested from chat. source is recorded
+// in run history, e.g. SourceStartup, SourceCron or SourceHTTP.
+func (r *Reconciler) TriggerFrom(source string, notify func(string)) (triggered bool, reason string) {
+	return r.trigger(source, "", "", notify)
+}
+
+// TriggerForGroup is Trigger for a run requested from a GroupMe group. The
+// summary passed to notify only covers the leagues bound to groupID (or the
+// unbound leagues if none are), so one league never sees another's trades.
+// An empty groupID summarizes every league.
+func (r *Reconciler) TriggerForGroup(senderUserID, groupID string, notify func(string)) (triggered bool, reason string) {
+	source := SourceManual
+	if senderUserID != "" {
+		source = "groupme:" + senderUserID
 	}
+	return r.trigger(source, senderUserID, groupID, notify)
+}
+
+func (r *Reconciler) trigger(source, senderUserID, groupID string, notify func(string)) (triggered bool, reason string) {
+	// Cooldown check, against run history when available so it survives
+	// restarts. History is read first so the database isn't queried under r.mu.
+	recorded := r.historyLastRun()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
 
-	// Cooldown check.
-	if !r.lastRunAt.IsZero() && time.Since(r.lastRunAt) < r.cooldown {
-		remaining := (r.cooldown - time.Since(r.lastRunAt)).Round(time.Minute)
-		return false, fmt.Sprintf("Roster data was just refreshed. Try again in %v.", remaining)
+	if err := r.admit(senderUserID, r.lastFinishedAt(recorded)); err != nil {
+		return false, triggerReason(err)
 	}
 
-	// In-flight guard.
-	if r.running {
-		return false, "A roster refresh is already in progress."
+	if r.runCtx == nil {
+		r.runCtx, r.cancelRuns = context.WithCancel(context.Background())
 	}
 
 	r.running = true
+	r.runs.Add(1)
+	leagues, transRounds, ctx := r.leagues, r.transRounds, r.runCtx
+	var notifyGroup func(groupID, summary string)
+	if source == SourceStartup || source == SourceCron {
+		notifyGroup = r.notifyGroup
+	}
 	go func() {
+		defer r.runs.Done()
+		tracker := r.startRun(source)
 		var summary string
 		defer func() {
 			if rec := recover(); rec != nil {
 				fmt.Printf("reconciler: recovered from panic: %v\n", rec)
 				summary = fmt.Sprintf("Roster refresh failed unexpectedly: %v", rec)
+				tracker.finish(summary, fmt.Errorf("panic: %v", rec))
 				if notify != nil {
 					notify(summary)
 				}
@@ -109,11 +243,19 @@ func (r *Reconciler) Trigger(senderUserID string, notify func(string)) (triggere
 			r.lastRunAt = time.Now()
 			r.mu.Unlock()
 		}()
-		var err error
-		summary, err = r.run(context.Background())
+		resolved, err := r.run(ctx, tracker, leagues, transRounds)
 		if err != nil {
 			fmt.Printf("reconciler: run failed: %v\n", err)
 			summary = fmt.Sprintf("Roster refresh failed: %v", err)
+			tracker.finish(summary, err)
+		} else {
+			tracker.finish(buildRefreshSummary(resolved), nil)
+			summary = buildRefreshSummary(leaguesForGroup(resolved, groupID))
+			if notifyGroup != nil {
+				for _, g := range boundGroups(leagues) {
+					notifyGroup(g, buildRefreshSummary(leaguesForGroup(resolved, g)))
+				}
+			}
 		}
 		if notify != nil {
 			notify(summary)
@@ -123,14 +265,125 @@ func (r *Reconciler) Trigger(senderUserID string, notify func(string)) (triggere
 	return true, ""
 }
 
-// run performs the full reconciliation cycle and returns a trade summary string.
-func (r *Reconciler) run(ctx context.Context) (string, error) {
+// ErrRunning is returned while a run or dry run is in progress.
+var ErrRunning = errors.New("a roster refresh is already in progress")
+
+// CooldownError is returned when a run is requested too soon after the last.
+type CooldownError struct {
+	Remaining time.Duration
+}
+
+func (e *CooldownError) Error() string {
+	return fmt.Sprintf("roster data was just refreshed, try again in %v", e.Remaining)
+}
+
+// admit reports whether senderUserID may start a run now: the service must
+// be open, chat users approved, nothing in flight and the cooldown since
+// lastAt over. Must be called with r.mu held.
+func (r *Reconciler) admit(senderUserID string, lastAt time.Time) error {
+	if r.closed {
+		return ErrShuttingDown
+	}
+	// Access control: only applies to GroupMe triggers with a non-empty user ID.
+	if senderUserID != "" && len(r.approvedUsers) > 0 && !r.approvedUsers[senderUserID] {
+		return ErrNotAuthorized
+	}
+	if r.running {
+		return ErrRunning
+	}
+	if !lastAt.IsZero() && time.Since(lastAt) < r.cooldown {
+		return &CooldownError{Remaining: (r.cooldown - time.Since(lastAt)).Round(time.Minute)}
+	}
+	return nil
+}
+
+// triggerReason renders an admit error as the reply to a trigger.
+func triggerReason(err error) string {
+	var cooldown *CooldownError
+	switch {
+	case errors.Is(err, ErrShuttingDown):
+		return "The service is shutting down."
+	case errors.Is(err, ErrNotAuthorized):
+		return "You're not authorized to trigger a roster refresh."
+	case errors.Is(err, ErrRunning):
+		return "A roster refresh is already in progress."
+	case errors.As(err, &cooldown):
+		return fmt.Sprintf("Roster data was just refreshed. Try again in %v.", cooldown.Remaining)
+	default:
+		return err.Error()
+	}
+}
+
+// Shutdown stops accepting triggers and waits for an in-flight run to finish.
+// If ctx expires first the run is cancelled and ctx.Err() is returned; a
+// vector store it already created may then be left behind.
+func (r *Reconciler) Shutdown(ctx context.Context) error {
+	r.mu.Lock()
+	r.closed = true
+	r.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		r.runs.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		r.mu.Lock()
+		if r.cancelRuns != nil {
+			r.cancelRuns()
+		}
+		r.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+// run performs the full reconciliation cycle for targets and returns the
+// resolved leagues. Each stage is recorded on tracker.
+func (r *Reconciler) run(ctx context.Context, tracker *runTracker, targets []LeagueTarget, transRounds int) ([]leagueData, error) {
+	leagues, docsByAssistant, err := r.generate(ctx, tracker, targets, transRounds)
+	if err != nil {
+		return nil, err
+	}
+
+	// 5. Publish one vector store per assistant holding the NFL docs plus
+	// only that assistant's leagues.
+	var failures []string
+	for _, assistantID := range assistantIDs(targets) {
+		docs := docsByAssistant[assistantID]
+		done := tracker.step("publish:" + assistantID)
+		vsID, err := r.publish(ctx, assistantID, docs)
+		done(err)
+		if err != nil {
+			fmt.Printf("reconciler: publish failed for assistant %s: %v\n", assistantID, err)
+			failures = append(failures, err.Error())
+			continue
+		}
+		tracker.record.VectorStoreIDs[assistantID] = vsID
+	}
+
+	if len(failures) > 0 {
+		return nil, fmt.Errorf("%s", strings.Join(failures, "; "))
+	}
+
+	return leagues, nil
+}
+
+// generate fetches ESPN and Sleeper data for targets and renders the
+// documents each assistant's vector store should hold, keyed by assistant ID.
+// It has no side effects beyond recording stages on tracker, which may be nil.
+func (r *Reconciler) generate(ctx context.Context, tracker *runTracker, targets []LeagueTarget, transRounds int) ([]leagueData, map[string]map[string][]byte, error) {
 	fmt.Println("reconciler: starting data fetch")
 
 	// 1. Fetch ESPN rosters.
+	done := tracker.step("espn_rosters")
 	nflTeams, err := r.espn.FetchAllTeamRosters(ctx)
+	done(err)
 	if err != nil {
-		return "", fmt.Errorf("espn fetch failed: %w", err)
+		return nil, nil, fmt.Errorf("espn fetch failed: %w", err)
 	}
 	fmt.Printf("reconciler: fetched %d NFL teams from ESPN\n", len(nflTeams))
 
@@ -142,75 +395,227 @@ func (r *Reconciler) run(ctx context.Context) (string, error) {
 	}
 
 	// 2. Fetch Sleeper all-players (large, in-memory only during this run).
+	done = tracker.step("sleeper_players")
 	sleeperPlayers, err := r.sleeper.FetchAllPlayers(ctx)
+	done(err)
 	if err != nil {
-		return "", fmt.Errorf("sleeper players fetch failed: %w", err)
+		return nil, nil, fmt.Errorf("sleeper players fetch failed: %w", err)
 	}
 	fmt.Printf("reconciler: fetched %d Sleeper players\n", len(sleeperPlayers))
 
 	// 3. Per-league: fetch rosters, users, transactions.
+	done = tracker.step("sleeper_leagues")
 	var leagues []leagueData
-	for _, lid := range r.leagueIDs {
-		league, err := r.fetchLeagueData(ctx, lid, sleeperPlayers, espnByName)
+	var skipped []string
+	for _, target := range targets {
+		league, err := r.fetchLeagueData(ctx, target.LeagueID, transRounds, sleeperPlayers, espnByName)
 		if err != nil {
-			fmt.Printf("reconciler: skipping league %s: %v\n", lid, err)
+			fmt.Printf("reconciler: skipping league %s: %v\n", target.LeagueID, err)
+			skipped = append(skipped, fmt.Sprintf("league %s: %v", target.LeagueID, err))
 			continue
 		}
+		league.groupID = target.GroupID
+		league.assistantID = target.AssistantID
 		leagues = append(leagues, league)
 	}
+	if len(skipped) > 0 {
+		done(fmt.Errorf("skipped %s", strings.Join(skipped, "; ")))
+	} else {
+		done(nil)
+	}
 
-	// 4. Generate documents.
-	docs := make(map[string][]byte)
+	// 4. Generate the NFL documents shared by every assistant, plus each
+	// assistant's own league documents.
+	nflDocs := make(map[string][]byte, len(nflTeams))
 	for _, team := range nflTeams {
-		key := fmt.Sprintf("nfl_team_%s.md", team.Team.TeamID)
-		docs[key] = buildNFLTeamDoc(team)
+		key := nflDocPrefix + team.Team.TeamID + ".md"
+		nflDocs[key] = buildNFLTeamDoc(team)
 	}
-	for _, ld := range leagues {
-		key := fmt.Sprintf("fantasy_league_%s.md", ld.leagueID)
-		docs[key] = buildFantasyLeagueDoc(ld)
+	tracker.addDocuments(nflDocs)
+
+	docsByAssistant := make(map[string]map[string][]byte)
+	for _, assistantID := range assistantIDs(targets) {
+		docs := make(map[string][]byte, len(nflDocs)+len(leagues))
+		leagueDocs := make(map[string][]byte)
+		for k, v := range nflDocs {
+			docs[k] = v
+		}
+		for _, ld := range leagues {
+			if ld.assistantID == assistantID {
+				name := leagueDocName(ld.leagueID)
+				docs[name] = buildFantasyLeagueDoc(ld)
+				leagueDocs[name] = docs[name]
+			}
+		}
+		tracker.addDocuments(leagueDocs)
+		fmt.Printf("reconciler: generated %d documents for assistant %s\n", len(docs), assistantID)
+		docsByAssistant[assistantID] = docs
 	}
-	fmt.Printf("reconciler: generated %d documents\n", len(docs))
 
-	// 5. Create new vector store.
-	vsID, err := r.oai.CreateVectorStore(ctx, vectorStoreName)
+	return leagues, docsByAssistant, nil
+}
+
+// publish brings the assistant's vector store in line with docs and returns
+// its ID. When the store and its document index are known, only changed
+// documents are replaced; otherwise, or if that fails, the store is rebuilt.
+func (r *Reconciler) publish(ctx context.Context, assistantID string, docs map[string][]byte) (string, error) {
+	if db := r.metadata(); db != nil {
+		vsID, _ := db.GetMetadata(ctx, vectorStoreKey(assistantID))
+		index, err := r.loadDocIndex(ctx, assistantID)
+		if err != nil {
+			fmt.Printf("reconciler: %v\n", err)
+		}
+		if vsID != "" && index != nil {
+			err := r.publishChanges(ctx, assistantID, vsID, index, docs)
+			if err == nil {
+				return vsID, nil
+			}
+			fmt.Printf("reconciler: incremental update of %s failed, rebuilding: %v\n", vsID, err)
+		}
+	}
+
+	return r.rebuild(ctx, assistantID, docs)
+}
+
+// rebuild replaces the assistant's vector store with a new one built from docs
+// and returns the new store's ID.
+func (r *Reconciler) rebuild(ctx context.Context, assistantID string, docs map[string][]byte) (string, error) {
+	// Create new vector store.
+	vsID, err := r.oai.CreateVectorStore(ctx, fmt.Sprintf("%s-%s", vectorStoreName, assistantID))
 	if err != nil {
 		return "", fmt.Errorf("vector store creation failed: %w", err)
 	}
 	fmt.Printf("reconciler: created vector store %s\n", vsID)
 
-	// 6. Upload documents.
-	if err := r.oai.UploadFilesToVectorStore(ctx, vsID, docs); err != nil {
+	// Upload documents.
+	// AddFilesToVectorStore deletes its own files when it fails.
+	fileIDs, err := r.oai.AddFilesToVectorStore(ctx, vsID, docs)
+	if err != nil {
+		r.deleteStore(ctx, vsID, nil, "incomplete")
 		return "", fmt.Errorf("vector store upload failed: %w", err)
 	}
 	fmt.Println("reconciler: files uploaded to vector store")
 
-	// 7. Attach vector store to GroupMe assistant.
-	if err := r.oai.AttachVectorStoreToAssistant(ctx, r.assistantID, vsID); err != nil {
+	// Attach vector store to the assistant.
+	if err := r.oai.AttachVectorStoreToAssistant(ctx, assistantID, vsID); err != nil {
+		r.deleteStore(ctx, vsID, newDocIndex(docs, fileIDs).fileIDs(), "unattached")
 		return "", fmt.Errorf("vector store attachment failed: %w", err)
 	}
-	fmt.Printf("reconciler: attached vector store to assistant %s\n", r.assistantID)
+	fmt.Printf("reconciler: attached vector store to assistant %s\n", assistantID)
 
-	// 8. Delete old vector store (if any).
-	if r.db != nil {
-		oldVsID, _ := r.db.GetMetadata(ctx, vectorStoreIDKey)
+	// Delete old vector store (if any) and persist the new ID.
+	if db := r.metadata(); db != nil {
+		key := vectorStoreKey(assistantID)
+		oldVsID, _ := db.GetMetadata(ctx, key)
+		if oldVsID == "" {
+			// Stores created before per-assistant keys were recorded under
+			// the legacy key; take it over once so it gets cleaned up.
+			oldVsID, _ = db.GetMetadata(ctx, vectorStoreIDKey)
+			if oldVsID != "" {
+				if err := db.SetMetadata(ctx, vectorStoreIDKey, ""); err != nil {
+					fmt.Printf("reconciler: failed to clear legacy vector store ID: %v\n", err)
+				}
+			}
+		}
 		if oldVsID != "" && oldVsID != vsID {
-			if err := r.oai.DeleteVectorStore(ctx, oldVsID); err != nil {
-				fmt.Printf("reconciler: failed to delete old vector store %s: %v\n", oldVsID, err)
+			// Deleting a store leaves its files behind; the old index names them.
+			oldIndex, err := r.loadDocIndex(ctx, assistantID)
+			if err != nil {
+				fmt.Printf("reconciler: %v\n", err)
 			}
+			r.deleteStore(ctx, oldVsID, oldIndex.fileIDs(), "old")
 		}
 
-		// 9. Persist new vector store ID.
-		if err := r.db.SetMetadata(ctx, vectorStoreIDKey, vsID); err != nil {
+		if err := db.SetMetadata(ctx, key, vsID); err != nil {
 			fmt.Printf("reconciler: failed to persist vector store ID: %v\n", err)
 		}
+		if err := r.saveDocIndex(ctx, assistantID, newDocIndex(docs, fileIDs)); err != nil {
+			fmt.Printf("reconciler: %v\n", err)
+		}
+	}
+
+	return vsID, nil
+}
+
+// deleteStore deletes a vector store and then the files that were in it,
+// logging failures. kind describes the store in log lines.
+func (r *Reconciler) deleteStore(ctx context.Context, vsID string, fileIDs []string, kind string) {
+	if err := r.oai.DeleteVectorStore(ctx, vsID); err != nil {
+		fmt.Printf("reconciler: failed to delete %s vector store %s: %v\n", kind, vsID, err)
+	}
+	if err := r.oai.DeleteFiles(ctx, fileIDs); err != nil {
+		fmt.Printf("reconciler: %s vector store %s: %v\n", kind, vsID, err)
+	}
+}
+
+// assistantIDs returns each distinct assistant across the league targets,
+// in configuration order.
+func assistantIDs(targets []LeagueTarget) []string {
+	seen := make(map[string]bool)
+	var ids []string
+	for _, t := range targets {
+		if !seen[t.AssistantID] {
+			seen[t.AssistantID] = true
+			ids = append(ids, t.AssistantID)
+		}
+	}
+	return ids
+}
+
+// vectorStoreKey is the metadata key holding an assistant's vector store ID.
+func vectorStoreKey(assistantID string) string {
+	return fmt.Sprintf("%s:%s", vectorStoreIDKey, assistantID)
+}
+
+// boundGroups returns each group that follows a league, in configuration order.
+func boundGroups(targets []LeagueTarget) []string {
+	seen := make(map[string]bool)
+	var groups []string
+	for _, t := range targets {
+		if t.GroupID != "" && !seen[t.GroupID] {
+			seen[t.GroupID] = true
+			groups = append(groups, t.GroupID)
+		}
 	}
+	return groups
+}
 
-	return buildTradeSummary(leagues), nil
+// leaguesForGroup selects the leagues whose summary a group may see.
+func leaguesForGroup(leagues []leagueData, groupID string) []leagueData {
+	return forGroup(leagues, func(ld leagueData) string { return ld.groupID }, groupID)
+}
+
+// targetsForGroup selects the league targets a group may refresh.
+func targetsForGroup(targets []LeagueTarget, groupID string) []LeagueTarget {
+	return forGroup(targets, func(t LeagueTarget) string { return t.GroupID }, groupID)
+}
+
+// forGroup selects the items bound to groupID, or the unbound items when the
+// group has none. An empty groupID selects every item.
+func forGroup[T any](items []T, groupOf func(T) string, groupID string) []T {
+	if groupID == "" {
+		return items
+	}
+
+	var bound, unbound []T
+	for _, item := range items {
+		switch groupOf(item) {
+		case groupID:
+			bound = append(bound, item)
+		case "":
+			unbound = append(unbound, item)
+		}
+	}
+	if len(bound) > 0 {
+		return bound
+	}
+	return unbound
 }
 
 func (r *Reconciler) fetchLeagueData(
 	ctx context.Context,
 	leagueID string,
+	transRounds int,
 	sleeperPlayers map[string]sleeper.SleeperPlayer,
 	espnByName map[string]espn.TeamWithRoster,
 ) (leagueData, error) {
@@ -229,11 +634,98 @@ func (r *Reconciler) fetchLeagueData(
 		return leagueData{}, err
 	}
 
-	transactions, err := r.sleeper.FetchRecentTransactions(ctx, leagueID, r.transRounds)
+	transactions, err := r.sleeper.FetchRecentTransactions(ctx, leagueID, transRounds)
 	if err != nil {
 		fmt.Printf("reconciler: failed to fetch transactions for league %s: %v\n", leagueID, err)
 		transactions = nil // non-fatal
 	}
 
-	return resolveLeague(leagueID, league.Name, rosters, users, sleeperPlayers, transactions, espnByName), nil
+	// Without the NFL state there is no current week; scores are left out.
+	state, err := r.sleeper.NFLState(ctx)
+	if err != nil {
+		fmt.Printf("reconciler: failed to fetch NFL state for league %s: %v\n", leagueID, err)
+	}
+
+	var matchups []sleeper.WeekMatchup
+	for _, week := range state.ScoredWeeks() {
+		games, err := r.sleeper.FetchMatchups(ctx, leagueID, week)
+		if err != nil {
+			fmt.Printf("reconciler: failed to fetch matchups for league %s: %v\n", leagueID, err)
+			break // non-fatal; keep the weeks fetched so far
+		}
+		matchups = append(matchups, games...)
+	}
+
+	ld := resolveLeague(sleeperLeague{
+		leagueID:     leagueID,
+		leagueName:   league.Name,
+		rosters:      rosters,
+		users:        users,
+		transactions: transactions,
+		matchups:     matchups,
+		drafts:       r.fetchDrafts(ctx, leagueID, league),
+	}, sleeperPlayers, espnByName)
+	if league.Settings.UsesFAAB() {
+		ld.faabBudget = league.Settings.WaiverBudget
+	}
+	ld.state = state
+	return ld, nil
+}
+
+// draftSeasons bounds how many seasons of drafts are read. Dynasty leagues
+// start a new league every season, so each earlier season costs a request
+// for the league it was renewed from.
+const draftSeasons = 5
+
+// fetchDrafts fetches the drafts and their picks for the league's season and
+// the seasons before it, following previous_league_id, plus the league's
+// traded picks. Failures are logged and leave the affected data out.
+func (r *Reconciler) fetchDrafts(ctx context.Context, leagueID string, league *sleeper.League) leagueDrafts {
+	ld := leagueDrafts{
+		season: league.Season,
+		rounds: league.Settings.DraftRounds,
+		picks:  make(map[string][]sleeper.DraftPick),
+	}
+
+	seasonID, previousID := leagueID, league.PreviousSeason()
+	for seasons := 1; ; seasons++ {
+		r.fetchSeasonDrafts(ctx, seasonID, &ld)
+		if previousID == "" || seasons == draftSeasons {
+			break
+		}
+		previous, err := r.sleeper.FetchLeague(ctx, previousID)
+		if err != nil {
+			fmt.Printf("reconciler: failed to fetch previous season %s of league %s: %v\n", previousID, leagueID, err)
+			break
+		}
+		seasonID, previousID = previousID, previous.PreviousSeason()
+	}
+
+	var err error
+	ld.tradedPicks, err = r.sleeper.FetchTradedPicks(ctx, leagueID)
+	if err != nil {
+		fmt.Printf("reconciler: failed to fetch traded picks for league %s: %v\n", leagueID, err)
+	}
+	return ld
+}
+
+// fetchSeasonDrafts adds one season's drafts and their picks to ld.
+func (r *Reconciler) fetchSeasonDrafts(ctx context.Context, leagueID string, ld *leagueDrafts) {
+	drafts, err := r.sleeper.FetchDrafts(ctx, leagueID)
+	if err != nil {
+		fmt.Printf("reconciler: failed to fetch drafts for league %s: %v\n", leagueID, err)
+	}
+	for _, d := range drafts {
+		if d.Status == sleeper.DraftPreDraft {
+			ld.drafts = append(ld.drafts, d)
+			continue
+		}
+		picks, err := r.sleeper.FetchDraftPicks(ctx, d.DraftID)
+		if err != nil {
+			fmt.Printf("reconciler: failed to fetch picks for draft %s: %v\n", d.DraftID, err)
+			continue
+		}
+		ld.drafts = append(ld.drafts, d)
+		ld.picks[d.DraftID] = picks
+	}
 }
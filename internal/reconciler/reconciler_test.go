@@ -1,6 +1,7 @@
 package reconciler
 
 import (
+	"context"
 	"testing"
 	"time"
 
@@ -9,7 +10,7 @@ import (
 )
 
 func newGuardTestReconciler() *Reconciler {
-	return &Reconciler{cooldown: 0}
+	return &Reconciler{cooldown: 0, runState: &runState{}}
 }
 
 func TestTrigger_UnauthorizedUser(t *testing.T) {
@@ -55,8 +56,8 @@ func TestTrigger_NoApprovedListAllowsAnyone(t *testing.T) {
 
 func TestTrigger_CooldownBlocks(t *testing.T) {
 	r := &Reconciler{
-		cooldown:  time.Hour,
-		lastRunAt: time.Now(),
+		cooldown: time.Hour,
+		runState: &runState{lastRunAt: time.Now()},
 	}
 
 	triggered, reason := r.Trigger("", nil)
@@ -102,3 +103,112 @@ func TestTrigger_SetsRunningFalseAfterCompletion(t *testing.T) {
 	assert.False(t, r.running)
 	assert.False(t, r.lastRunAt.IsZero())
 }
+
+func TestShutdown_WaitsForRunAndRejectsTriggers(t *testing.T) {
+	r := newGuardTestReconciler()
+	done := make(chan string, 1)
+
+	triggered, _ := r.Trigger("", func(s string) { done <- s })
+	require.True(t, triggered)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, r.Shutdown(ctx))
+	assert.NotEmpty(t, <-done, "run must complete before Shutdown returns")
+
+	triggered, reason := r.Trigger("", nil)
+	assert.False(t, triggered)
+	assert.Contains(t, reason, "shutting down")
+}
+
+func TestShutdown_DeadlineCancelsRun(t *testing.T) {
+	r := newGuardTestReconciler()
+	r.runs.Add(1) // a run that never finishes on its own
+	r.runCtx, r.cancelRuns = context.WithCancel(context.Background())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, r.Shutdown(ctx), context.DeadlineExceeded)
+	assert.Error(t, r.runCtx.Err(), "run context should be cancelled")
+}
+
+func TestAssistantIDs_DistinctInOrder(t *testing.T) {
+	targets := []LeagueTarget{
+		{LeagueID: "l1", AssistantID: "a1"},
+		{LeagueID: "l2", AssistantID: "a2"},
+		{LeagueID: "l3", AssistantID: "a1"},
+	}
+	assert.Equal(t, []string{"a1", "a2"}, assistantIDs(targets))
+}
+
+func TestLeaguesForGroup(t *testing.T) {
+	leagues := []leagueData{
+		{leagueID: "l1", groupID: "g1"},
+		{leagueID: "l2", groupID: "g2"},
+		{leagueID: "l3"},
+	}
+	ids := func(ls []leagueData) []string {
+		var out []string
+		for _, l := range ls {
+			out = append(out, l.leagueID)
+		}
+		return out
+	}
+
+	assert.Equal(t, []string{"l1"}, ids(leaguesForGroup(leagues, "g1")))
+	assert.Equal(t, []string{"l3"}, ids(leaguesForGroup(leagues, "unbound-group")))
+	assert.Equal(t, []string{"l1", "l2", "l3"}, ids(leaguesForGroup(leagues, "")))
+}
+
+func TestValidateTargets(t *testing.T) {
+	assert.NoError(t, ValidateTargets([]LeagueTarget{
+		{LeagueID: "l1", GroupID: "g1", AssistantID: "a1"},
+		{LeagueID: "l2", GroupID: "g1", AssistantID: "a1"},
+		{LeagueID: "l3", AssistantID: "a2"},
+		{LeagueID: "l4", AssistantID: "a2"},
+	}))
+
+	// A bound group without its own assistant falls back to the one the
+	// unbound leagues use, which would share their vector store.
+	err := ValidateTargets([]LeagueTarget{
+		{LeagueID: "l1", AssistantID: "default"},
+		{LeagueID: "l2", GroupID: "g2", AssistantID: "default"},
+	})
+	assert.ErrorContains(t, err, `league l1 (unbound) and league l2 (group g2) both use assistant "default"`)
+}
+
+func TestBoundGroups(t *testing.T) {
+	targets := []LeagueTarget{
+		{LeagueID: "l1", GroupID: "g2"},
+		{LeagueID: "l2"},
+		{LeagueID: "l3", GroupID: "g1"},
+		{LeagueID: "l4", GroupID: "g2"},
+	}
+	assert.Equal(t, []string{"g2", "g1"}, boundGroups(targets))
+}
+
+func TestWithSettings_SharesRunState(t *testing.T) {
+	r := NewReconciler(nil, nil, nil, nil, nil, nil, 2, time.Hour, []string{"old"})
+	next := r.WithSettings([]LeagueTarget{{LeagueID: "l1", AssistantID: "a1"}}, 3, 0, []string{"new"})
+
+	triggered, reason := next.Trigger("old", nil)
+	assert.False(t, triggered)
+	assert.Contains(t, reason, "not authorized")
+
+	assert.Equal(t, 3, next.transRounds)
+	assert.Equal(t, time.Duration(0), next.cooldown)
+	assert.Len(t, next.leagues, 1)
+	assert.Equal(t, 2, r.transRounds, "the original keeps its settings")
+	assert.Empty(t, r.leagues)
+
+	r.mu.Lock()
+	r.running = true
+	r.mu.Unlock()
+	triggered, reason = next.Trigger("new", nil)
+	assert.False(t, triggered)
+	assert.Contains(t, reason, "already in progress")
+}
+
+func TestVectorStoreKey_PerAssistant(t *testing.T) {
+	assert.Equal(t, "vector_store_id:asst_1", vectorStoreKey("asst_1"))
+}
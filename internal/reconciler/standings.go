@@ -0,0 +1,129 @@
+package reconciler
+
+import (
+	"context"
+	"crowfather/internal/sleeper"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// record is a team's wins, losses and ties.
+type record struct {
+	wins, losses, ties int
+}
+
+func (r record) String() string {
+	if r.ties > 0 {
+		return fmt.Sprintf("%d-%d-%d", r.wins, r.losses, r.ties)
+	}
+	return fmt.Sprintf("%d-%d", r.wins, r.losses)
+}
+
+// winPct counts a tie as half a win. A team that hasn't played has 0.
+func (r record) winPct() float64 {
+	games := r.wins + r.losses + r.ties
+	if games == 0 {
+		return 0
+	}
+	return (float64(r.wins) + float64(r.ties)/2) / float64(games)
+}
+
+// standing is a team's place in the league table.
+type standing struct {
+	ownerName string
+	record
+	pointsFor      float64
+	pointsAgainst  float64
+	waiverPosition int
+}
+
+// resolveStandings ranks the rosters by win percentage, breaking ties by the
+// most points for and then the fewest points against.
+func resolveStandings(rosters []sleeper.Roster, ownerByRosterID map[int]string) []standing {
+	standings := make([]standing, 0, len(rosters))
+	for _, r := range rosters {
+		s := r.Settings
+		standings = append(standings, standing{
+			ownerName:      ownerByRosterID[r.RosterID],
+			record:         record{wins: s.Wins, losses: s.Losses, ties: s.Ties},
+			pointsFor:      s.PointsFor(),
+			pointsAgainst:  s.PointsAgainst(),
+			waiverPosition: s.WaiverPosition,
+		})
+	}
+
+	sort.SliceStable(standings, func(i, j int) bool {
+		a, b := standings[i], standings[j]
+		switch {
+		case a.winPct() != b.winPct():
+			return a.winPct() > b.winPct()
+		case a.pointsFor != b.pointsFor:
+			return a.pointsFor > b.pointsFor
+		case a.pointsAgainst != b.pointsAgainst:
+			return a.pointsAgainst < b.pointsAgainst
+		default:
+			return a.ownerName < b.ownerName
+		}
+	})
+	return standings
+}
+
+// writeStandings writes the league table for the league document.
+func writeStandings(sb *strings.Builder, standings []standing) {
+	if len(standings) == 0 {
+		return
+	}
+	sb.WriteString("## Standings\n\n")
+	sb.WriteString("| Rank | Team | Record | Points For | Points Against | Waiver Position |\n")
+	sb.WriteString("|------|------|--------|------------|----------------|-----------------|\n")
+	for i, s := range standings {
+		fmt.Fprintf(sb, "| %d | %s | %s | %.2f | %.2f | %d |\n", i+1, s.ownerName, s.record, s.pointsFor, s.pointsAgainst, s.waiverPosition)
+	}
+	sb.WriteString("\n")
+}
+
+// formatStandings renders the league table as a GroupMe message.
+func formatStandings(leagueName string, standings []standing) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s standings:", leagueName)
+	for i, s := range standings {
+		fmt.Fprintf(&sb, "\n%d. %s %s (%.2f PF, %.2f PA)", i+1, s.ownerName, s.record, s.pointsFor, s.pointsAgainst)
+	}
+	return sb.String()
+}
+
+// Standings fetches the current standings of the leagues groupID follows, as
+// selected by targetsForGroup, and renders them for chat. An empty groupID
+// covers every league.
+func (r *Reconciler) Standings(ctx context.Context, groupID string) (string, error) {
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		return "", ErrShuttingDown
+	}
+	targets := targetsForGroup(r.leagues, groupID)
+	r.mu.Unlock()
+
+	if len(targets) == 0 {
+		return "", fmt.Errorf("no leagues are configured for this group")
+	}
+
+	var tables []string
+	for _, target := range targets {
+		league, err := r.sleeper.FetchLeague(ctx, target.LeagueID)
+		if err != nil {
+			return "", err
+		}
+		rosters, err := r.sleeper.FetchLeagueRosters(ctx, target.LeagueID)
+		if err != nil {
+			return "", err
+		}
+		users, err := r.sleeper.FetchLeagueUsers(ctx, target.LeagueID)
+		if err != nil {
+			return "", err
+		}
+		tables = append(tables, formatStandings(league.Name, resolveStandings(rosters, ownerNames(rosters, users))))
+	}
+	return strings.Join(tables, "\n\n"), nil
+}
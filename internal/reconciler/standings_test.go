@@ -0,0 +1,75 @@
+package reconciler
+
+import (
+	"context"
+	"crowfather/internal/sleeper"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Teams are ranked by win percentage, then most points for, then fewest
+// points against.
+func TestResolveStandings_Tiebreakers(t *testing.T) {
+	rosters := []sleeper.Roster{
+		{RosterID: 1, Settings: sleeper.RosterSettings{Wins: 5, Losses: 4, Fpts: 1000}},
+		{RosterID: 2, Settings: sleeper.RosterSettings{Wins: 6, Losses: 3, Fpts: 900}},
+		{RosterID: 3, Settings: sleeper.RosterSettings{Wins: 5, Losses: 4, Fpts: 1000, FptsDecimal: 50}},
+		{RosterID: 4, Settings: sleeper.RosterSettings{Wins: 5, Losses: 4, Fpts: 1000, FptsAgainst: 800}},
+		{RosterID: 5, Settings: sleeper.RosterSettings{Wins: 5, Losses: 3, Ties: 1}},
+	}
+	owners := map[int]string{1: "Alice", 2: "Bob", 3: "Carol", 4: "Dave", 5: "Erin"}
+
+	var order []string
+	for _, s := range resolveStandings(rosters, owners) {
+		order = append(order, s.ownerName)
+	}
+	assert.Equal(t, []string{"Bob", "Erin", "Carol", "Alice", "Dave"}, order)
+}
+
+func TestBuildFantasyLeagueDoc_StandingsFirst(t *testing.T) {
+	ld := leagueData{
+		leagueName: "Test League",
+		standings: []standing{
+			{ownerName: "Bob", record: record{wins: 6, losses: 3}, pointsFor: 1234.5, pointsAgainst: 1100.25, waiverPosition: 9},
+			{ownerName: "Alice", record: record{wins: 5, losses: 3, ties: 1}, pointsFor: 1000},
+		},
+		rosters: []resolvedRoster{{ownerName: "Alice"}},
+	}
+
+	doc := string(buildFantasyLeagueDoc(ld))
+	assert.Contains(t, doc, "# Fantasy League: Test League\n\n## Standings\n\n")
+	assert.Contains(t, doc, "| 1 | Bob | 6-3 | 1234.50 | 1100.25 | 9 |\n| 2 | Alice | 5-3-1 | 1000.00 | 0.00 | 0 |\n")
+	assert.Less(t, strings.Index(doc, "## Standings"), strings.Index(doc, "## Team: Alice"))
+}
+
+func TestStandings_GroupLeagues(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/league/l1":
+			w.Write([]byte(`{"league_id": "l1", "name": "Dynasty"}`))
+		case "/league/l1/rosters":
+			w.Write([]byte(`[
+				{"roster_id": 1, "owner_id": "u1", "settings": {"wins": 2, "losses": 1, "fpts": 300, "fpts_decimal": 12, "fpts_against": 280}},
+				{"roster_id": 2, "owner_id": "u2", "settings": {"wins": 1, "losses": 2, "fpts": 250, "fpts_against": 270, "fpts_against_decimal": 5}}
+			]`))
+		case "/league/l1/users":
+			w.Write([]byte(`[{"user_id": "u1", "display_name": "Alice"}, {"user_id": "u2", "display_name": "Bob"}]`))
+		default:
+			t.Errorf("unexpected request %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	leagues := []LeagueTarget{{LeagueID: "l1", GroupID: "g1"}, {LeagueID: "l2", GroupID: "g2"}}
+	r := NewReconciler(nil, sleeper.NewSleeperServiceWithBaseURL(server.URL), nil, nil, nil, leagues, 2, 0, nil)
+
+	got, err := r.Standings(context.Background(), "g1")
+	require.NoError(t, err)
+	assert.Equal(t, "Dynasty standings:\n1. Alice 2-1 (300.12 PF, 280.00 PA)\n2. Bob 1-2 (250.00 PF, 270.05 PA)", got)
+}
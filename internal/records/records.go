@@ -0,0 +1,48 @@
+// Package records holds the rows shared between the services and the
+// database package, so the database layer doesn't import the services that
+// consume it.
+package records
+
+import "time"
+
+// ThreadRecord is one thread a context has used. The active thread has a
+// zero RetiredAt.
+type ThreadRecord struct {
+	ContextID    string
+	ThreadID     string
+	MessageCount int
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	RetiredAt    time.Time
+	RetireReason string
+	Summary      string // summary of this thread, carried into the next
+}
+
+// RunRecord is one reconciliation run as stored in run history.
+type RunRecord struct {
+	ID             int64             `json:"id"`
+	Trigger        string            `json:"trigger"`
+	Status         string            `json:"status"`
+	StartedAt      time.Time         `json:"started_at"`
+	FinishedAt     time.Time         `json:"finished_at"` // zero while running
+	Steps          []StepRecord      `json:"steps"`
+	Documents      []string          `json:"documents"`
+	VectorStoreIDs map[string]string `json:"vector_store_ids"` // assistant ID → vector store ID
+	Error          string            `json:"error"`
+	Summary        string            `json:"summary"`
+}
+
+// StepRecord is the outcome of one stage of a run.
+type StepRecord struct {
+	Name       string    `json:"name"`
+	Status     string    `json:"status"`
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// RunDocument is a document generated by a run, as uploaded to the vector store.
+type RunDocument struct {
+	Name    string `json:"name"`
+	Content string `json:"content"`
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"crowfather/internal/config"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// liveServices holds the services requests are served with. cron is nil
+// when no Sleeper leagues are configured.
+type liveServices struct {
+	current atomic.Pointer[services]
+	cron    *time.Ticker
+}
+
+// reload puts next into effect. It builds a complete set of services for next
+// from the current set, sharing its threads, run state, stored group bindings
+// and Sleeper player cache, and then publishes it in one swap. A request or
+// job keeps the set that was current when it arrived, so it never sees one
+// config's GroupMe bindings next to another's approved users or tool scopes.
+// A rejected config changes nothing.
+func (s *liveServices) reload(next *config.Config) error {
+	cur := s.current.Load()
+	built := &services{
+		oai:   cur.oai.WithThreadPolicy(next.Threads),
+		gms:   cur.gms.WithConfig(next.GroupMe),
+		tools: cur.tools,
+		rec:   cur.rec,
+	}
+
+	reconfigure := cur.rec != nil && next.Reconciler != nil
+	if reconfigure {
+		targets, err := leagueTargets(next, built.gms.Bindings(next.GroupMe))
+		if err != nil {
+			return err
+		}
+		built.oai, built.tools = bindSleeperTools(built.oai, cur.tools, targets, next.Reconciler.TransactionRounds)
+		built.rec = cur.rec.WithSettings(
+			targets,
+			next.Reconciler.TransactionRounds,
+			next.Reconciler.CooldownMinutes,
+			next.Reconciler.ApprovedUsers,
+		)
+		notifyGroups(built.rec, built.gms)
+	} else if (cur.rec != nil) != (next.Reconciler != nil) {
+		fmt.Println("Config reload: enabling or disabling the reconciler requires a restart")
+	}
+	if cur.router != nil {
+		built.router = cur.router.WithServices(built.oai, built.gms, built.rec, next)
+	}
+
+	s.current.Store(built)
+	if reconfigure {
+		s.cron.Reset(next.Reconciler.Interval)
+	}
+	return nil
+}
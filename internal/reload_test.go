@@ -0,0 +1,82 @@
+package main
+
+import (
+	"crowfather/internal/config"
+	"crowfather/internal/groupme"
+	"crowfather/internal/open_ai"
+	"crowfather/internal/reconciler"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestLive(t *testing.T) *liveServices {
+	gms := groupme.NewGroupMeService(&config.GroupMeConfig{BotID: "bot"})
+	oai := open_ai.NewOpenAIService(&config.OpenAIConfig{}, nil)
+	rec := reconciler.NewReconciler(nil, nil, oai, nil, nil, nil, 2, time.Hour, nil)
+	live := &liveServices{cron: time.NewTicker(time.Hour)}
+	t.Cleanup(live.cron.Stop)
+	live.current.Store(&services{oai: oai, gms: gms, rec: rec})
+	return live
+}
+
+// A config the reconciler rejects leaves every service on the old settings,
+// including those reloaded before the reconciler.
+func TestReload_RejectedConfigChangesNothing(t *testing.T) {
+	live := newTestLive(t)
+	before := live.current.Load()
+
+	next := &config.Config{
+		GroupMe:    &config.GroupMeConfig{Groups: []config.GroupBinding{{GroupID: "g1", BotID: "new"}}},
+		Assistants: &config.Assistants{GroupMeAssistantID: "asst_default"},
+		Threads:    &config.ThreadsConfig{MaxMessages: 10},
+		Reconciler: &config.ReconcilerConfig{
+			LeagueIDs: []string{"l1", "l2"},
+			Leagues:   []config.LeagueBinding{{LeagueID: "l1", GroupID: "g1"}},
+		},
+	}
+	assert.ErrorContains(t, live.reload(next), "need their own assistants")
+
+	assert.Same(t, before, live.current.Load(), "no new services must be published")
+	_, ok := before.gms.Group("g1")
+	assert.False(t, ok, "GroupMe bindings must not be applied")
+	before.oai.ThreadIds["g1"] = "thread_1"
+	info, _ := before.oai.ThreadInfo("g1")
+	assert.Zero(t, info.MaxMessages, "the thread policy must not be applied")
+}
+
+// An accepted config is published as a new set of services sharing the old
+// set's state, while the old set keeps its settings for work in flight.
+func TestReload_PublishesNewServices(t *testing.T) {
+	live := newTestLive(t)
+	before := live.current.Load()
+	before.oai.ThreadIds["g1"] = "thread_1"
+
+	next := &config.Config{
+		GroupMe:    &config.GroupMeConfig{Groups: []config.GroupBinding{{GroupID: "g1", BotID: "new", AssistantID: "asst_g1"}}},
+		Assistants: &config.Assistants{GroupMeAssistantID: "asst_default"},
+		Threads:    &config.ThreadsConfig{MaxMessages: 10},
+		Reconciler: &config.ReconcilerConfig{
+			LeagueIDs: []string{"l1"},
+			Leagues:   []config.LeagueBinding{{LeagueID: "l1", GroupID: "g1"}},
+			Interval:  time.Hour,
+		},
+	}
+	require.NoError(t, live.reload(next))
+
+	after := live.current.Load()
+	require.NotSame(t, before, after)
+	b, ok := after.gms.Group("g1")
+	assert.True(t, ok)
+	assert.Equal(t, "asst_g1", b.AssistantID)
+	info, _ := after.oai.ThreadInfo("g1")
+	assert.Equal(t, "thread_1", info.ThreadID, "threads are shared")
+	assert.Equal(t, 10, info.MaxMessages)
+
+	_, ok = before.gms.Group("g1")
+	assert.False(t, ok, "the old services keep their bindings")
+	info, _ = before.oai.ThreadInfo("g1")
+	assert.Zero(t, info.MaxMessages, "the old services keep their thread policy")
+}
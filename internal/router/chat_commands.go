@@ -0,0 +1,248 @@
+package router
+
+import (
+	"context"
+	"crowfather/internal/config"
+	"crowfather/internal/groupme"
+	"crowfather/internal/open_ai"
+	"crowfather/internal/reconciler"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// chatTrigger starts every chat command, e.g. "hey crowfather status".
+const chatTrigger = "hey crowfather"
+
+// chatCommand is a GroupMe command handled by the bot itself rather than
+// passed to the assistant.
+type chatCommand struct {
+	name  string
+	help  string
+	allow permission
+	// trailing lets words follow the command ("refresh the rosters"). Other
+	// commands must stand alone so "hey crowfather help me pick a QB" still
+	// reaches the assistant.
+	trailing bool
+	// needsReconciler hides the command when no leagues are configured.
+	needsReconciler bool
+	run             func(r *Router, cfg *config.Config, msg groupme.Message)
+}
+
+// permission reports whether the GroupMe user may run a command.
+type permission func(cfg *config.Config, userID string) bool
+
+func anyone(*config.Config, string) bool { return true }
+
+// approvedUsers admits the users in ReconcilerConfig.ApprovedUsers, or anyone
+// when that list is empty, the same rule the reconciler applies to refreshes.
+func approvedUsers(cfg *config.Config, userID string) bool {
+	if cfg.Reconciler == nil || len(cfg.Reconciler.ApprovedUsers) == 0 {
+		return true
+	}
+	return slices.Contains(cfg.Reconciler.ApprovedUsers, userID)
+}
+
+// chatCommands lists every command. A command whose name starts with another
+// command's name comes first so the longer one wins.
+func chatCommands() []chatCommand {
+	return []chatCommand{
+		{
+			name:            "refresh dry run",
+			help:            "preview a roster refresh without uploading anything",
+			allow:           approvedUsers,
+			trailing:        true,
+			needsReconciler: true,
+			run: func(r *Router, _ *config.Config, msg groupme.Message) {
+				r.handleGroupMeDryRun(msg)
+			},
+		},
+		{
+			name:            "refresh",
+			help:            "refresh the league data the assistant answers from",
+			allow:           approvedUsers,
+			trailing:        true,
+			needsReconciler: true,
+			run: func(r *Router, _ *config.Config, msg groupme.Message) {
+				r.reply(msg, r.handleGroupMeRefresh(msg))
+			},
+		},
+		{
+			name:            "standings",
+			help:            "show the current league standings",
+			allow:           anyone,
+			needsReconciler: true,
+			run:             (*Router).handleGroupMeStandings,
+		},
+		{
+			name:  "reset",
+			help:  "start a new conversation thread, keeping a summary of this one",
+			allow: approvedUsers,
+			run:   (*Router).handleGroupMeReset,
+		},
+		{
+			name:  "status",
+			help:  "show the conversation thread and roster refresh status",
+			allow: anyone,
+			run:   (*Router).handleGroupMeStatus,
+		},
+		{
+			name:  "help",
+			help:  "list these commands",
+			allow: anyone,
+			run:   (*Router).handleGroupMeHelp,
+		},
+	}
+}
+
+// matchCommand returns the command in text, or nil if text isn't a command.
+func (r *Router) matchCommand(text string) *chatCommand {
+	lower := strings.ToLower(text)
+	i := strings.Index(lower, chatTrigger)
+	if i < 0 {
+		return nil
+	}
+	rest := strings.TrimLeft(lower[i+len(chatTrigger):], " ,:")
+	rest = strings.TrimRight(rest, " .!?")
+
+	commands := chatCommands()
+	for i := range commands {
+		cmd := &commands[i]
+		if cmd.needsReconciler && r.rec == nil {
+			continue
+		}
+		if rest == cmd.name || (cmd.trailing && strings.HasPrefix(rest, cmd.name+" ")) {
+			return cmd
+		}
+	}
+	return nil
+}
+
+// runCommand checks the sender may run cmd, then runs it.
+func (r *Router) runCommand(cmd *chatCommand, cfg *config.Config, msg groupme.Message) {
+	if !cmd.allow(cfg, msg.UserId) {
+		r.reply(msg, fmt.Sprintf("@%s Only approved users can use \"%s %s\".", msg.Name, chatTrigger, cmd.name))
+		return
+	}
+	cmd.run(r, cfg, msg)
+}
+
+func (r *Router) reply(msg groupme.Message, text string) {
+	if err := r.gms.SendGroupMessage(msg.GroupId, text); err != nil {
+		fmt.Printf("router: failed to send command reply: %v\n", err)
+	}
+}
+
+// handleGroupMeReset rotates the group's thread. Summarizing the old thread
+// takes a few seconds, so it runs in the background and replies when done.
+// A reset while the assistant is answering is refused rather than queued.
+func (r *Router) handleGroupMeReset(_ *config.Config, msg groupme.Message) {
+	go func() {
+		_, err := r.oai.ResetThread(msg.GroupId)
+		switch {
+		case errors.Is(err, open_ai.ErrThreadBusy):
+			r.reply(msg, fmt.Sprintf("@%s I'm still answering a message. Try the reset again in a moment.", msg.Name))
+		case err != nil:
+			r.reply(msg, fmt.Sprintf("Reset failed: %v", err))
+		default:
+			r.reply(msg, fmt.Sprintf("@%s Started a new conversation. I kept a summary of the old one.", msg.Name))
+		}
+	}()
+}
+
+// handleGroupMeStandings replies with the standings of the group's leagues,
+// fetched from Sleeper in the background.
+func (r *Router) handleGroupMeStandings(_ *config.Config, msg groupme.Message) {
+	go func() {
+		standings, err := r.rec.Standings(context.Background(), msg.GroupId)
+		if err != nil {
+			r.reply(msg, fmt.Sprintf("Couldn't load the standings: %v", err))
+			return
+		}
+		r.reply(msg, standings)
+	}()
+}
+
+func (r *Router) handleGroupMeStatus(_ *config.Config, msg groupme.Message) {
+	var lines []string
+
+	if info, ok := r.oai.ThreadInfo(msg.GroupId); ok {
+		line := fmt.Sprintf("Conversation: %d message(s), started %s ago", info.Messages, formatAge(time.Since(info.CreatedAt)))
+		var limits []string
+		if info.MaxMessages > 0 {
+			limits = append(limits, fmt.Sprintf("%d messages", info.MaxMessages))
+		}
+		if info.MaxAge > 0 {
+			limits = append(limits, formatAge(info.MaxAge))
+		}
+		if len(limits) > 0 {
+			line += fmt.Sprintf(", starts over after %s", strings.Join(limits, " or "))
+		}
+		lines = append(lines, line+".")
+	} else {
+		lines = append(lines, "Conversation: not started yet.")
+	}
+
+	if r.rec != nil {
+		lines = append(lines, refreshStatusLine(r.rec.Status()))
+	}
+	r.reply(msg, strings.Join(lines, "\n"))
+}
+
+func (r *Router) handleGroupMeHelp(cfg *config.Config, msg groupme.Message) {
+	lines := []string{"Commands (start with \"" + chatTrigger + "\"):"}
+	for _, cmd := range chatCommands() {
+		if cmd.needsReconciler && r.rec == nil {
+			continue
+		}
+		line := fmt.Sprintf("%s - %s", cmd.name, cmd.help)
+		if !cmd.allow(cfg, msg.UserId) {
+			line += " (approved users only)"
+		}
+		lines = append(lines, line)
+	}
+	lines = append(lines, "Anything else after \""+chatTrigger+"\" goes to the assistant.")
+	r.reply(msg, strings.Join(lines, "\n"))
+}
+
+// refreshStatusLine summarizes the reconciler for the status command.
+func refreshStatusLine(status reconciler.Status) string {
+	var line string
+	switch {
+	case status.Running && status.CurrentStep != "":
+		line = fmt.Sprintf("Roster refresh: running (%s)", status.CurrentStep)
+	case status.Running:
+		line = "Roster refresh: running"
+	case status.LastRun == nil:
+		line = "Roster refresh: no runs yet"
+	default:
+		finished := status.LastRun.FinishedAt
+		if finished.IsZero() {
+			finished = status.LastRun.StartedAt
+		}
+		line = fmt.Sprintf("Roster refresh: last run %s %s ago", status.LastRun.Status, formatAge(time.Since(finished)))
+	}
+	if status.CooldownRemaining > 0 {
+		line += fmt.Sprintf(", next one allowed in %s", formatAge(status.CooldownRemaining))
+	}
+	return line + "."
+}
+
+// formatAge renders d in the largest whole unit, e.g. "3 days" or "1 minute".
+func formatAge(d time.Duration) string {
+	unit, n := "minute", int(d/time.Minute)
+	switch {
+	case d < time.Minute:
+		return "under a minute"
+	case d >= 24*time.Hour:
+		unit, n = "day", int(d/(24*time.Hour))
+	case d >= time.Hour:
+		unit, n = "hour", int(d/time.Hour)
+	}
+	if n == 1 {
+		return "1 " + unit
+	}
+	return fmt.Sprintf("%d %ss", n, unit)
+}
@@ -1,51 +1,95 @@
 package router
 
 import (
+	"context"
 	"crowfather/internal/config"
 	"crowfather/internal/groupme"
 	"crowfather/internal/handlers/meltdown_handler"
 	"crowfather/internal/handlers/message_handler"
 	"crowfather/internal/handlers/test_handler"
+	"crowfather/internal/jobs"
 	"crowfather/internal/open_ai"
 	"crowfather/internal/reconciler"
+	"errors"
 	"fmt"
 	"net/http"
-	"strings"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 )
 
+// Router serves requests with one set of services and the config they were
+// built from. A reload builds a new Router with WithServices rather than
+// changing one in use.
 type Router struct {
 	oai             *open_ai.OpenAIService
 	gms             *groupme.GroupMeService
 	rec             *reconciler.Reconciler // nil if reconciler is not configured
+	queue           *jobs.Queue            // nil processes GroupMe messages inline
 	messageHandler  func(groupme.Message, *open_ai.OpenAIService, *groupme.GroupMeService, string) (string, error)
+	messageJob      func(groupme.Message, *open_ai.OpenAIService, *groupme.GroupMeService, string) jobs.Job
 	testHandler     func(string, *open_ai.OpenAIService, string) (string, error)
 	meltdownHandler func(string, *open_ai.OpenAIService, string) (string, error)
 	config          *config.Config
 }
 
-func NewRouter(oai *open_ai.OpenAIService, gms *groupme.GroupMeService, rec *reconciler.Reconciler, config *config.Config) (*Router, error) {
+func NewRouter(oai *open_ai.OpenAIService, gms *groupme.GroupMeService, rec *reconciler.Reconciler, queue *jobs.Queue, config *config.Config) (*Router, error) {
 	return &Router{
 		messageHandler:  message_handler.Handle,
+		messageJob:      message_handler.NewJob,
 		testHandler:     test_handler.Handle,
 		meltdownHandler: meltdown_handler.Handle,
 		oai:             oai,
 		gms:             gms,
 		rec:             rec,
+		queue:           queue,
 		config:          config,
 	}, nil
 }
 
+// WithServices returns a router for a reloaded config and the services
+// built for it. It shares r's queue and handlers.
+func (r *Router) WithServices(oai *open_ai.OpenAIService, gms *groupme.GroupMeService, rec *reconciler.Reconciler, cfg *config.Config) *Router {
+	next := *r
+	next.oai, next.gms, next.rec, next.config = oai, gms, rec, cfg
+	return &next
+}
+
+// RegisterRoutes serves every request with r.
 func (r *Router) RegisterRoutes(engine *gin.Engine) {
-	engine.GET("/ping", r.handlePing)
-	engine.POST("/message", r.processGroupMeMessage)
-	engine.POST("/meltdown", r.processMeltdownMessage)
+	RegisterRoutes(engine, func() *Router { return r })
+}
 
-	base := engine.Group("/")
-	base.Use(AuthMiddleware(r.config.Auth.APIKey))
-	base.POST("/test", r.processTestMessage)
-	base.POST("/refresh", r.handleRefresh)
+// routerKey is the gin context key of the router serving a request.
+const routerKey = "router"
+
+// RegisterRoutes serves each request with the router current returns when it
+// arrives. A reload swaps the router current returns, and requests already
+// being handled finish with the one they started with.
+func RegisterRoutes(engine *gin.Engine, current func() *Router) {
+	all := engine.Group("/", func(c *gin.Context) { c.Set(routerKey, current()) })
+	all.GET("/ping", serve((*Router).handlePing))
+	all.POST("/message", serve((*Router).processGroupMeMessage))
+	all.POST("/meltdown", serve((*Router).processMeltdownMessage))
+
+	base := all.Group("/")
+	base.Use(dynamicAuthMiddleware(func(c *gin.Context) string { return routerFor(c).config.Auth.APIKey }))
+	base.POST("/test", serve((*Router).processTestMessage))
+	base.POST("/refresh", serve((*Router).handleRefresh))
+	base.GET("/reconcile/status", serve((*Router).handleReconcileStatus))
+	base.GET("/reconcile/runs", serve((*Router).handleReconcileRuns))
+	base.GET("/reconcile/runs/:id", serve((*Router).handleReconcileRun))
+}
+
+// serve adapts a Router method to a handler for the request's router.
+func serve(handle func(*Router, *gin.Context)) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		handle(routerFor(c), c)
+	}
+}
+
+func routerFor(c *gin.Context) *Router {
+	return c.MustGet(routerKey).(*Router)
 }
 
 func (r *Router) handlePing(c *gin.Context) {
@@ -60,18 +104,23 @@ func (r *Router) processGroupMeMessage(c *gin.Context) {
 	if err := c.BindJSON(&msg); err != nil {
 		return
 	}
+	cfg := r.config
 
-	// Check for the GroupMe refresh trigger before routing to OpenAI.
-	if r.rec != nil && isRefreshTrigger(msg.Text) {
-		reply := r.handleGroupMeRefresh(msg)
-		if err := r.gms.SendRawMessage(reply); err != nil {
-			fmt.Printf("router: failed to send refresh reply: %v\n", err)
+	// Chat commands are handled here rather than by the assistant.
+	if message_handler.ValidateMessage(msg) == nil {
+		if cmd := r.matchCommand(msg.Text); cmd != nil {
+			r.runCommand(cmd, cfg, msg)
+			c.JSON(http.StatusOK, gin.H{})
+			return
 		}
-		c.JSON(http.StatusOK, gin.H{})
+	}
+
+	if r.queue != nil {
+		r.enqueueGroupMeMessage(c, cfg, msg)
 		return
 	}
 
-	response, err := r.messageHandler(msg, r.oai, r.gms, r.config.Assistants.GroupMeAssistantID)
+	response, err := r.messageHandler(msg, r.oai, r.gms, r.groupAssistantID(cfg, msg.GroupId))
 
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -85,6 +134,35 @@ func (r *Router) processGroupMeMessage(c *gin.Context) {
 	})
 }
 
+// enqueueGroupMeMessage acknowledges the webhook immediately and hands the
+// OpenAI round trip to the background queue, which posts the reply when ready.
+func (r *Router) enqueueGroupMeMessage(c *gin.Context, cfg *config.Config, msg groupme.Message) {
+	if err := message_handler.ValidateMessage(msg); err != nil {
+		c.JSON(http.StatusOK, gin.H{"status": "ignored"})
+		return
+	}
+
+	job := r.messageJob(msg, r.oai, r.gms, r.groupAssistantID(cfg, msg.GroupId))
+	if err := r.queue.Enqueue(job); err != nil {
+		fmt.Printf("router: failed to enqueue message %s: %v\n", msg.Id, err)
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"status": "queued"})
+}
+
+// groupAssistantID returns the assistant bound to the GroupMe group, falling
+// back to the default GroupMe assistant in cfg.
+func (r *Router) groupAssistantID(cfg *config.Config, groupID string) string {
+	if r.gms != nil {
+		if b, ok := r.gms.Group(groupID); ok && b.AssistantID != "" {
+			return b.AssistantID
+		}
+	}
+	return cfg.Assistants.GroupMeAssistantID
+}
+
 func (r *Router) processTestMessage(c *gin.Context) {
 	var message struct {
 		Text string `json:"text"`
@@ -133,13 +211,27 @@ func (r *Router) processMeltdownMessage(c *gin.Context) {
 
 // handleRefresh is the HTTP-triggered reconciliation endpoint (POST /refresh).
 // Protected by API key middleware. Returns 202 immediately; run is asynchronous.
+// With ?dry_run=true it instead renders the documents synchronously and
+// returns them with a diff against the previous run, uploading nothing.
 func (r *Router) handleRefresh(c *gin.Context) {
 	if r.rec == nil {
 		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "reconciler not configured"})
 		return
 	}
 
-	triggered, reason := r.rec.Trigger("", nil)
+	if v := c.Query("dry_run"); v != "" {
+		dryRun, err := strconv.ParseBool(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "dry_run must be a boolean"})
+			return
+		}
+		if dryRun {
+			r.handleDryRun(c)
+			return
+		}
+	}
+
+	triggered, reason := r.rec.TriggerFrom(reconciler.SourceHTTP, nil)
 	if !triggered {
 		c.JSON(http.StatusConflict, gin.H{"error": reason})
 		return
@@ -148,36 +240,170 @@ func (r *Router) handleRefresh(c *gin.Context) {
 	c.JSON(http.StatusAccepted, gin.H{"status": "refresh started"})
 }
 
+func (r *Router) handleDryRun(c *gin.Context) {
+	result, err := r.rec.DryRun(c.Request.Context(), "", "")
+	if err != nil {
+		c.JSON(dryRunErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// dryRunErrorStatus maps a dry run error to its HTTP status. A refresh in
+// progress or a cooldown is a conflict, as it is for a real refresh.
+func dryRunErrorStatus(err error) int {
+	var cooldown *reconciler.CooldownError
+	switch {
+	case errors.Is(err, reconciler.ErrShuttingDown):
+		return http.StatusServiceUnavailable
+	case errors.Is(err, reconciler.ErrRunning), errors.As(err, &cooldown):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// handleReconcileStatus reports whether a run is in progress, its current
+// step, the remaining cooldown and the last run (GET /reconcile/status).
+func (r *Router) handleReconcileStatus(c *gin.Context) {
+	if r.rec == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "reconciler not configured"})
+		return
+	}
+
+	status := r.rec.Status()
+	c.JSON(http.StatusOK, gin.H{
+		"running":                    status.Running,
+		"current_step":               status.CurrentStep,
+		"cooldown_remaining_seconds": int(status.CooldownRemaining.Seconds()),
+		"last_run":                   status.LastRun,
+	})
+}
+
+// handleReconcileRuns lists recent runs, newest first (GET /reconcile/runs).
+// ?limit= caps the result at 100; the default is 20.
+func (r *Router) handleReconcileRuns(c *gin.Context) {
+	if r.rec == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "reconciler not configured"})
+		return
+	}
+
+	limit := 20
+	if v := c.Query("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be a positive integer"})
+			return
+		}
+		limit = min(n, 100)
+	}
+
+	runs, err := r.rec.Runs(c.Request.Context(), limit)
+	if err != nil {
+		c.JSON(reconcileErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	if runs == nil {
+		runs = []reconciler.RunRecord{}
+	}
+	c.JSON(http.StatusOK, gin.H{"runs": runs})
+}
+
+// handleReconcileRun returns one run with the documents it generated
+// (GET /reconcile/runs/:id).
+func (r *Router) handleReconcileRun(c *gin.Context) {
+	if r.rec == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "reconciler not configured"})
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "run id must be an integer"})
+		return
+	}
+
+	run, docs, err := r.rec.Run(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(reconcileErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	if docs == nil {
+		docs = []reconciler.RunDocument{}
+	}
+	c.JSON(http.StatusOK, gin.H{"run": run, "documents": docs})
+}
+
+func reconcileErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, reconciler.ErrRunNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, reconciler.ErrNoHistory):
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
 // handleGroupMeRefresh processes the GroupMe refresh trigger keyword.
-// It sends an immediate acknowledgement and the notify callback posts the result.
+// It sends an immediate acknowledgement and the notify callback posts the result
+// back to the group that asked.
 func (r *Router) handleGroupMeRefresh(msg groupme.Message) string {
 	notify := func(summary string) {
-		if err := r.gms.SendRawMessage(summary); err != nil {
+		if err := r.gms.SendGroupMessage(msg.GroupId, summary); err != nil {
 			fmt.Printf("router: failed to send refresh summary: %v\n", err)
 		}
 	}
 
-	triggered, reason := r.rec.Trigger(msg.UserId, notify)
+	triggered, reason := r.rec.TriggerForGroup(msg.UserId, msg.GroupId, notify)
 	if triggered {
 		return fmt.Sprintf("@%s On it! I'll post an update when the roster refresh is done.", msg.Name)
 	}
 	return fmt.Sprintf("@%s %s", msg.Name, reason)
 }
 
-// isRefreshTrigger returns true if the message text contains the refresh keyword.
-func isRefreshTrigger(text string) bool {
-	return strings.Contains(strings.ToLower(text), "hey crowfather refresh")
+// handleGroupMeDryRun processes the GroupMe dry-run keyword. The dry run
+// fetches league data, so after an acknowledgement it runs in the background
+// and posts its summary to the group when done.
+func (r *Router) handleGroupMeDryRun(msg groupme.Message) {
+	ack := fmt.Sprintf("@%s Previewing a roster refresh, nothing will be uploaded.", msg.Name)
+	if err := r.gms.SendGroupMessage(msg.GroupId, ack); err != nil {
+		fmt.Printf("router: failed to send dry run reply: %v\n", err)
+	}
+
+	go func() {
+		result, err := r.rec.DryRun(context.Background(), msg.UserId, msg.GroupId)
+		var reply string
+		switch {
+		case errors.Is(err, reconciler.ErrNotAuthorized):
+			reply = fmt.Sprintf("@%s You're not authorized to trigger a roster refresh.", msg.Name)
+		case err != nil:
+			reply = fmt.Sprintf("Dry run failed: %v", err)
+		default:
+			reply = result.Summary()
+		}
+		if err := r.gms.SendGroupMessage(msg.GroupId, reply); err != nil {
+			fmt.Printf("router: failed to send dry run summary: %v\n", err)
+		}
+	}()
 }
 
 func AuthMiddleware(apiKey string) gin.HandlerFunc {
+	return dynamicAuthMiddleware(func(*gin.Context) string { return apiKey })
+}
+
+// dynamicAuthMiddleware checks each request against the key apiKey returns
+// for it, so a reloaded key applies to the next request.
+func dynamicAuthMiddleware(apiKey func(*gin.Context) string) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		want := apiKey(c)
 		key := c.GetHeader("Authorization")
 
 		if key == "" {
 			key = c.Query("api_key")
 		}
 
-		if key == "" || key != apiKey {
+		if key == "" || key != want {
 			c.JSON(http.StatusUnauthorized, gin.H{
 				"error": "unauthorized",
 			})
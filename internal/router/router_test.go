@@ -1,36 +1,182 @@
 package router
 
 import (
+	"context"
+	"crowfather/internal/config"
+	"crowfather/internal/groupme"
+	"crowfather/internal/jobs"
+	"crowfather/internal/open_ai"
+	"crowfather/internal/reconciler"
+	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func init() {
 	gin.SetMode(gin.TestMode)
 }
 
-func TestIsRefreshTrigger(t *testing.T) {
+func TestMatchCommand(t *testing.T) {
+	r := &Router{rec: reconciler.NewReconciler(nil, nil, nil, nil, nil, nil, 2, 0, nil)}
 	cases := []struct {
-		text    string
-		want    bool
+		text string
+		want string // "" for no command
 	}{
-		{"hey crowfather refresh", true},
-		{"HEY CROWFATHER REFRESH", true},
-		{"Hey Crowfather Refresh the rosters", true},
-		{"please hey crowfather refresh now", true},
-		{"hey crowfather, what's up?", false},
-		{"refresh the rosters", false},
-		{"", false},
+		{"hey crowfather refresh", "refresh"},
+		{"HEY CROWFATHER REFRESH", "refresh"},
+		{"Hey Crowfather Refresh the rosters", "refresh"},
+		{"please hey crowfather refresh now", "refresh"},
+		{"Hey Crowfather refresh dry run", "refresh dry run"},
+		{"hey crowfather, reset!", "reset"},
+		{"hey crowfather status", "status"},
+		{"hey crowfather help", "help"},
+		{"hey crowfather standings?", "standings"},
+		{"hey crowfather help me pick a QB", ""},
+		{"hey crowfather reset the scoreboard for me", ""},
+		{"hey crowfather, what's up?", ""},
+		{"refresh the rosters", ""},
+		{"", ""},
 	}
 	for _, tc := range cases {
-		assert.Equal(t, tc.want, isRefreshTrigger(tc.text), "input: %q", tc.text)
+		got := ""
+		if cmd := r.matchCommand(tc.text); cmd != nil {
+			got = cmd.name
+		}
+		assert.Equal(t, tc.want, got, "input: %q", tc.text)
 	}
 }
 
+// Refresh commands are left to the assistant when no reconciler is configured.
+func TestMatchCommand_WithoutReconciler(t *testing.T) {
+	r := &Router{}
+	assert.Nil(t, r.matchCommand("hey crowfather refresh"))
+	assert.Nil(t, r.matchCommand("hey crowfather standings"))
+	assert.NotNil(t, r.matchCommand("hey crowfather status"))
+}
+
+// newGroupMeServer returns a GroupMe service whose sent messages arrive on
+// the returned channel.
+func newGroupMeServer(t *testing.T) (*groupme.GroupMeService, <-chan string) {
+	t.Helper()
+	sent := make(chan string, 10)
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var body groupme.MessageSendRequest
+		json.NewDecoder(req.Body).Decode(&body)
+		sent <- body.Text
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	t.Cleanup(server.Close)
+
+	u, _ := url.Parse(server.URL)
+	gms := &groupme.GroupMeService{
+		Client: server.Client(),
+		Config: &config.GroupMeConfig{BotID: "bot", Host: u.Host, Path: u.Path, Timeout: 5 * time.Second},
+	}
+	return gms, sent
+}
+
+func newCommandTestRouter(t *testing.T, approved ...string) (*Router, <-chan string) {
+	gms, sent := newGroupMeServer(t)
+	oai := open_ai.NewOpenAIService(&config.OpenAIConfig{}, nil)
+	oai.ThreadIds["g1"] = "thread_1"
+	return &Router{
+		gms: gms,
+		oai: oai,
+		config: &config.Config{
+			Assistants: &config.Assistants{GroupMeAssistantID: "asst"},
+			Reconciler: &config.ReconcilerConfig{ApprovedUsers: approved},
+		},
+	}, sent
+}
+
+func TestProcessGroupMeMessage_CommandRequiresApproval(t *testing.T) {
+	r, sent := newCommandTestRouter(t, "u1")
+
+	w := postMessage(r, `{"id":"m1","sender_type":"user","user_id":"u2","name":"Sam","group_id":"g1","text":"hey crowfather reset"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `@Sam Only approved users can use "hey crowfather reset".`, <-sent)
+	assert.Equal(t, "thread_1", r.oai.GetThreadId("g1"))
+}
+
+func TestProcessGroupMeMessage_StatusAndHelp(t *testing.T) {
+	r, sent := newCommandTestRouter(t, "u1")
+
+	postMessage(r, `{"id":"m1","sender_type":"user","user_id":"u2","group_id":"g1","text":"hey crowfather status"}`)
+	assert.Equal(t, "Conversation: 0 message(s), started under a minute ago.", <-sent)
+
+	postMessage(r, `{"id":"m2","sender_type":"user","user_id":"u2","group_id":"g1","text":"Hey Crowfather help"}`)
+	help := <-sent
+	assert.Contains(t, help, "reset - start a new conversation thread, keeping a summary of this one (approved users only)")
+	assert.Contains(t, help, "status - show the conversation thread and roster refresh status\n")
+	assert.NotContains(t, help, "refresh -")
+}
+
+// Messages from bots, including the bot's own replies, never run commands.
+func TestProcessGroupMeMessage_IgnoresBotCommands(t *testing.T) {
+	queue := jobs.NewQueue(1, 1, 1, 0)
+	r, sent := newCommandTestRouter(t)
+	r.queue = queue
+
+	w := postMessage(r, `{"id":"m1","sender_type":"bot","group_id":"g1","text":"hey crowfather help"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "ignored")
+	assert.Empty(t, sent)
+}
+
+func TestFormatAge(t *testing.T) {
+	assert.Equal(t, "under a minute", formatAge(30*time.Second))
+	assert.Equal(t, "1 minute", formatAge(90*time.Second))
+	assert.Equal(t, "5 hours", formatAge(5*time.Hour+10*time.Minute))
+	assert.Equal(t, "30 days", formatAge(30*24*time.Hour))
+}
+
+func TestHandleRefresh_InvalidDryRun_Returns400(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodPost, "/refresh?dry_run=maybe", nil)
+
+	r := &Router{rec: reconciler.NewReconciler(nil, nil, nil, nil, nil, nil, 2, 0, nil)}
+	r.handleRefresh(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+// A dry run refused for a run in progress or the cooldown is a conflict,
+// like a refresh refused for the same reasons.
+func TestHandleRefresh_DryRunCooldown_Returns409(t *testing.T) {
+	rec := reconciler.NewReconciler(nil, nil, nil, nil, nil, nil, 2, time.Hour, nil)
+	done := make(chan string, 1)
+	triggered, _ := rec.TriggerFrom(reconciler.SourceHTTP, func(s string) { done <- s })
+	require.True(t, triggered)
+	<-done
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodPost, "/refresh?dry_run=true", nil)
+	r := &Router{rec: rec}
+	r.handleRefresh(c)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Contains(t, w.Body.String(), "just refreshed")
+}
+
+func TestDryRunErrorStatus(t *testing.T) {
+	assert.Equal(t, http.StatusConflict, dryRunErrorStatus(reconciler.ErrRunning))
+	assert.Equal(t, http.StatusConflict, dryRunErrorStatus(&reconciler.CooldownError{Remaining: time.Minute}))
+	assert.Equal(t, http.StatusServiceUnavailable, dryRunErrorStatus(reconciler.ErrShuttingDown))
+	assert.Equal(t, http.StatusInternalServerError, dryRunErrorStatus(errors.New("sleeper down")))
+}
+
 func TestHandleRefresh_NilReconciler_Returns503(t *testing.T) {
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
@@ -41,3 +187,166 @@ func TestHandleRefresh_NilReconciler_Returns503(t *testing.T) {
 
 	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
 }
+
+func newQueueTestRouter(queue *jobs.Queue, ran chan<- groupme.Message) *Router {
+	return &Router{
+		queue:  queue,
+		config: &config.Config{Assistants: &config.Assistants{GroupMeAssistantID: "asst"}},
+		messageJob: func(msg groupme.Message, _ *open_ai.OpenAIService, _ *groupme.GroupMeService, _ string) jobs.Job {
+			return jobs.Job{Name: "test", Run: func(context.Context) error {
+				ran <- msg
+				return nil
+			}}
+		},
+	}
+}
+
+func postMessage(r *Router, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(http.MethodPost, "/message", strings.NewReader(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	r.processGroupMeMessage(c)
+	return w
+}
+
+func TestProcessGroupMeMessage_QueuesAndAcks(t *testing.T) {
+	queue := jobs.NewQueue(1, 1, 1, 0)
+	queue.Start(context.Background())
+	ran := make(chan groupme.Message, 1)
+
+	w := postMessage(newQueueTestRouter(queue, ran), `{"id":"m1","sender_type":"user","text":"hey crowfather hi"}`)
+	assert.Equal(t, http.StatusAccepted, w.Code)
+
+	select {
+	case msg := <-ran:
+		assert.Equal(t, "m1", msg.Id)
+	case <-time.After(time.Second):
+		t.Error("queued job did not run")
+	}
+	queue.Stop(context.Background())
+}
+
+func TestProcessGroupMeMessage_IgnoresBotMessages(t *testing.T) {
+	queue := jobs.NewQueue(1, 1, 1, 0)
+	ran := make(chan groupme.Message, 1)
+
+	w := postMessage(newQueueTestRouter(queue, ran), `{"id":"m1","sender_type":"bot","text":"hello"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "ignored")
+}
+
+func TestProcessGroupMeMessage_QueueFullReturns503(t *testing.T) {
+	queue := jobs.NewQueue(1, 0, 1, 0) // unbuffered and not started: every enqueue fails
+	ran := make(chan groupme.Message, 1)
+
+	w := postMessage(newQueueTestRouter(queue, ran), `{"id":"m1","sender_type":"user","text":"hey crowfather hi"}`)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
+
+func TestGroupAssistantID(t *testing.T) {
+	gms := groupme.NewGroupMeService(&config.GroupMeConfig{
+		Groups: []config.GroupBinding{
+			{GroupID: "g1", BotID: "bot1", AssistantID: "asst_g1"},
+			{GroupID: "g2", BotID: "bot2"},
+		},
+	})
+	cfg := &config.Config{Assistants: &config.Assistants{GroupMeAssistantID: "asst_default"}}
+	r := &Router{gms: gms, config: cfg}
+
+	assert.Equal(t, "asst_g1", r.groupAssistantID(cfg, "g1"))
+	assert.Equal(t, "asst_default", r.groupAssistantID(cfg, "g2"))
+	assert.Equal(t, "asst_default", r.groupAssistantID(cfg, "unknown"))
+}
+
+func TestRegisterRoutes_ServesCurrentRouter(t *testing.T) {
+	var current atomic.Pointer[Router]
+	current.Store(&Router{config: &config.Config{Auth: &config.AuthConfig{APIKey: "old"}}})
+	engine := gin.New()
+	RegisterRoutes(engine, current.Load)
+
+	refresh := func(key string) int {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/refresh", nil)
+		req.Header.Set("Authorization", key)
+		engine.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusServiceUnavailable, refresh("old"))
+
+	current.Store(&Router{config: &config.Config{Auth: &config.AuthConfig{APIKey: "new"}}})
+	assert.Equal(t, http.StatusUnauthorized, refresh("old"))
+	assert.Equal(t, http.StatusServiceUnavailable, refresh("new"))
+}
+
+type stubRunRepo struct {
+	runs []reconciler.RunRecord
+	docs []reconciler.RunDocument
+}
+
+func (s *stubRunRepo) CreateRun(context.Context, reconciler.RunRecord) (int64, error) { return 0, nil }
+func (s *stubRunRepo) UpdateRun(context.Context, reconciler.RunRecord) error          { return nil }
+func (s *stubRunRepo) LastRun(context.Context) (*reconciler.RunRecord, error)         { return nil, nil }
+func (s *stubRunRepo) ListRuns(context.Context, int) ([]reconciler.RunRecord, error) {
+	return s.runs, nil
+}
+func (s *stubRunRepo) GetRun(_ context.Context, id int64) (*reconciler.RunRecord, error) {
+	for _, run := range s.runs {
+		if run.ID == id {
+			return &run, nil
+		}
+	}
+	return nil, nil
+}
+func (s *stubRunRepo) SaveRunDocuments(context.Context, int64, []reconciler.RunDocument) error {
+	return nil
+}
+func (s *stubRunRepo) ListRunDocuments(context.Context, int64) ([]reconciler.RunDocument, error) {
+	return s.docs, nil
+}
+
+func adminRequest(r *Router, path string) *httptest.ResponseRecorder {
+	engine := gin.New()
+	r.RegisterRoutes(engine)
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, path, nil)
+	req.Header.Set("Authorization", "key")
+	engine.ServeHTTP(w, req)
+	return w
+}
+
+func TestReconcileEndpoints(t *testing.T) {
+	repo := &stubRunRepo{
+		runs: []reconciler.RunRecord{{ID: 7, Trigger: reconciler.SourceHTTP, Status: reconciler.StatusFailed, Error: "espn fetch failed"}},
+		docs: []reconciler.RunDocument{{Name: "nfl_team_1.md", Content: "# Team"}},
+	}
+	rec := reconciler.NewReconciler(nil, nil, nil, nil, repo, nil, 2, 0, nil)
+	r := &Router{rec: rec, config: &config.Config{Auth: &config.AuthConfig{APIKey: "key"}}}
+
+	w := adminRequest(r, "/reconcile/status")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"running":false`)
+
+	w = adminRequest(r, "/reconcile/runs?limit=5")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "espn fetch failed")
+
+	w = adminRequest(r, "/reconcile/runs/7")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "nfl_team_1.md")
+
+	assert.Equal(t, http.StatusNotFound, adminRequest(r, "/reconcile/runs/8").Code)
+	assert.Equal(t, http.StatusBadRequest, adminRequest(r, "/reconcile/runs/abc").Code)
+	assert.Equal(t, http.StatusBadRequest, adminRequest(r, "/reconcile/runs?limit=0").Code)
+}
+
+func TestReconcileEndpoints_WithoutHistoryOrReconciler(t *testing.T) {
+	cfg := &config.Config{Auth: &config.AuthConfig{APIKey: "key"}}
+	r := &Router{rec: reconciler.NewReconciler(nil, nil, nil, nil, nil, nil, 2, 0, nil), config: cfg}
+	assert.Equal(t, http.StatusServiceUnavailable, adminRequest(r, "/reconcile/runs").Code)
+	assert.Equal(t, http.StatusOK, adminRequest(r, "/reconcile/status").Code)
+
+	r = &Router{config: cfg}
+	assert.Equal(t, http.StatusServiceUnavailable, adminRequest(r, "/reconcile/status").Code)
+}
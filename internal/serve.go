@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"crowfather/internal/config"
+	"crowfather/internal/jobs"
+	"crowfather/internal/reconciler"
+	"crowfather/internal/router"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// configPollInterval is how often CONFIG_FILE is checked for changes.
+const configPollInterval = 5 * time.Second
+
+// shutdownTimeout bounds how long SIGTERM waits for in-flight HTTP requests,
+// queued jobs and a reconciliation run before giving up on them.
+const shutdownTimeout = 30 * time.Second
+
+// serve runs the HTTP server until ctx is cancelled, then shuts down gracefully.
+func serve(ctx context.Context, args []string) error {
+	if err := flag.NewFlagSet("serve", flag.ContinueOnError).Parse(args); err != nil {
+		return err
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	// Database — optional. Service runs in memory-only mode while it is
+	// unavailable and keeps trying to connect in the background.
+	repos := openRepositories(ctx, cfg.Database)
+
+	// Reconciler — optional. Only constructed when SLEEPER_LEAGUE_IDS is set.
+	svcs, err := newServices(cfg, repos)
+	if err != nil {
+		return err
+	}
+	rec := svcs.rec
+	live := &liveServices{}
+
+	var reposMu sync.Mutex
+	supervised := make(chan struct{})
+	if cfg.Database != nil && repos.db == nil {
+		go func() {
+			defer close(supervised)
+			superviseDatabase(ctx, cfg.Database, svcs.oai, svcs.gms, rec, func(opened *repositories) {
+				reposMu.Lock()
+				defer reposMu.Unlock()
+				repos = opened
+			})
+		}()
+	} else {
+		close(supervised)
+	}
+
+	// Background queue for GroupMe webhooks so callbacks are acknowledged
+	// before the OpenAI round trip completes.
+	queue := jobs.NewQueue(cfg.Jobs.Workers, cfg.Jobs.QueueSize, cfg.Jobs.MaxAttempts, cfg.Jobs.RetryBackoff)
+	queue.Start(context.Background())
+
+	svcs.router, err = router.NewRouter(svcs.oai, svcs.gms, rec, queue, cfg)
+	if err != nil {
+		return err
+	}
+	live.current.Store(svcs)
+
+	if rec != nil {
+		// Startup trigger.
+		if cfg.Reconciler.OnStartup {
+			fmt.Println("Starting initial roster reconciliation...")
+			rec.TriggerFrom(reconciler.SourceStartup, nil)
+		}
+
+		// Periodic cron goroutine, stopped on shutdown.
+		live.cron = time.NewTicker(cfg.Reconciler.Interval)
+		go func() {
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-live.cron.C:
+					fmt.Println("Cron: triggering scheduled roster reconciliation")
+					live.current.Load().rec.TriggerFrom(reconciler.SourceCron, nil)
+				}
+			}
+		}()
+	}
+
+	// Reload on SIGHUP or config file change.
+	go config.Watch(ctx, configPollInterval, live.reload)
+
+	engine := gin.Default()
+	router.RegisterRoutes(engine, func() *router.Router { return live.current.Load().router })
+
+	serveCtx, stop := context.WithCancel(ctx)
+	defer stop()
+
+	srv := &http.Server{Addr: listenAddr(), Handler: engine}
+	go func() {
+		fmt.Printf("Listening on %s\n", srv.Addr)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			fmt.Printf("HTTP server failed: %v\n", err)
+			stop()
+		}
+	}()
+
+	<-serveCtx.Done()
+	fmt.Println("Shutting down...")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	// Stop taking requests first so nothing new reaches the queue or the
+	// reconciler, then drain them in turn.
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		fmt.Printf("HTTP server shutdown incomplete: %v\n", err)
+	}
+	if live.cron != nil {
+		live.cron.Stop()
+	}
+	if err := queue.Stop(shutdownCtx); err != nil {
+		fmt.Printf("Job queue shutdown incomplete: %v\n", err)
+	}
+	if rec != nil {
+		if err := rec.Shutdown(shutdownCtx); err != nil {
+			fmt.Printf("Reconciliation run did not finish before shutdown: %v\n", err)
+		}
+	}
+	<-supervised
+	reposMu.Lock()
+	repos.Close()
+	reposMu.Unlock()
+	fmt.Println("Shutdown complete")
+	return nil
+}
+
+// listenAddr mirrors gin's default: PORT if set, otherwise :8080.
+func listenAddr() string {
+	if port := os.Getenv("PORT"); port != "" {
+		return ":" + port
+	}
+	return ":8080"
+}
@@ -0,0 +1,279 @@
+package main
+
+import (
+	"context"
+	"crowfather/internal/config"
+	"crowfather/internal/database"
+	"crowfather/internal/espn"
+	"crowfather/internal/groupme"
+	"crowfather/internal/open_ai"
+	"crowfather/internal/reconciler"
+	"crowfather/internal/router"
+	"crowfather/internal/sleeper"
+	"crowfather/internal/sleeper_tools"
+	"errors"
+	"fmt"
+)
+
+// repositories holds the Postgres-backed repositories. All are nil when the
+// database is unavailable or its schema could not be migrated, so callers
+// fall back to memory-only behaviour.
+type repositories struct {
+	db      *database.DatabaseService
+	threads *database.PgThreadRepository
+	meta    *database.PgMetadataRepository
+	groups  *database.PgGroupRepository
+	runs    *database.PgRunRepository
+}
+
+// openRepositories connects to the database and applies pending migrations.
+// A nil cfg means no database is configured.
+func openRepositories(ctx context.Context, cfg *config.DatabaseConfig) *repositories {
+	if cfg == nil {
+		fmt.Println("No database configured, running in memory-only mode")
+		return &repositories{}
+	}
+	repos, err := connectRepositories(ctx, cfg)
+	if err != nil {
+		fmt.Printf("Database unavailable, running in memory-only mode: %v\n", err)
+		return &repositories{}
+	}
+
+	if err := migrateUp(ctx, repos.db); err != nil {
+		fmt.Printf("Schema migration failed, running in memory-only mode: %v\n", err)
+		repos.Close()
+		return &repositories{}
+	}
+	return repos
+}
+
+// connectRepositories connects to the database without migrating.
+func connectRepositories(ctx context.Context, cfg *config.DatabaseConfig) (*repositories, error) {
+	dbSvc, err := database.ConnectDb(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return newRepositories(dbSvc), nil
+}
+
+func newRepositories(dbSvc *database.DatabaseService) *repositories {
+	return &repositories{
+		db:      dbSvc,
+		threads: database.NewPgThreadRepository(dbSvc.DB()),
+		meta:    database.NewPgMetadataRepository(dbSvc.DB()),
+		groups:  database.NewPgGroupRepository(dbSvc.DB()),
+		runs:    database.NewPgRunRepository(dbSvc.DB()),
+	}
+}
+
+// superviseDatabase keeps reconnecting in the background for a service that
+// started without its database. Once connected it migrates the schema, swaps
+// live repositories into the running services and hands them to connected,
+// which owns closing them. rec may be nil.
+func superviseDatabase(ctx context.Context, cfg *config.DatabaseConfig, oai *open_ai.OpenAIService, gms *groupme.GroupMeService, rec *reconciler.Reconciler, connected func(*repositories)) {
+	err := database.Supervise(ctx, cfg, func(ctx context.Context, dbSvc *database.DatabaseService) error {
+		if err := migrateUp(ctx, dbSvc); err != nil {
+			return err
+		}
+		live := newRepositories(dbSvc)
+		oai.SetRepo(live.threads)
+		if rec != nil {
+			rec.SetRepositories(live.meta, live.runs)
+		}
+		if err := gms.LoadGroups(ctx, live.groups); err != nil {
+			fmt.Printf("Using configured GroupMe groups only: %v\n", err)
+		}
+		connected(live)
+		fmt.Println("Database connected, leaving memory-only mode")
+		return nil
+	})
+	if err != nil && !errors.Is(err, context.Canceled) {
+		fmt.Printf("Database supervisor stopped: %v\n", err)
+	}
+}
+
+// migrateUp applies pending schema migrations, logging each one.
+func migrateUp(ctx context.Context, dbSvc *database.DatabaseService) error {
+	migrator, err := database.NewMigrator(dbSvc.DB())
+	if err != nil {
+		return err
+	}
+	applied, err := migrator.Up(ctx)
+	for _, m := range applied {
+		fmt.Printf("Applied migration %04d_%s\n", m.Version, m.Name)
+	}
+	return err
+}
+
+// Close releases the connection pool, if there is one.
+func (r *repositories) Close() {
+	if r.db == nil {
+		return
+	}
+	if err := r.db.Close(); err != nil {
+		fmt.Printf("Failed to close database: %v\n", err)
+	}
+}
+
+// The typed nil pointers above must not leak into interfaces, where they
+// would compare non-nil.
+
+func (r *repositories) threadRepo() open_ai.ThreadRepository {
+	if r.threads == nil {
+		return nil
+	}
+	return r.threads
+}
+
+func (r *repositories) metaRepo() reconciler.MetadataRepository {
+	if r.meta == nil {
+		return nil
+	}
+	return r.meta
+}
+
+func (r *repositories) runRepo() reconciler.RunRepository {
+	if r.runs == nil {
+		return nil
+	}
+	return r.runs
+}
+
+// errNoDatabase is returned by commands that cannot work without Postgres.
+var errNoDatabase = errors.New("this command requires a database")
+
+// requireRepositories connects for commands that need the database and
+// nothing else from the configuration.
+func requireRepositories(ctx context.Context) (*repositories, error) {
+	cfg, err := config.LoadDatabaseConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	if cfg == nil {
+		return nil, fmt.Errorf("%w: set DATABASE_URL or DB_HOST", errNoDatabase)
+	}
+	repos, err := connectRepositories(ctx, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errNoDatabase, err)
+	}
+	return repos, nil
+}
+
+// services are the services built for one config. A reload builds a new set
+// sharing the old one's state, see liveServices.
+type services struct {
+	oai    *open_ai.OpenAIService
+	gms    *groupme.GroupMeService
+	tools  *sleeper_tools.SleeperTools // nil when no Sleeper leagues are configured
+	rec    *reconciler.Reconciler      // nil when no Sleeper leagues are configured
+	router *router.Router              // nil outside serve
+}
+
+// newServices builds the services for cfg, all but the router. The leagues'
+// assistants get the Sleeper tools, and scheduled run summaries are posted
+// to each bound group.
+func newServices(cfg *config.Config, repos *repositories) (*services, error) {
+	oai := open_ai.NewOpenAIService(cfg.OpenAI, repos.threadRepo())
+	oai.SetThreadPolicy(cfg.Threads)
+	s := &services{oai: oai, gms: newGroupMeService(cfg, repos)}
+	if cfg.Reconciler == nil {
+		return s, nil
+	}
+
+	targets, err := leagueTargets(cfg, s.gms.Bindings(cfg.GroupMe))
+	if err != nil {
+		return nil, err
+	}
+	// Live Sleeper tools let the assistant answer from current league data
+	// instead of the last uploaded snapshot.
+	tools := sleeper_tools.NewSleeperTools(sleeper.NewSleeperService(), nil, cfg.Reconciler.TransactionRounds)
+	s.oai, s.tools = bindSleeperTools(oai, tools, targets, cfg.Reconciler.TransactionRounds)
+	s.rec = reconciler.NewReconciler(
+		espn.NewESPNService(),
+		sleeper.NewSleeperService(),
+		s.oai,
+		repos.metaRepo(),
+		repos.runRepo(),
+		targets,
+		cfg.Reconciler.TransactionRounds,
+		cfg.Reconciler.CooldownMinutes,
+		cfg.Reconciler.ApprovedUsers,
+	)
+	notifyGroups(s.rec, s.gms)
+	return s, nil
+}
+
+func newGroupMeService(cfg *config.Config, repos *repositories) *groupme.GroupMeService {
+	gms := groupme.NewGroupMeService(cfg.GroupMe)
+	if repos.groups != nil {
+		if err := gms.LoadGroups(context.Background(), repos.groups); err != nil {
+			fmt.Printf("Using configured GroupMe groups only: %v\n", err)
+		}
+	}
+	return gms
+}
+
+// notifyGroups posts the summaries of rec's scheduled runs through gms.
+func notifyGroups(rec *reconciler.Reconciler, gms *groupme.GroupMeService) {
+	rec.SetGroupNotifier(func(groupID, summary string) {
+		if err := gms.SendGroupMessage(groupID, summary); err != nil {
+			fmt.Printf("Failed to post refresh summary to group %s: %v\n", groupID, err)
+		}
+	})
+}
+
+// leagueTargets resolves each configured league to its GroupMe group and
+// assistant, looking groups up in groups. Unbound leagues, and bound leagues
+// whose group has no assistant of its own, use the default GroupMe
+// assistant; targets that would share an assistant's vector store across
+// groups are rejected.
+func leagueTargets(cfg *config.Config, groups map[string]config.GroupBinding) ([]reconciler.LeagueTarget, error) {
+	bindings := make(map[string]config.LeagueBinding, len(cfg.Reconciler.Leagues))
+	for _, b := range cfg.Reconciler.Leagues {
+		bindings[b.LeagueID] = b
+	}
+
+	targets := make([]reconciler.LeagueTarget, 0, len(cfg.Reconciler.LeagueIDs))
+	for _, id := range cfg.Reconciler.LeagueIDs {
+		target := reconciler.LeagueTarget{
+			LeagueID:    id,
+			AssistantID: cfg.Assistants.GroupMeAssistantID,
+		}
+		if b, ok := bindings[id]; ok {
+			target.GroupID = b.GroupID
+			if b.AssistantID != "" {
+				target.AssistantID = b.AssistantID
+			} else if g, ok := groups[b.GroupID]; ok && g.AssistantID != "" {
+				target.AssistantID = g.AssistantID
+			}
+		}
+		targets = append(targets, target)
+	}
+	if err := reconciler.ValidateTargets(targets); err != nil {
+		return nil, err
+	}
+	return targets, nil
+}
+
+// bindSleeperTools returns oai and tools for the targets' leagues: the tools
+// are scoped to each assistant's own leagues and registered for a new
+// service that gives them to those assistants only. tools may be nil.
+func bindSleeperTools(oai *open_ai.OpenAIService, tools *sleeper_tools.SleeperTools, targets []reconciler.LeagueTarget, transRounds int) (*open_ai.OpenAIService, *sleeper_tools.SleeperTools) {
+	scopes := make(map[string][]string)
+	var assistants []string
+	for _, t := range targets {
+		if _, ok := scopes[t.AssistantID]; !ok {
+			assistants = append(assistants, t.AssistantID)
+		}
+		scopes[t.AssistantID] = append(scopes[t.AssistantID], t.LeagueID)
+	}
+
+	reg := open_ai.NewToolRegistry()
+	if tools != nil {
+		tools = tools.WithLeagues(scopes, transRounds)
+		if err := tools.Register(reg); err != nil {
+			fmt.Printf("Failed to register Sleeper tools: %v\n", err)
+		}
+	}
+	return oai.WithTools(reg, assistants), tools
+}
@@ -5,20 +5,35 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"sync"
 	"time"
 )
 
 const defaultBaseURL = "https://api.sleeper.app/v1"
 
+// stateTTL bounds how long the NFL state is reused. The week only changes
+// once a week, so an hour keeps it fresh without a request per call.
+const stateTTL = time.Hour
+
 type SleeperService struct {
 	client  *http.Client
 	baseURL string
+
+	stateMu sync.Mutex
+	state   *NFLState
+	stateAt time.Time
 }
 
 func NewSleeperService() *SleeperService {
+	return NewSleeperServiceWithBaseURL(defaultBaseURL)
+}
+
+// NewSleeperServiceWithBaseURL points the client at a different Sleeper API
+// root, e.g. a test server.
+func NewSleeperServiceWithBaseURL(baseURL string) *SleeperService {
 	return &SleeperService{
 		client:  &http.Client{Timeout: 30 * time.Second},
-		baseURL: defaultBaseURL,
+		baseURL: baseURL,
 	}
 }
 
@@ -59,24 +74,133 @@ func (s *SleeperService) FetchLeagueUsers(ctx context.Context, leagueID string)
 	return users, nil
 }
 
-// FetchRecentTransactions fetches completed trade transactions for a league,
-// paginating through rounds 1..maxRounds (or until an empty page is returned).
-// Only completed trades are returned.
-func (s *SleeperService) FetchRecentTransactions(ctx context.Context, leagueID string, maxRounds int) ([]Transaction, error) {
+// FetchMatchups fetches a league's games for one week, with each roster's
+// starters and points.
+func (s *SleeperService) FetchMatchups(ctx context.Context, leagueID string, week int) ([]WeekMatchup, error) {
+	var entries []Matchup
+	if err := s.get(ctx, fmt.Sprintf("/league/%s/matchups/%d", leagueID, week), &entries); err != nil {
+		return nil, fmt.Errorf("failed to fetch week %d matchups for league %s: %w", week, leagueID, err)
+	}
+	return PairMatchups(week, entries), nil
+}
+
+// FetchDrafts fetches the drafts of a league's season. Earlier seasons' drafts
+// belong to the leagues it was renewed from; see League.PreviousSeason.
+func (s *SleeperService) FetchDrafts(ctx context.Context, leagueID string) ([]Draft, error) {
+	var drafts []Draft
+	if err := s.get(ctx, fmt.Sprintf("/league/%s/drafts", leagueID), &drafts); err != nil {
+		return nil, fmt.Errorf("failed to fetch drafts for league %s: %w", leagueID, err)
+	}
+	return drafts, nil
+}
+
+// FetchDraftPicks fetches every pick made so far in a draft.
+func (s *SleeperService) FetchDraftPicks(ctx context.Context, draftID string) ([]DraftPick, error) {
+	var picks []DraftPick
+	if err := s.get(ctx, fmt.Sprintf("/draft/%s/picks", draftID), &picks); err != nil {
+		return nil, fmt.Errorf("failed to fetch picks for draft %s: %w", draftID, err)
+	}
+	return picks, nil
+}
+
+// FetchTradedPicks fetches a league's future draft picks that have changed
+// hands. Picks that were never traded still belong to their original roster.
+func (s *SleeperService) FetchTradedPicks(ctx context.Context, leagueID string) ([]TradedPick, error) {
+	var picks []TradedPick
+	if err := s.get(ctx, fmt.Sprintf("/league/%s/traded_picks", leagueID), &picks); err != nil {
+		return nil, fmt.Errorf("failed to fetch traded picks for league %s: %w", leagueID, err)
+	}
+	return picks, nil
+}
+
+// FetchNFLState fetches the current NFL week and season.
+func (s *SleeperService) FetchNFLState(ctx context.Context) (*NFLState, error) {
+	var state NFLState
+	if err := s.get(ctx, "/state/nfl", &state); err != nil {
+		return nil, fmt.Errorf("failed to fetch NFL state: %w", err)
+	}
+	return &state, nil
+}
+
+// NFLState returns the current NFL week and season, fetching it at most once
+// per stateTTL.
+func (s *SleeperService) NFLState(ctx context.Context) (NFLState, error) {
+	s.stateMu.Lock()
+	defer s.stateMu.Unlock()
+
+	if s.state != nil && time.Since(s.stateAt) < stateTTL {
+		return *s.state, nil
+	}
+
+	state, err := s.FetchNFLState(ctx)
+	if err != nil {
+		return NFLState{}, err
+	}
+	s.state = state
+	s.stateAt = time.Now()
+	return *state, nil
+}
+
+// FetchRecentTransactions fetches a league's transactions of every type from
+// the most recent weeks, newest week first. Sleeper files transactions by
+// week ("round"), so the current week comes from NFLState; weeks <= 0 fetches
+// the whole season so far. Callers filter by Type and Status; failed waiver
+// claims are kept so they can show who was outbid.
+//
+// If the NFL state can't be read, weeks 1 through weeks are fetched instead.
+// In the offseason the most recent weeks are the last of the season just
+// played; see fetchOffseasonTransactions.
+func (s *SleeperService) FetchRecentTransactions(ctx context.Context, leagueID string, weeks int) ([]Transaction, error) {
+	state, err := s.NFLState(ctx)
+	if err != nil {
+		fallback := weeksUpTo(regularSeasonWeeks, 0)
+		if weeks > 0 {
+			fallback = weeksUpTo(weeks, weeks)
+		}
+		fmt.Printf("sleeper: %v; fetching transaction rounds 1-%d for league %s\n", err, len(fallback), leagueID)
+		return s.fetchTransactions(ctx, leagueID, fallback)
+	}
+	if state.SeasonType == seasonOff {
+		return s.fetchOffseasonTransactions(ctx, leagueID, weeks)
+	}
+	return s.fetchTransactions(ctx, leagueID, state.TransactionWeeks(weeks))
+}
+
+// fetchOffseasonTransactions fetches the last weeks of the season just
+// played. Once a league has rolled over to the next season, that season lives
+// in the previous league and the new league's offseason moves are filed under
+// its week 1, which comes first.
+func (s *SleeperService) fetchOffseasonTransactions(ctx context.Context, leagueID string, weeks int) ([]Transaction, error) {
+	league, err := s.FetchLeague(ctx, leagueID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch transactions for league %s: %w", leagueID, err)
+	}
+	lastWeeks := weeksUpTo(regularSeasonWeeks, weeks)
+	if league.Status == leagueComplete {
+		return s.fetchTransactions(ctx, leagueID, lastWeeks)
+	}
+
+	current, err := s.fetchTransactions(ctx, leagueID, []int{1})
+	if err != nil || league.PreviousSeason() == "" {
+		return current, err
+	}
+	previous, err := s.fetchTransactions(ctx, league.PreviousSeason(), lastWeeks)
+	if err != nil {
+		return nil, err
+	}
+	return append(current, previous...), nil
+}
+
+// fetchTransactions fetches a league's transactions from each of weeks in
+// order.
+func (s *SleeperService) fetchTransactions(ctx context.Context, leagueID string, weeks []int) ([]Transaction, error) {
 	var all []Transaction
-	for round := 1; round <= maxRounds; round++ {
+	for _, week := range weeks {
 		var page []Transaction
-		if err := s.get(ctx, fmt.Sprintf("/league/%s/transactions/%d", leagueID, round), &page); err != nil {
-			return nil, fmt.Errorf("failed to fetch transactions round %d for league %s: %w", round, leagueID, err)
-		}
-		if len(page) == 0 {
-			break
-		}
-		for _, t := range page {
-			if t.Type == "trade" && t.Status == "complete" {
-				all = append(all, t)
-			}
+		if err := s.get(ctx, fmt.Sprintf("/league/%s/transactions/%d", leagueID, week), &page); err != nil {
+			return nil, fmt.Errorf("failed to fetch transactions round %d for league %s: %w", week, leagueID, err)
 		}
+		all = append(all, page...)
 	}
 	return all, nil
 }
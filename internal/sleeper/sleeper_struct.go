@@ -1,5 +1,11 @@
 package sleeper
 
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
 type SleeperPlayer struct {
 	PlayerID string `json:"player_id"`
 	FullName string `json:"full_name"`
@@ -7,10 +13,93 @@ type SleeperPlayer struct {
 	Team     string `json:"team"` // NFL team abbreviation, e.g. "KC"
 }
 
+// NFLState is Sleeper's view of where the NFL season is.
+type NFLState struct {
+	Week         int    `json:"week"`          // current week; 0 before the season
+	Leg          int    `json:"leg"`           // week new transactions are filed under
+	Season       string `json:"season"`        // e.g. "2025"
+	SeasonType   string `json:"season_type"`   // "pre", "regular", "post" or "off"
+	LeagueSeason string `json:"league_season"` // season new leagues are created for
+	DisplayWeek  int    `json:"display_week"`
+}
+
+// seasonOff is the NFLState.SeasonType between the Super Bowl and the
+// preseason.
+const seasonOff = "off"
+
+// TransactionWeeks returns the last n weeks transactions are filed under,
+// newest first, or every week of the season so far when n <= 0. Before the
+// season starts transactions are filed under week 1.
+func (s NFLState) TransactionWeeks(n int) []int {
+	return weeksUpTo(max(s.Leg, s.Week, 1), n)
+}
+
+// regularSeasonWeeks is the length of the NFL regular season. Fantasy
+// seasons end within it.
+const regularSeasonWeeks = 18
+
+// weeksUpTo returns the n weeks ending with last, newest first, or weeks
+// last through 1 when n <= 0.
+func weeksUpTo(last, n int) []int {
+	first := 1
+	if n > 0 {
+		first = max(last-n+1, 1)
+	}
+
+	weeks := make([]int, 0, last-first+1)
+	for w := last; w >= first; w-- {
+		weeks = append(weeks, w)
+	}
+	return weeks
+}
+
+// ScoredWeeks returns the weeks of the season that have fantasy scores so far,
+// oldest first. The current regular season week is still being played.
+func (s NFLState) ScoredWeeks() []int {
+	last := 0
+	switch s.SeasonType {
+	case "regular":
+		last = min(s.Week, regularSeasonWeeks)
+	case "post":
+		last = regularSeasonWeeks
+	}
+
+	weeks := make([]int, 0, last)
+	for w := 1; w <= last; w++ {
+		weeks = append(weeks, w)
+	}
+	return weeks
+}
+
 type Roster struct {
-	RosterID int      `json:"roster_id"`
-	OwnerID  string   `json:"owner_id"`
-	Players  []string `json:"players"` // Sleeper player IDs
+	RosterID int            `json:"roster_id"`
+	OwnerID  string         `json:"owner_id"`
+	Players  []string       `json:"players"` // Sleeper player IDs
+	Settings RosterSettings `json:"settings"`
+}
+
+// RosterSettings holds a roster's season so far. Sleeper splits points into
+// whole points and hundredths.
+type RosterSettings struct {
+	Wins               int `json:"wins"`
+	Losses             int `json:"losses"`
+	Ties               int `json:"ties"`
+	Fpts               int `json:"fpts"`
+	FptsDecimal        int `json:"fpts_decimal"`
+	FptsAgainst        int `json:"fpts_against"`
+	FptsAgainstDecimal int `json:"fpts_against_decimal"`
+	WaiverPosition     int `json:"waiver_position"`
+	WaiverBudgetUsed   int `json:"waiver_budget_used"` // FAAB spent this season
+}
+
+// PointsFor returns the points the roster has scored this season.
+func (s RosterSettings) PointsFor() float64 {
+	return float64(s.Fpts) + float64(s.FptsDecimal)/100
+}
+
+// PointsAgainst returns the points scored against the roster this season.
+func (s RosterSettings) PointsAgainst() float64 {
+	return float64(s.FptsAgainst) + float64(s.FptsAgainstDecimal)/100
 }
 
 type User struct {
@@ -19,24 +108,210 @@ type User struct {
 }
 
 type League struct {
-	LeagueID string `json:"league_id"`
-	Name     string `json:"name"`
+	LeagueID         string         `json:"league_id"`
+	Name             string         `json:"name"`
+	Season           string         `json:"season"`             // e.g. "2025"
+	Status           string         `json:"status"`             // "pre_draft", "drafting", "in_season" or "complete"
+	PreviousLeagueID string         `json:"previous_league_id"` // the league's previous season; empty or "0" for its first
+	Settings         LeagueSettings `json:"settings"`
+}
+
+// leagueComplete is the League.Status of a league whose season is over.
+const leagueComplete = "complete"
+
+// PreviousSeason returns the ID of the league's previous season, or "" if
+// this is its first.
+func (l League) PreviousSeason() string {
+	if l.PreviousLeagueID == "0" {
+		return ""
+	}
+	return l.PreviousLeagueID
+}
+
+type LeagueSettings struct {
+	WaiverType   int `json:"waiver_type"`   // 2 for FAAB bidding
+	WaiverBudget int `json:"waiver_budget"` // FAAB each team starts the season with
+	DraftRounds  int `json:"draft_rounds"`  // rounds in each future draft
+}
+
+// UsesFAAB reports whether waivers are claimed with FAAB bids.
+func (s LeagueSettings) UsesFAAB() bool {
+	return s.WaiverType == 2 && s.WaiverBudget > 0
 }
 
 type Transaction struct {
-	TransactionID string         `json:"transaction_id"`
-	Type          string         `json:"type"`   // "trade", "waiver", "free_agent"
-	Status        string         `json:"status"` // "complete", "pending", "cancelled"
-	Adds          map[string]int `json:"adds"`   // player_id → roster_id receiving
-	Drops         map[string]int `json:"drops"`  // player_id → roster_id dropping
-	DraftPicks    []TradedPick   `json:"draft_picks"`
-	RosterIDs     []int          `json:"roster_ids"`
-	Created       int64          `json:"created"`
+	TransactionID string               `json:"transaction_id"`
+	Type          string               `json:"type"`   // "trade", "waiver", "free_agent"
+	Status        string               `json:"status"` // "complete", "failed", "pending", "cancelled"
+	Adds          map[string]int       `json:"adds"`   // player_id → roster_id receiving
+	Drops         map[string]int       `json:"drops"`  // player_id → roster_id dropping
+	DraftPicks    []TradedPick         `json:"draft_picks"`
+	WaiverBudget  []WaiverBudgetChange `json:"waiver_budget"` // FAAB traded between rosters
+	Settings      *TransactionSettings `json:"settings"`      // nil for most non-waiver transactions
+	RosterIDs     []int                `json:"roster_ids"`
+	Leg           int                  `json:"leg"` // week the transaction was processed in
+	Created       int64                `json:"created"`
 }
 
+// Transaction types.
+const (
+	TransactionTrade     = "trade"
+	TransactionWaiver    = "waiver"
+	TransactionFreeAgent = "free_agent"
+)
+
+// Transaction statuses. A failed waiver is a claim that lost to a higher
+// bid or priority.
+const (
+	StatusComplete = "complete"
+	StatusFailed   = "failed"
+)
+
+type TransactionSettings struct {
+	WaiverBid int `json:"waiver_bid"` // FAAB bid on a waiver claim
+}
+
+// Bid returns the FAAB bid on a waiver claim, or 0.
+func (t Transaction) Bid() int {
+	if t.Settings == nil {
+		return 0
+	}
+	return t.Settings.WaiverBid
+}
+
+type WaiverBudgetChange struct {
+	Sender   int `json:"sender"`   // roster_id giving FAAB
+	Receiver int `json:"receiver"` // roster_id receiving FAAB
+	Amount   int `json:"amount"`
+}
+
+// TradedPick is a future draft pick that changed hands. The roster IDs are
+// the pick's original roster, its current owner and its owner before the
+// latest trade.
 type TradedPick struct {
 	Season          string `json:"season"`
 	Round           int    `json:"round"`
+	RosterID        int    `json:"roster_id"`
 	OwnerID         int    `json:"owner_id"`
 	PreviousOwnerID int    `json:"previous_owner_id"`
 }
+
+// Draft statuses.
+const (
+	DraftPreDraft = "pre_draft"
+	DraftComplete = "complete"
+)
+
+type Draft struct {
+	DraftID        string         `json:"draft_id"`
+	Season         string         `json:"season"`
+	Type           string         `json:"type"`       // "snake", "linear" or "auction"
+	Status         string         `json:"status"`     // "pre_draft", "drafting", "paused" or "complete"
+	StartTime      int64          `json:"start_time"` // Unix milliseconds
+	Settings       DraftSettings  `json:"settings"`
+	SlotToRosterID map[string]int `json:"slot_to_roster_id"` // draft slot → roster_id
+}
+
+type DraftSettings struct {
+	Teams  int `json:"teams"`
+	Rounds int `json:"rounds"`
+}
+
+type DraftPick struct {
+	PlayerID  string            `json:"player_id"`
+	PickedBy  string            `json:"picked_by"` // user_id
+	RosterID  int               `json:"roster_id"`
+	Round     int               `json:"round"`
+	DraftSlot int               `json:"draft_slot"`
+	PickNo    int               `json:"pick_no"` // overall pick, from 1
+	Metadata  DraftPickMetadata `json:"metadata"`
+}
+
+// DraftPickMetadata describes the player as of the draft.
+type DraftPickMetadata struct {
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+	Position  string `json:"position"`
+	Team      string `json:"team"`
+	Amount    string `json:"amount"` // auction price, if any
+}
+
+// UnmarshalJSON accepts roster_id as a number or, as some drafts send it, a
+// quoted number.
+func (p *DraftPick) UnmarshalJSON(data []byte) error {
+	type plain DraftPick
+	aux := struct {
+		*plain
+		RosterID json.Number `json:"roster_id"`
+	}{plain: (*plain)(p)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	if aux.RosterID == "" {
+		return nil
+	}
+	id, err := aux.RosterID.Int64()
+	if err != nil {
+		return fmt.Errorf("invalid roster_id %q: %w", aux.RosterID, err)
+	}
+	p.RosterID = int(id)
+	return nil
+}
+
+// EmptySlot is the player ID Sleeper uses for an unfilled lineup slot.
+const EmptySlot = "0"
+
+// Matchup is one roster's lineup and score for a week. Rosters playing each
+// other share a MatchupID; it is 0 for rosters without a game, such as teams
+// knocked out of the playoffs.
+type Matchup struct {
+	RosterID       int       `json:"roster_id"`
+	MatchupID      int       `json:"matchup_id"`
+	Points         float64   `json:"points"`
+	Starters       []string  `json:"starters"` // player IDs by lineup slot; EmptySlot if unfilled
+	StartersPoints []float64 `json:"starters_points"`
+	Players        []string  `json:"players"`
+}
+
+// WeekMatchup is one game of a week: the rosters that played each other.
+type WeekMatchup struct {
+	Week      int
+	MatchupID int
+	Teams     []Matchup // usually two; fewer if Sleeper lost an opponent
+}
+
+// Opponent returns the roster rosterID played, or false if it had none.
+func (m WeekMatchup) Opponent(rosterID int) (Matchup, bool) {
+	for _, t := range m.Teams {
+		if t.RosterID != rosterID {
+			return t, true
+		}
+	}
+	return Matchup{}, false
+}
+
+// PairMatchups groups a week's matchup entries into games ordered by
+// MatchupID. Rosters without a game are left out.
+func PairMatchups(week int, entries []Matchup) []WeekMatchup {
+	byID := make(map[int]*WeekMatchup)
+	var games []*WeekMatchup
+	for _, e := range entries {
+		if e.MatchupID == 0 {
+			continue
+		}
+		g, ok := byID[e.MatchupID]
+		if !ok {
+			g = &WeekMatchup{Week: week, MatchupID: e.MatchupID}
+			byID[e.MatchupID] = g
+			games = append(games, g)
+		}
+		g.Teams = append(g.Teams, e)
+	}
+
+	sort.Slice(games, func(i, j int) bool { return games[i].MatchupID < games[j].MatchupID })
+	paired := make([]WeekMatchup, len(games))
+	for i, g := range games {
+		paired[i] = *g
+	}
+	return paired
+}
@@ -16,6 +16,17 @@ func newTestSleeper(server *httptest.Server) *SleeperService {
 	return &SleeperService{client: server.Client(), baseURL: server.URL}
 }
 
+// withNFLState answers /state/nfl with state and passes other requests to next.
+func withNFLState(state string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/state/nfl" {
+			w.Write([]byte(state))
+			return
+		}
+		next(w, r)
+	}
+}
+
 func TestFetchAllPlayers(t *testing.T) {
 	players := map[string]SleeperPlayer{
 		"1234": {PlayerID: "1234", FullName: "Patrick Mahomes", Position: "QB", Team: "KC"},
@@ -65,44 +76,180 @@ func TestFetchLeagueUsers(t *testing.T) {
 	assert.Equal(t, "JohnFantasy", got[0].DisplayName)
 }
 
-func TestFetchRecentTransactions_ReturnsTrades(t *testing.T) {
-	txs := []Transaction{
-		{TransactionID: "t1", Type: "trade", Status: "complete", RosterIDs: []int{1, 2}},
-		{TransactionID: "t2", Type: "waiver", Status: "complete"},   // should be filtered out
-		{TransactionID: "t3", Type: "trade", Status: "pending"},     // should be filtered out
-	}
-	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if strings.HasSuffix(r.URL.Path, "/1") {
-			json.NewEncoder(w).Encode(txs)
+func TestFetchRecentTransactions_ReturnsEveryType(t *testing.T) {
+	page := `[
+		{"transaction_id": "t1", "type": "trade", "status": "complete", "roster_ids": [1, 2],
+		 "waiver_budget": [{"sender": 1, "receiver": 2, "amount": 15}]},
+		{"transaction_id": "t2", "type": "waiver", "status": "complete", "adds": {"p1": 3},
+		 "settings": {"waiver_bid": 23}, "leg": 4},
+		{"transaction_id": "t3", "type": "waiver", "status": "failed", "adds": {"p1": 4},
+		 "settings": {"waiver_bid": 20}, "leg": 4},
+		{"transaction_id": "t4", "type": "free_agent", "status": "complete", "adds": {"p2": 1}, "settings": null}
+	]`
+	server := httptest.NewServer(withNFLState(`{"week": 1, "leg": 1}`, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(page))
+	}))
+	defer server.Close()
+
+	got, err := newTestSleeper(server).FetchRecentTransactions(context.Background(), "league1", 3)
+	require.NoError(t, err)
+	require.Len(t, got, 4, "every type and status should be returned")
+	assert.Equal(t, []WaiverBudgetChange{{Sender: 1, Receiver: 2, Amount: 15}}, got[0].WaiverBudget)
+	assert.Equal(t, 23, got[1].Bid())
+	assert.Equal(t, 4, got[1].Leg)
+	assert.Equal(t, StatusFailed, got[2].Status)
+	assert.Equal(t, 0, got[3].Bid())
+}
+
+func TestRosterSettings_Points(t *testing.T) {
+	var roster Roster
+	require.NoError(t, json.Unmarshal([]byte(`{"roster_id": 1, "settings": {
+		"wins": 7, "losses": 2, "ties": 1, "fpts": 1234, "fpts_decimal": 56,
+		"fpts_against": 1100, "fpts_against_decimal": 5, "waiver_position": 4
+	}}`), &roster))
+
+	assert.Equal(t, 7, roster.Settings.Wins)
+	assert.Equal(t, 1, roster.Settings.Ties)
+	assert.Equal(t, 4, roster.Settings.WaiverPosition)
+	assert.InDelta(t, 1234.56, roster.Settings.PointsFor(), 0.001)
+	assert.InDelta(t, 1100.05, roster.Settings.PointsAgainst(), 0.001)
+}
+
+func TestLeagueSettings_UsesFAAB(t *testing.T) {
+	assert.True(t, LeagueSettings{WaiverType: 2, WaiverBudget: 100}.UsesFAAB())
+	assert.False(t, LeagueSettings{WaiverType: 0, WaiverBudget: 100}.UsesFAAB())
+	assert.False(t, LeagueSettings{WaiverType: 2}.UsesFAAB())
+}
+
+func TestNFLState_TransactionWeeks(t *testing.T) {
+	state := NFLState{Week: 9, Leg: 10, SeasonType: "regular"}
+	assert.Equal(t, []int{10, 9, 8}, state.TransactionWeeks(3))
+	assert.Equal(t, []int{10, 9, 8, 7, 6, 5, 4, 3, 2, 1}, state.TransactionWeeks(0))
+	assert.Equal(t, []int{2, 1}, NFLState{Week: 2, Leg: 2}.TransactionWeeks(5))
+	// Before the season everything is filed under week 1.
+	assert.Equal(t, []int{1}, NFLState{SeasonType: "pre"}.TransactionWeeks(2))
+}
+
+// Transactions come from the most recent weeks by the NFL state, newest
+// first, and an empty week doesn't end the search.
+func TestFetchRecentTransactions_FetchesRecentWeeks(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(withNFLState(`{"week": 9, "leg": 10, "season": "2025", "season_type": "regular"}`, func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		if strings.HasSuffix(r.URL.Path, "/8") {
+			json.NewEncoder(w).Encode([]Transaction{{TransactionID: "t8", Type: TransactionTrade, Status: StatusComplete}})
 			return
 		}
-		json.NewEncoder(w).Encode([]Transaction{}) // round 2 empty → stop
+		json.NewEncoder(w).Encode([]Transaction{})
 	}))
 	defer server.Close()
 
 	got, err := newTestSleeper(server).FetchRecentTransactions(context.Background(), "league1", 3)
 	require.NoError(t, err)
-	require.Len(t, got, 1, "only completed trades should be returned")
-	assert.Equal(t, "t1", got[0].TransactionID)
+	assert.Equal(t, []string{
+		"/league/league1/transactions/10",
+		"/league/league1/transactions/9",
+		"/league/league1/transactions/8",
+	}, paths)
+	require.Len(t, got, 1)
+	assert.Equal(t, "t8", got[0].TransactionID)
 }
 
-func TestFetchRecentTransactions_StopsAtEmptyPage(t *testing.T) {
-	callCount := 0
+func TestFetchRecentTransactions_WholeSeason(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(withNFLState(`{"week": 4, "leg": 4}`, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		json.NewEncoder(w).Encode([]Transaction{})
+	}))
+	defer server.Close()
+
+	_, err := newTestSleeper(server).FetchRecentTransactions(context.Background(), "league1", 0)
+	require.NoError(t, err)
+	assert.Equal(t, 4, calls)
+}
+
+// Without the NFL state the first rounds are read instead.
+func TestFetchRecentTransactions_StateUnavailable(t *testing.T) {
+	var paths []string
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		callCount++
-		if strings.HasSuffix(r.URL.Path, "/1") {
-			json.NewEncoder(w).Encode([]Transaction{
-				{TransactionID: "t1", Type: "trade", Status: "complete"},
-			})
+		if r.URL.Path == "/state/nfl" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		paths = append(paths, r.URL.Path)
+		json.NewEncoder(w).Encode([]Transaction{{TransactionID: "t"}})
+	}))
+	defer server.Close()
+
+	got, err := newTestSleeper(server).FetchRecentTransactions(context.Background(), "league1", 2)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/league/league1/transactions/2", "/league/league1/transactions/1"}, paths)
+	assert.Len(t, got, 2)
+}
+
+// In the offseason a finished league's last weeks are read.
+func TestFetchRecentTransactions_OffseasonCompleteLeague(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(withNFLState(`{"week": 0, "leg": 0, "season_type": "off"}`, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/league/league1" {
+			w.Write([]byte(`{"league_id": "league1", "status": "complete", "previous_league_id": "0"}`))
+			return
+		}
+		paths = append(paths, r.URL.Path)
+		json.NewEncoder(w).Encode([]Transaction{})
+	}))
+	defer server.Close()
+
+	_, err := newTestSleeper(server).FetchRecentTransactions(context.Background(), "league1", 2)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/league/league1/transactions/18", "/league/league1/transactions/17"}, paths)
+}
+
+// A league that has rolled over reads its own offseason moves, then the
+// previous league's last weeks.
+func TestFetchRecentTransactions_OffseasonRolledOverLeague(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(withNFLState(`{"week": 0, "leg": 0, "season_type": "off"}`, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/league/new" {
+			w.Write([]byte(`{"league_id": "new", "status": "pre_draft", "previous_league_id": "old"}`))
 			return
 		}
-		json.NewEncoder(w).Encode([]Transaction{}) // empty on round 2
+		paths = append(paths, r.URL.Path)
+		json.NewEncoder(w).Encode([]Transaction{{TransactionID: r.URL.Path}})
 	}))
 	defer server.Close()
 
-	_, err := newTestSleeper(server).FetchRecentTransactions(context.Background(), "league1", 5)
+	got, err := newTestSleeper(server).FetchRecentTransactions(context.Background(), "new", 2)
 	require.NoError(t, err)
-	assert.Equal(t, 2, callCount, "should stop after the empty page, not fetch all 5 rounds")
+	assert.Equal(t, []string{
+		"/league/new/transactions/1",
+		"/league/old/transactions/18",
+		"/league/old/transactions/17",
+	}, paths)
+	assert.Len(t, got, 3)
+}
+
+// The NFL state is fetched once and reused until it goes stale.
+func TestNFLState_Cached(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Write([]byte(`{"week": 5, "leg": 5, "season": "2025", "season_type": "regular", "display_week": 5}`))
+	}))
+	defer server.Close()
+
+	s := newTestSleeper(server)
+	for i := 0; i < 3; i++ {
+		state, err := s.NFLState(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, NFLState{Week: 5, Leg: 5, Season: "2025", SeasonType: "regular", DisplayWeek: 5}, state)
+	}
+	assert.Equal(t, 1, calls)
+
+	s.stateAt = s.stateAt.Add(-stateTTL)
+	_, err := s.NFLState(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, calls)
 }
 
 func TestFetchRecentTransactions_IncludesDraftPicks(t *testing.T) {
@@ -114,7 +261,7 @@ func TestFetchRecentTransactions_IncludesDraftPicks(t *testing.T) {
 			{Season: "2026", Round: 1, OwnerID: 2, PreviousOwnerID: 1},
 		},
 	}}
-	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	server := httptest.NewServer(withNFLState(`{"week": 2, "leg": 2}`, func(w http.ResponseWriter, r *http.Request) {
 		if strings.HasSuffix(r.URL.Path, "/1") {
 			json.NewEncoder(w).Encode(txs)
 			return
@@ -130,6 +277,95 @@ func TestFetchRecentTransactions_IncludesDraftPicks(t *testing.T) {
 	assert.Equal(t, "2026", got[0].DraftPicks[0].Season)
 }
 
+func TestNFLState_ScoredWeeks(t *testing.T) {
+	assert.Equal(t, []int{1, 2, 3}, NFLState{Week: 3, SeasonType: "regular"}.ScoredWeeks())
+	assert.Len(t, NFLState{Week: 2, SeasonType: "post"}.ScoredWeeks(), regularSeasonWeeks)
+	assert.Empty(t, NFLState{Week: 0, SeasonType: "pre"}.ScoredWeeks())
+	assert.Empty(t, NFLState{SeasonType: "off"}.ScoredWeeks())
+}
+
+// Entries sharing a matchup_id are paired into one game; rosters without a
+// game are left out.
+func TestFetchMatchups_PairsOpponents(t *testing.T) {
+	page := `[
+		{"roster_id": 1, "matchup_id": 2, "points": 101.5, "starters": ["p1", "0"], "starters_points": [20.5, 0]},
+		{"roster_id": 2, "matchup_id": 1, "points": 88.2, "starters": ["p2"], "starters_points": [12]},
+		{"roster_id": 3, "matchup_id": 2, "points": 120.1, "starters": ["p3"], "starters_points": [30.1]},
+		{"roster_id": 4, "matchup_id": 1, "points": 90, "starters": ["p4"], "starters_points": [9]},
+		{"roster_id": 5, "matchup_id": null, "points": 0}
+	]`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/league/league1/matchups/7", r.URL.Path)
+		w.Write([]byte(page))
+	}))
+	defer server.Close()
+
+	got, err := newTestSleeper(server).FetchMatchups(context.Background(), "league1", 7)
+	require.NoError(t, err)
+	require.Len(t, got, 2)
+
+	assert.Equal(t, 7, got[0].Week)
+	assert.Equal(t, 1, got[0].MatchupID)
+	opp, ok := got[0].Opponent(2)
+	require.True(t, ok)
+	assert.Equal(t, 4, opp.RosterID)
+
+	assert.Equal(t, 2, got[1].MatchupID)
+	require.Len(t, got[1].Teams, 2)
+	assert.Equal(t, []string{"p1", EmptySlot}, got[1].Teams[0].Starters)
+	assert.Equal(t, []float64{20.5, 0}, got[1].Teams[0].StartersPoints)
+	assert.Equal(t, 120.1, got[1].Teams[1].Points)
+}
+
+// Draft picks decode roster_id whether Sleeper sends it as a number or a string.
+func TestFetchDraftPicks_RosterIDFormats(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/draft/d1/picks", r.URL.Path)
+		w.Write([]byte(`[
+			{"player_id": "p1", "picked_by": "u1", "roster_id": 3, "round": 1, "draft_slot": 3, "pick_no": 3,
+			 "metadata": {"first_name": "Ashton", "last_name": "Jeanty", "position": "RB", "team": "LV"}},
+			{"player_id": "p2", "picked_by": "u2", "roster_id": "4", "round": 1, "draft_slot": 4, "pick_no": 4},
+			{"player_id": "p3", "roster_id": null, "round": 2, "pick_no": 5}
+		]`))
+	}))
+	defer server.Close()
+
+	got, err := newTestSleeper(server).FetchDraftPicks(context.Background(), "d1")
+	require.NoError(t, err)
+	require.Len(t, got, 3)
+	assert.Equal(t, 3, got[0].RosterID)
+	assert.Equal(t, "Jeanty", got[0].Metadata.LastName)
+	assert.Equal(t, 4, got[1].RosterID)
+	assert.Equal(t, "u2", got[1].PickedBy)
+	assert.Equal(t, 0, got[2].RosterID)
+}
+
+func TestFetchDraftsAndTradedPicks(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/league/l1/drafts":
+			w.Write([]byte(`[{"draft_id": "d1", "season": "2025", "type": "snake", "status": "complete",
+				"settings": {"teams": 12, "rounds": 4}, "slot_to_roster_id": {"1": 7}}]`))
+		case "/league/l1/traded_picks":
+			w.Write([]byte(`[{"season": "2026", "round": 1, "roster_id": 2, "owner_id": 5, "previous_owner_id": 2}]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	s := newTestSleeper(server)
+	drafts, err := s.FetchDrafts(context.Background(), "l1")
+	require.NoError(t, err)
+	require.Len(t, drafts, 1)
+	assert.Equal(t, DraftSettings{Teams: 12, Rounds: 4}, drafts[0].Settings)
+	assert.Equal(t, 7, drafts[0].SlotToRosterID["1"])
+
+	traded, err := s.FetchTradedPicks(context.Background(), "l1")
+	require.NoError(t, err)
+	assert.Equal(t, []TradedPick{{Season: "2026", Round: 1, RosterID: 2, OwnerID: 5, PreviousOwnerID: 2}}, traded)
+}
+
 func TestFetchLeague_ErrorOnNonOK(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusNotFound)
@@ -0,0 +1,478 @@
+package sleeper_tools
+
+import (
+	"context"
+	"crowfather/internal/open_ai"
+	"crowfather/internal/sleeper"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// playersTTL bounds how long the ~5MB Sleeper player map is reused between
+// tool calls. Player names and teams change rarely enough for this to be safe.
+const playersTTL = 6 * time.Hour
+
+// SleeperTools exposes live Sleeper league data to assistants as function tools.
+type SleeperTools struct {
+	sleeper     *sleeper.SleeperService
+	leagues     map[string][]string // assistant ID → leagues its runs may read
+	transRounds int
+	players     *playerCache // shared with the tools WithLeagues derives
+}
+
+// playerCache holds the Sleeper player map between tool calls.
+type playerCache struct {
+	mu        sync.Mutex
+	players   map[string]sleeper.SleeperPlayer
+	playersAt time.Time
+}
+
+// NewSleeperTools scopes each assistant in leagues to the leagues it answers
+// for: a tool call only reads the leagues of the assistant whose run made it,
+// so members of one league can't ask about another's.
+func NewSleeperTools(sleeperSvc *sleeper.SleeperService, leagues map[string][]string, transRounds int) *SleeperTools {
+	return &SleeperTools{
+		sleeper:     sleeperSvc,
+		leagues:     leagues,
+		transRounds: transRounds,
+		players:     &playerCache{},
+	}
+}
+
+// WithLeagues returns tools with reloaded league scopes and transaction
+// weeks, sharing st's player cache. Register them with a new registry; tool
+// calls of runs that started with st keep st's settings.
+func (st *SleeperTools) WithLeagues(leagues map[string][]string, transRounds int) *SleeperTools {
+	return &SleeperTools{
+		sleeper:     st.sleeper,
+		leagues:     leagues,
+		transRounds: transRounds,
+		players:     st.players,
+	}
+}
+
+// settings returns the leagues the calling assistant may read and the
+// transaction weeks.
+func (st *SleeperTools) settings(ctx context.Context) ([]string, int) {
+	return st.leagues[open_ai.AssistantFromContext(ctx)], st.transRounds
+}
+
+// Register adds every Sleeper tool to the registry.
+func (st *SleeperTools) Register(reg *open_ai.ToolRegistry) error {
+	leagueIDProp := map[string]interface{}{
+		"type":        "string",
+		"description": "Optional Sleeper league ID. Omit to search every league this chat follows.",
+	}
+
+	tools := []open_ai.Tool{
+		{
+			Name:        "get_roster_for_owner",
+			Description: "Get the current fantasy roster for a league member by their Sleeper display name.",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"owner":     map[string]interface{}{"type": "string", "description": "Sleeper display name of the team owner."},
+					"league_id": leagueIDProp,
+				},
+				"required": []string{"owner"},
+			},
+			Handler: st.getRosterForOwner,
+		},
+		{
+			Name:        "list_recent_trades",
+			Description: "List recently completed trades in the fantasy leagues.",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"league_id": leagueIDProp,
+				},
+			},
+			Handler: st.listRecentTrades,
+		},
+		{
+			Name:        "list_recent_moves",
+			Description: "List recent waiver claims, with FAAB bids and the claims that lost, and free-agent adds and drops in the fantasy leagues.",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"league_id": leagueIDProp,
+				},
+			},
+			Handler: st.listRecentMoves,
+		},
+		{
+			Name:        "find_player_owner",
+			Description: "Find which fantasy team currently rosters an NFL player. Partial names such as \"Bijan\" are accepted.",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"player":    map[string]interface{}{"type": "string", "description": "Full or partial player name."},
+					"league_id": leagueIDProp,
+				},
+				"required": []string{"player"},
+			},
+			Handler: st.findPlayerOwner,
+		},
+	}
+
+	for _, tool := range tools {
+		if err := reg.Register(tool); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type rosterPlayer struct {
+	Name     string `json:"name"`
+	Position string `json:"position"`
+	NFLTeam  string `json:"nfl_team"`
+}
+
+type rosterResult struct {
+	League  string         `json:"league"`
+	Owner   string         `json:"owner"`
+	Players []rosterPlayer `json:"players"`
+}
+
+type tradeSide struct {
+	Owner    string   `json:"owner"`
+	Receives []string `json:"receives"`
+}
+
+type tradeResult struct {
+	League string      `json:"league"`
+	Date   string      `json:"date"`
+	Sides  []tradeSide `json:"sides"`
+}
+
+type moveResult struct {
+	League string   `json:"league"`
+	Date   string   `json:"date"`
+	Type   string   `json:"type"`   // "waiver" or "free_agent"
+	Status string   `json:"status"` // "complete", or "failed" for a losing waiver claim
+	Owner  string   `json:"owner"`
+	Adds   []string `json:"adds"`
+	Drops  []string `json:"drops"`
+	Bid    int      `json:"faab_bid,omitempty"`
+}
+
+type playerOwnerResult struct {
+	League   string `json:"league"`
+	Owner    string `json:"owner"`
+	Player   string `json:"player"`
+	Position string `json:"position"`
+	NFLTeam  string `json:"nfl_team"`
+}
+
+func (st *SleeperTools) getRosterForOwner(ctx context.Context, arguments json.RawMessage) (string, error) {
+	var args struct {
+		Owner    string `json:"owner"`
+		LeagueID string `json:"league_id"`
+	}
+	if err := json.Unmarshal(arguments, &args); err != nil {
+		return "", fmt.Errorf("invalid arguments: %w", err)
+	}
+	if strings.TrimSpace(args.Owner) == "" {
+		return "", fmt.Errorf("owner is required")
+	}
+
+	players, err := st.allPlayers(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	leagueIDs, _ := st.settings(ctx)
+	leagues, err := st.loadLeagues(ctx, leagueIDs, args.LeagueID)
+	if err != nil {
+		return "", err
+	}
+
+	results := []rosterResult{}
+	for _, l := range leagues {
+		for _, r := range l.rosters {
+			owner := l.owners[r.RosterID]
+			if !strings.EqualFold(owner, strings.TrimSpace(args.Owner)) {
+				continue
+			}
+			res := rosterResult{League: l.name, Owner: owner, Players: []rosterPlayer{}}
+			for _, pid := range r.Players {
+				if sp, ok := players[pid]; ok {
+					res.Players = append(res.Players, rosterPlayer{Name: sp.FullName, Position: sp.Position, NFLTeam: sp.Team})
+				}
+			}
+			results = append(results, res)
+		}
+	}
+
+	return marshalResult(results)
+}
+
+func (st *SleeperTools) listRecentTrades(ctx context.Context, arguments json.RawMessage) (string, error) {
+	var args struct {
+		LeagueID string `json:"league_id"`
+	}
+	if err := json.Unmarshal(arguments, &args); err != nil {
+		return "", fmt.Errorf("invalid arguments: %w", err)
+	}
+
+	players, err := st.allPlayers(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	leagueIDs, transRounds := st.settings(ctx)
+	leagues, err := st.loadLeagues(ctx, leagueIDs, args.LeagueID)
+	if err != nil {
+		return "", err
+	}
+
+	results := []tradeResult{}
+	for _, l := range leagues {
+		txs, err := st.sleeper.FetchRecentTransactions(ctx, l.id, transRounds)
+		if err != nil {
+			return "", err
+		}
+		for _, t := range txs {
+			if t.Type != sleeper.TransactionTrade || t.Status != sleeper.StatusComplete {
+				continue
+			}
+			receives := make(map[int][]string)
+			for pid, rosterID := range t.Adds {
+				name := pid
+				if sp, ok := players[pid]; ok {
+					name = sp.FullName
+				}
+				receives[rosterID] = append(receives[rosterID], name)
+			}
+			for _, pick := range t.DraftPicks {
+				receives[pick.OwnerID] = append(receives[pick.OwnerID], fmt.Sprintf("%s round %d pick", pick.Season, pick.Round))
+			}
+			for _, wb := range t.WaiverBudget {
+				receives[wb.Receiver] = append(receives[wb.Receiver], fmt.Sprintf("$%d FAAB", wb.Amount))
+			}
+
+			res := tradeResult{
+				League: l.name,
+				Date:   time.Unix(t.Created/1000, 0).UTC().Format("2006-01-02"),
+			}
+			for rosterID, items := range receives {
+				sort.Strings(items)
+				res.Sides = append(res.Sides, tradeSide{Owner: l.owners[rosterID], Receives: items})
+			}
+			sort.Slice(res.Sides, func(i, j int) bool { return res.Sides[i].Owner < res.Sides[j].Owner })
+			results = append(results, res)
+		}
+	}
+
+	return marshalResult(results)
+}
+
+func (st *SleeperTools) listRecentMoves(ctx context.Context, arguments json.RawMessage) (string, error) {
+	var args struct {
+		LeagueID string `json:"league_id"`
+	}
+	if err := json.Unmarshal(arguments, &args); err != nil {
+		return "", fmt.Errorf("invalid arguments: %w", err)
+	}
+
+	players, err := st.allPlayers(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	leagueIDs, transRounds := st.settings(ctx)
+	leagues, err := st.loadLeagues(ctx, leagueIDs, args.LeagueID)
+	if err != nil {
+		return "", err
+	}
+
+	names := func(m map[string]int) []string {
+		out := []string{}
+		for pid := range m {
+			if sp, ok := players[pid]; ok {
+				out = append(out, sp.FullName)
+			} else {
+				out = append(out, pid)
+			}
+		}
+		sort.Strings(out)
+		return out
+	}
+
+	results := []moveResult{}
+	for _, l := range leagues {
+		txs, err := st.sleeper.FetchRecentTransactions(ctx, l.id, transRounds)
+		if err != nil {
+			return "", err
+		}
+		for _, t := range txs {
+			switch {
+			case t.Type == sleeper.TransactionFreeAgent && t.Status == sleeper.StatusComplete:
+			case t.Type == sleeper.TransactionWaiver && (t.Status == sleeper.StatusComplete || t.Status == sleeper.StatusFailed):
+			default:
+				continue
+			}
+			owner := ""
+			if len(t.RosterIDs) > 0 {
+				owner = l.owners[t.RosterIDs[0]]
+			}
+			results = append(results, moveResult{
+				League: l.name,
+				Date:   time.Unix(t.Created/1000, 0).UTC().Format("2006-01-02"),
+				Type:   t.Type,
+				Status: t.Status,
+				Owner:  owner,
+				Adds:   names(t.Adds),
+				Drops:  names(t.Drops),
+				Bid:    t.Bid(),
+			})
+		}
+	}
+
+	return marshalResult(results)
+}
+
+func (st *SleeperTools) findPlayerOwner(ctx context.Context, arguments json.RawMessage) (string, error) {
+	var args struct {
+		Player   string `json:"player"`
+		LeagueID string `json:"league_id"`
+	}
+	if err := json.Unmarshal(arguments, &args); err != nil {
+		return "", fmt.Errorf("invalid arguments: %w", err)
+	}
+	query := strings.ToLower(strings.TrimSpace(args.Player))
+	if query == "" {
+		return "", fmt.Errorf("player is required")
+	}
+
+	players, err := st.allPlayers(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	leagueIDs, _ := st.settings(ctx)
+	leagues, err := st.loadLeagues(ctx, leagueIDs, args.LeagueID)
+	if err != nil {
+		return "", err
+	}
+
+	results := []playerOwnerResult{}
+	for _, l := range leagues {
+		for _, r := range l.rosters {
+			for _, pid := range r.Players {
+				sp, ok := players[pid]
+				if !ok || !strings.Contains(strings.ToLower(sp.FullName), query) {
+					continue
+				}
+				results = append(results, playerOwnerResult{
+					League:   l.name,
+					Owner:    l.owners[r.RosterID],
+					Player:   sp.FullName,
+					Position: sp.Position,
+					NFLTeam:  sp.Team,
+				})
+			}
+		}
+	}
+
+	return marshalResult(results)
+}
+
+// leagueSnapshot is the live roster and owner state for one league.
+type leagueSnapshot struct {
+	id      string
+	name    string
+	rosters []sleeper.Roster
+	owners  map[int]string // roster_id → owner display name
+}
+
+// loadLeagues fetches live rosters and users for the requested league, or for
+// every league in leagueIDs, the calling assistant's, when leagueID is empty.
+func (st *SleeperTools) loadLeagues(ctx context.Context, leagueIDs []string, leagueID string) ([]leagueSnapshot, error) {
+	if len(leagueIDs) == 0 {
+		return nil, fmt.Errorf("no Sleeper leagues are followed in this chat")
+	}
+	ids := leagueIDs
+	if leagueID != "" {
+		if !slices.Contains(leagueIDs, leagueID) {
+			return nil, fmt.Errorf("league %s is not followed in this chat", leagueID)
+		}
+		ids = []string{leagueID}
+	}
+
+	var snapshots []leagueSnapshot
+	for _, id := range ids {
+		league, err := st.sleeper.FetchLeague(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		rosters, err := st.sleeper.FetchLeagueRosters(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		users, err := st.sleeper.FetchLeagueUsers(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, leagueSnapshot{
+			id:      id,
+			name:    league.Name,
+			rosters: rosters,
+			owners:  ownerNames(rosters, users),
+		})
+	}
+	return snapshots, nil
+}
+
+// allPlayers returns the cached Sleeper player map, refreshing it once it is
+// older than playersTTL.
+func (st *SleeperTools) allPlayers(ctx context.Context) (map[string]sleeper.SleeperPlayer, error) {
+	c := st.players
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.players != nil && time.Since(c.playersAt) < playersTTL {
+		return c.players, nil
+	}
+
+	players, err := st.sleeper.FetchAllPlayers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c.players = players
+	c.playersAt = time.Now()
+	return players, nil
+}
+
+// ownerNames maps roster_id to the owning user's display name, falling back to
+// "Team N" for rosters without a known owner.
+func ownerNames(rosters []sleeper.Roster, users []sleeper.User) map[int]string {
+	userByID := make(map[string]sleeper.User, len(users))
+	for _, u := range users {
+		userByID[u.UserID] = u
+	}
+
+	owners := make(map[int]string, len(rosters))
+	for _, r := range rosters {
+		if u, ok := userByID[r.OwnerID]; ok {
+			owners[r.RosterID] = u.DisplayName
+		} else {
+			owners[r.RosterID] = fmt.Sprintf("Team %d", r.RosterID)
+		}
+	}
+	return owners
+}
+
+func marshalResult(v interface{}) (string, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode tool result: %w", err)
+	}
+	return string(b), nil
+}
@@ -0,0 +1,208 @@
+package sleeper_tools
+
+import (
+	"context"
+	"crowfather/internal/open_ai"
+	"crowfather/internal/sleeper"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestTools(t *testing.T) (*SleeperTools, *int) {
+	t.Helper()
+	playerFetches := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/players/nfl":
+			playerFetches++
+			json.NewEncoder(w).Encode(map[string]sleeper.SleeperPlayer{
+				"p1": {PlayerID: "p1", FullName: "Bijan Robinson", Position: "RB", Team: "ATL"},
+				"p2": {PlayerID: "p2", FullName: "Patrick Mahomes", Position: "QB", Team: "KC"},
+				"p3": {PlayerID: "p3", FullName: "Jaylen Warren", Position: "RB", Team: "PIT"},
+			})
+		case r.URL.Path == "/state/nfl":
+			w.Write([]byte(`{"week": 1, "leg": 1, "season": "2025", "season_type": "regular"}`))
+		case r.URL.Path == "/league/l1":
+			json.NewEncoder(w).Encode(sleeper.League{LeagueID: "l1", Name: "Dynasty"})
+		case r.URL.Path == "/league/l1/rosters":
+			json.NewEncoder(w).Encode([]sleeper.Roster{
+				{RosterID: 1, OwnerID: "u1", Players: []string{"p1"}},
+				{RosterID: 2, OwnerID: "u2", Players: []string{"p2"}},
+			})
+		case r.URL.Path == "/league/l1/users":
+			json.NewEncoder(w).Encode([]sleeper.User{
+				{UserID: "u1", DisplayName: "Alice"},
+				{UserID: "u2", DisplayName: "Bob"},
+			})
+		case r.URL.Path == "/league/l2":
+			json.NewEncoder(w).Encode(sleeper.League{LeagueID: "l2", Name: "Redraft"})
+		case r.URL.Path == "/league/l2/rosters":
+			json.NewEncoder(w).Encode([]sleeper.Roster{{RosterID: 1, OwnerID: "u3", Players: []string{"p1"}}})
+		case r.URL.Path == "/league/l2/users":
+			json.NewEncoder(w).Encode([]sleeper.User{{UserID: "u3", DisplayName: "Carol"}})
+		case r.URL.Path == "/league/l1/transactions/1":
+			json.NewEncoder(w).Encode([]sleeper.Transaction{
+				{
+					TransactionID: "t1",
+					Type:          "trade",
+					Status:        "complete",
+					Adds:          map[string]int{"p1": 1, "p2": 2},
+					DraftPicks:    []sleeper.TradedPick{{Season: "2026", Round: 1, OwnerID: 2}},
+				},
+				{
+					TransactionID: "t2",
+					Type:          "waiver",
+					Status:        "complete",
+					RosterIDs:     []int{1},
+					Adds:          map[string]int{"p3": 1},
+					Drops:         map[string]int{"p1": 1},
+					Settings:      &sleeper.TransactionSettings{WaiverBid: 23},
+				},
+				{
+					TransactionID: "t3",
+					Type:          "waiver",
+					Status:        "failed",
+					RosterIDs:     []int{2},
+					Adds:          map[string]int{"p3": 2},
+					Settings:      &sleeper.TransactionSettings{WaiverBid: 20},
+				},
+			})
+		default:
+			json.NewEncoder(w).Encode([]sleeper.Transaction{})
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	leagues := map[string][]string{"asst_l1": {"l1"}, "asst_l2": {"l2"}}
+	return NewSleeperTools(sleeper.NewSleeperServiceWithBaseURL(server.URL), leagues, 2), &playerFetches
+}
+
+// l1Ctx is the context of a tool call made by the run of league l1's assistant.
+var l1Ctx = open_ai.WithAssistant(context.Background(), "asst_l1")
+
+func TestRegister_AddsAllTools(t *testing.T) {
+	st, _ := newTestTools(t)
+	reg := open_ai.NewToolRegistry()
+	require.NoError(t, st.Register(reg))
+	assert.Equal(t, 4, reg.Len())
+}
+
+func TestFindPlayerOwner_PartialName(t *testing.T) {
+	st, _ := newTestTools(t)
+
+	out, err := st.findPlayerOwner(l1Ctx, json.RawMessage(`{"player":"bijan"}`))
+	require.NoError(t, err)
+
+	var got []playerOwnerResult
+	require.NoError(t, json.Unmarshal([]byte(out), &got))
+	require.Len(t, got, 1)
+	assert.Equal(t, "Alice", got[0].Owner)
+	assert.Equal(t, "Bijan Robinson", got[0].Player)
+	assert.Equal(t, "Dynasty", got[0].League)
+}
+
+func TestGetRosterForOwner_CaseInsensitive(t *testing.T) {
+	st, _ := newTestTools(t)
+
+	out, err := st.getRosterForOwner(l1Ctx, json.RawMessage(`{"owner":"bob"}`))
+	require.NoError(t, err)
+
+	var got []rosterResult
+	require.NoError(t, json.Unmarshal([]byte(out), &got))
+	require.Len(t, got, 1)
+	require.Len(t, got[0].Players, 1)
+	assert.Equal(t, "Patrick Mahomes", got[0].Players[0].Name)
+}
+
+func TestListRecentTrades_ResolvesNames(t *testing.T) {
+	st, _ := newTestTools(t)
+
+	out, err := st.listRecentTrades(l1Ctx, json.RawMessage(`{}`))
+	require.NoError(t, err)
+
+	var got []tradeResult
+	require.NoError(t, json.Unmarshal([]byte(out), &got))
+	require.Len(t, got, 1)
+	require.Len(t, got[0].Sides, 2)
+	assert.Equal(t, "Alice", got[0].Sides[0].Owner)
+	assert.Equal(t, []string{"Bijan Robinson"}, got[0].Sides[0].Receives)
+	assert.Equal(t, "Bob", got[0].Sides[1].Owner)
+	assert.Contains(t, got[0].Sides[1].Receives, "2026 round 1 pick")
+}
+
+func TestListRecentMoves_IncludesLosingClaims(t *testing.T) {
+	st, _ := newTestTools(t)
+
+	out, err := st.listRecentMoves(l1Ctx, json.RawMessage(`{}`))
+	require.NoError(t, err)
+
+	var got []moveResult
+	require.NoError(t, json.Unmarshal([]byte(out), &got))
+	require.Len(t, got, 2)
+	assert.Equal(t, moveResult{
+		League: "Dynasty", Date: "1970-01-01", Type: "waiver", Status: "complete", Owner: "Alice",
+		Adds: []string{"Jaylen Warren"}, Drops: []string{"Bijan Robinson"}, Bid: 23,
+	}, got[0])
+	assert.Equal(t, "failed", got[1].Status)
+	assert.Equal(t, "Bob", got[1].Owner)
+	assert.Equal(t, 20, got[1].Bid)
+}
+
+func TestLoadLeagues_RejectsUnknownLeague(t *testing.T) {
+	st, _ := newTestTools(t)
+
+	_, err := st.findPlayerOwner(l1Ctx, json.RawMessage(`{"player":"bijan","league_id":"other"}`))
+	assert.Error(t, err)
+}
+
+// An assistant only reads the leagues it answers for, whether it names
+// another league or searches them all.
+func TestLoadLeagues_ScopedToAssistant(t *testing.T) {
+	st, _ := newTestTools(t)
+
+	_, err := st.getRosterForOwner(l1Ctx, json.RawMessage(`{"owner":"carol","league_id":"l2"}`))
+	assert.ErrorContains(t, err, "league l2 is not followed in this chat")
+
+	out, err := st.findPlayerOwner(l1Ctx, json.RawMessage(`{"player":"bijan"}`))
+	require.NoError(t, err)
+	var got []playerOwnerResult
+	require.NoError(t, json.Unmarshal([]byte(out), &got))
+	require.Len(t, got, 1)
+	assert.Equal(t, "Dynasty", got[0].League)
+
+	l2Ctx := open_ai.WithAssistant(context.Background(), "asst_l2")
+	out, err = st.getRosterForOwner(l2Ctx, json.RawMessage(`{"owner":"carol","league_id":"l2"}`))
+	require.NoError(t, err)
+	assert.Contains(t, out, "Redraft")
+
+	// Runs of assistants without leagues, and calls outside a run, read nothing.
+	_, err = st.findPlayerOwner(context.Background(), json.RawMessage(`{"player":"bijan"}`))
+	assert.ErrorContains(t, err, "no Sleeper leagues")
+}
+
+func TestWithLeagues_KeepsOriginalScopes(t *testing.T) {
+	st, fetches := newTestTools(t)
+
+	next := st.WithLeagues(map[string][]string{"asst_l1": {"other"}}, 1)
+	_, err := next.findPlayerOwner(l1Ctx, json.RawMessage(`{"player":"bijan","league_id":"l1"}`))
+	assert.ErrorContains(t, err, "league l1 is not followed in this chat")
+
+	_, err = st.findPlayerOwner(l1Ctx, json.RawMessage(`{"player":"bijan","league_id":"l1"}`))
+	assert.NoError(t, err, "the original tools keep their scopes")
+	assert.Equal(t, 1, *fetches, "the player cache is shared")
+}
+
+func TestAllPlayers_Cached(t *testing.T) {
+	st, fetches := newTestTools(t)
+
+	_, err := st.allPlayers(context.Background())
+	require.NoError(t, err)
+	_, err = st.allPlayers(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, *fetches)
+}